@@ -1,28 +1,19 @@
 package cmd
 
 import (
-	"bufio"
+	"errors"
 	"fmt"
-	"log"
 	"os"
-	"os/exec"
 	"sort"
 	"strings"
+	"time"
+
+	"git-gone/internal/events"
+	"git-gone/internal/git"
 
-	"github.com/koki-develop/go-fzf"
 	"github.com/spf13/cobra"
 )
 
-type Branch struct {
-	Name       string
-	IsMerged   bool
-	IsDefault  bool
-	IsLocal    bool
-	IsUnmerged bool
-}
-
-const unmergedPrefix = "(!) "
-
 var branchesCmd = &cobra.Command{
 	Use:   "branches",
 	Short: "Clean up merged branches interactively",
@@ -49,120 +40,165 @@ Interactive Controls:
 
   # Skip confirmation prompt
   git-gone branches --force
-  git gone -f`,
+  git gone -f
+
+  # Show the git operations without deleting anything
+  git gone --dry-run`,
 	Run: func(cmd *cobra.Command, args []string) {
 		runCleanup()
 	},
 }
 
 func runCleanup() {
-	// Validate incompatible flags
-	if selectAll && forceDelete {
-		fmt.Println("❌ Options -a (--all) and -f (--force) are incompatible")
-		os.Exit(1)
+	// Validate incompatible flags; the event stream cannot prompt, so it
+	// combines -a with -f instead
+	if selectAll && forceDelete && !events.Enabled() {
+		fatalf("Options -a (--all) and -f (--force) are incompatible")
 	}
 
-	// Check if we're in a git repository
-	if err := checkGitRepository(); err != nil {
-		log.Fatal("❌ Not in a git repository")
-	}
+	minAge := parseBranchAgeFlags()
+
+	summary := &events.Summary{Command: "branches", DryRun: dryRun}
+	defer finish(summary)
 
-	fmt.Println("🔄 Updating remote references...")
-	if err := updateRemoteRefs(); err != nil {
-		fmt.Printf("⚠️  Warning: Failed to update remote refs: %v\n", err)
+	requireRepository()
+
+	updateRemoteRefs(os.Stdout, repo.UpdateRemoteRefsSync)
+
+	// Resolve the remote to compare against
+	remote := resolveRemote()
+	if remote != "" && remote != git.DefaultRemote {
+		fmt.Printf("📡 Remote: %s\n", remote)
 	}
 
 	// Get default branch
-	defaultBranch, err := getDefaultBranch()
+	defaultBranch, err := repo.GetDefaultBranch(remote)
 	if err != nil {
-		log.Fatalf("❌ Failed to get default branch: %v", err)
+		fatalf("Failed to get default branch: %v", err)
 	}
 	fmt.Printf("📍 Default branch: %s\n", defaultBranch)
 
 	// Get current branch
-	currentBranch, err := getCurrentBranch()
+	currentBranch, err := repo.GetCurrentBranch()
 	if err != nil {
-		log.Fatalf("❌ Failed to get current branch: %v", err)
+		fatalf("Failed to get current branch: %v", err)
 	}
 	fmt.Printf("🌿 Current branch: %s\n", currentBranch)
 
-	// Get branches to delete (both merged and gone remotes)
-	goneBranches, err := getGoneBranches()
-	if err != nil {
-		fmt.Printf("⚠️  Warning: Failed to get gone branches: %v\n", err)
-		goneBranches = []string{}
+	// Resolve integration targets (default branch first)
+	targets := resolveTargets(defaultBranch)
+	if len(targets) > 1 {
+		fmt.Printf("🎯 Integration targets: %s\n", strings.Join(targets, ", "))
 	}
 
-	// Get merged branches
-	mergedBranches, err := getMergedBranches(defaultBranch)
+	// Branches checked out in other worktrees cannot be deleted
+	worktreeBranches := repo.GetWorktreeBranches()
+
+	// Read every branch at once and classify it; failed checks are skipped
+	snapshot, err := repo.LoadBranchSnapshot(git.BranchContext{
+		DefaultBranch: defaultBranch,
+		CurrentBranch: currentBranch,
+		Targets:       targets,
+		Protected:     cfg.Protected,
+		Worktrees:     worktreeBranches,
+	})
+	if snapshot == nil {
+		fatalf("Failed to get local branches: %v", err)
+	}
 	if err != nil {
-		fmt.Printf("⚠️  Warning: Failed to get merged branches: %v\n", err)
-		mergedBranches = []string{}
+		fmt.Printf("⚠️  Warning: %v\n", err)
 	}
 
-	// Combine and deduplicate branches (track merged/gone branches)
-	safeToDeleteMap := make(map[string]bool)
-	for _, branch := range goneBranches {
-		branch = strings.TrimSpace(branch)
-		if branch != defaultBranch && branch != currentBranch && branch != "" {
-			safeToDeleteMap[branch] = true
-		}
+	// Last commit dates drive the --older-than filter and --sort age
+	now := time.Now()
+	if minAge > 0 {
+		fmt.Printf("⏳ Only branches with no commits in the last %s\n", olderThan)
 	}
-	for _, branch := range mergedBranches {
-		branch = strings.TrimSpace(branch)
-		if branch != defaultBranch && branch != currentBranch && branch != "" {
-			safeToDeleteMap[branch] = true
+	commitDates := make(map[string]time.Time)
+	protectedCount := 0
+	for _, branch := range snapshot.Branches() {
+		ref, _ := snapshot.Ref(branch)
+		commitDates[branch] = ref.CommitDate
+		if _, protected := git.MatchProtectedPattern(branch, cfg.Protected); protected {
+			protectedCount++
 		}
 	}
-
-	// Track unmerged branches if -u flag is set
-	unmergedBranchesMap := make(map[string]bool)
-	if includeUnmerged {
-		allBranches, err := getAllLocalBranches()
-		if err != nil {
-			fmt.Printf("⚠️  Warning: Failed to get all branches: %v\n", err)
-		} else {
-			for _, branch := range allBranches {
-				branch = strings.TrimSpace(branch)
-				if branch != defaultBranch && branch != currentBranch && branch != "" && !safeToDeleteMap[branch] {
-					unmergedBranchesMap[branch] = true
-				}
-			}
-		}
+	if protectedCount > 0 {
+		fmt.Printf("🔒 %d branches protected by configured patterns\n", protectedCount)
 	}
-
-	// Convert maps to display list
-	var branchesToDelete []string
-	for branch := range safeToDeleteMap {
-		branchesToDelete = append(branchesToDelete, branch)
+	if len(worktreeBranches) > 0 {
+		fmt.Printf("🔒 %d branches checked out in other worktrees (see git-gone worktrees)\n", len(worktreeBranches))
 	}
-	for branch := range unmergedBranchesMap {
-		branchesToDelete = append(branchesToDelete, unmergedPrefix+branch)
+
+	// Collect deletable branches; unmerged ones only if the -u flag is set
+	candidates := make(map[string]git.DeletionCandidate)
+	displayNames := make(map[string]string)
+	reasonCounts := make(map[git.DeletionReason]int)
+	for _, c := range snapshot.Classify() {
+		if c.IsProtected() || (c.Candidate.Reason == git.ReasonUnmerged && !includeUnmerged) {
+			continue
+		}
+		// Branches with recent commits are skipped
+		if minAge > 0 && !git.IsOlderThan(commitDates[c.Name], minAge, now) {
+			continue
+		}
+		candidates[c.Name] = c.Candidate
+		displayNames[c.Name] = c.Candidate.DisplayLabel
+		reasonCounts[c.Candidate.Reason]++
+		emitCandidate(c.Candidate)
 	}
+	summary.Candidates = len(candidates)
 
-	if len(branchesToDelete) == 0 {
+	if len(displayNames) == 0 {
 		fmt.Println("✅ No branches to delete (all branches are either active or unmerged)")
 		return
 	}
 
+	// Label each branch with its ahead/behind counts; labels map back to branch names
+	branchesToDelete, branchByLabel := divergenceLabels(snapshot, displayNames, defaultBranch)
+
 	// Sort branches for better display
 	sort.Strings(branchesToDelete)
+	if sortOrder == "age" {
+		sort.SliceStable(branchesToDelete, func(i, j int) bool {
+			return commitDates[branchByLabel[branchesToDelete[i]]].Before(commitDates[branchByLabel[branchesToDelete[j]]])
+		})
+	}
 
+	rebaseCount := reasonCounts[git.ReasonRebaseMerged]
+	squashCount := reasonCounts[git.ReasonSquashMerged]
+	unmergedCount := reasonCounts[git.ReasonUnmerged]
 	fmt.Printf("\n🔍 Found %d deletable branches:\n", len(branchesToDelete))
-	if len(goneBranches) > 0 {
-		fmt.Printf("   • %d branches with deleted remotes\n", len(goneBranches))
+	if n := reasonCounts[git.ReasonGoneRemote]; n > 0 {
+		fmt.Printf("   • %d branches with deleted remotes\n", n)
 	}
-	if len(mergedBranches) > 0 {
-		fmt.Printf("   • %d branches merged into %s\n", len(mergedBranches), defaultBranch)
+	if n := reasonCounts[git.ReasonMerged]; n > 0 {
+		fmt.Printf("   • %d branches merged into %s\n", n, strings.Join(targets, ", "))
 	}
-	if len(unmergedBranchesMap) > 0 {
-		fmt.Printf("   • %d unmerged branches ((!) requires confirmation)\n", len(unmergedBranchesMap))
+	if rebaseCount > 0 {
+		fmt.Printf("   • %d branches rebase-merged into %s\n", rebaseCount, strings.Join(targets, ", "))
+	}
+	if squashCount > 0 {
+		fmt.Printf("   • %d branches squash-merged into %s\n", squashCount, strings.Join(targets, ", "))
+	}
+	if unmergedCount > 0 {
+		fmt.Printf("   • %d unmerged branches ((!) requires confirmation)\n", unmergedCount)
 	}
 
-	// Show legend if there are unmerged branches
-	if len(unmergedBranchesMap) > 0 {
-		fmt.Println("\n   (!) Unmerged")
+	// Show legend if there are marked branches
+	if rebaseCount > 0 || squashCount > 0 || unmergedCount > 0 {
+		fmt.Println()
+	}
+	if rebaseCount > 0 {
+		fmt.Println("   (r) Rebase-merged")
+	}
+	if squashCount > 0 {
+		fmt.Println("   (s) Squash-merged")
 	}
+	if unmergedCount > 0 {
+		fmt.Println("   (!) Unmerged")
+	}
+	fmt.Printf("   ↑/↓ Commits ahead of/behind %s and the upstream branch\n", defaultBranch)
 
 	// Select branches: use all if -a flag is set, otherwise use interactive fzf
 	var selectedBranches []string
@@ -170,302 +206,258 @@ func runCleanup() {
 		selectedBranches = branchesToDelete
 	} else {
 		var err error
-		selectedBranches, err = selectBranchesWithFzf(branchesToDelete)
+		selectedBranches, err = selectItems(branchesToDelete, "Select branches to delete > ")
 		if err != nil {
 			if err.Error() == "abort" {
 				fmt.Println("\n❌ Selection cancelled")
+				emitCancelled(events.KindBranch)
+				summary.Cancelled = true
 				return
 			}
-			log.Fatalf("❌ Failed to select branches: %v", err)
+			fatalf("Failed to select branches: %v", err)
 		}
 	}
 
+	selectedNames := make([]string, len(selectedBranches))
+	for i, label := range selectedBranches {
+		selectedNames[i] = branchByLabel[label]
+	}
+	emitSelection(events.KindBranch, selectedNames)
+	summary.Selected = len(selectedNames)
+
 	if len(selectedBranches) == 0 {
 		fmt.Println("\n✅ No branches selected for deletion")
 		return
 	}
 
-	// Separate unmerged branches from safe branches
-	var safeBranches []string
-	var unmergedSelected []string
-	for _, branch := range selectedBranches {
-		if strings.HasPrefix(branch, unmergedPrefix) {
-			unmergedSelected = append(unmergedSelected, strings.TrimPrefix(branch, unmergedPrefix))
+	// Separate dangerous (unmerged) candidates from safe ones
+	var safeSelected []git.DeletionCandidate
+	var unmergedSelected []git.DeletionCandidate
+	for _, label := range selectedBranches {
+		c := candidates[branchByLabel[label]]
+		if c.RiskLevel == git.RiskDangerous {
+			unmergedSelected = append(unmergedSelected, c)
 		} else {
-			safeBranches = append(safeBranches, branch)
+			safeSelected = append(safeSelected, c)
 		}
 	}
 
 	// Show branches to delete
 	fmt.Printf("\n⚠️  The following branches will be deleted:\n")
-	for _, branch := range safeBranches {
-		fmt.Printf("  • %s\n", branch)
+	for _, c := range safeSelected {
+		fmt.Printf("  • %s\n", c.Name)
 	}
-	for _, branch := range unmergedSelected {
-		fmt.Printf("  • %s%s (local + remote)\n", unmergedPrefix, branch)
+	for _, c := range unmergedSelected {
+		fmt.Printf("  • %s (local + remote)\n", c.DisplayLabel)
 	}
 
 	// Confirm deletion for safe branches (unless --force is used)
-	if len(safeBranches) > 0 && !forceDelete {
-		fmt.Print("\nAre you sure you want to delete these branches? (y/N): ")
-		reader := bufio.NewReader(os.Stdin)
-		response, _ := reader.ReadString('\n')
-		response = strings.TrimSpace(strings.ToLower(response))
-
-		if response != "y" && response != "yes" {
+	if len(safeSelected) > 0 && !forceDelete {
+		if !confirmDeletion("Are you sure you want to delete these branches?") {
 			fmt.Println("❌ Deletion cancelled")
+			summary.Cancelled = true
 			return
 		}
 	}
 
 	// Always confirm unmerged branches (even with -f)
 	if len(unmergedSelected) > 0 {
-		fmt.Printf("\n🚨 WARNING: You are about to delete %d UNMERGED branch(es):\n", len(unmergedSelected))
-		for _, branch := range unmergedSelected {
-			fmt.Printf("   • %s (will be deleted locally AND from remote)\n", branch)
+		items := make([]string, len(unmergedSelected))
+		for i, c := range unmergedSelected {
+			items[i] = c.Name + " (will be deleted locally AND from remote)"
 		}
-		fmt.Print("\n⚠️  This action cannot be undone! Type 'DELETE' to confirm: ")
-		reader := bufio.NewReader(os.Stdin)
-		response, _ := reader.ReadString('\n')
-		response = strings.TrimSpace(response)
-
-		if response != "DELETE" {
+		if !confirmDangerousOperation(items, "UNMERGED branch(es)") {
 			fmt.Println("❌ Deletion of unmerged branches cancelled")
-			// Still delete safe branches if force was used
-			if forceDelete && len(safeBranches) > 0 {
-				unmergedSelected = []string{}
-			} else {
+			// Still delete safe branches if force was used; the run is only
+			// cancelled if nothing is deleted
+			if !forceDelete || len(safeSelected) == 0 {
+				summary.Cancelled = true
 				return
 			}
+			unmergedSelected = nil
 		}
 	}
 
-	// Delete safe branches
-	deletedCount := 0
-	for _, branch := range safeBranches {
-		if err := deleteBranch(branch); err != nil {
-			fmt.Printf("❌ Failed to delete branch %s: %v\n", branch, err)
+	// In dry-run mode, show what would have been executed and stop here
+	if dryRun {
+		var operations []string
+		if atomicDelete {
+			operations = plannedAtomicDeletion(repo, append(safeSelected, unmergedSelected...), remote)
 		} else {
-			fmt.Printf("✅ Deleted branch: %s\n", branch)
-			deletedCount++
+			for _, c := range safeSelected {
+				operations = append(operations, plannedBranchDeletion(c)...)
+			}
+			for _, c := range unmergedSelected {
+				operations = append(operations, plannedBranchDeletionWithRemote(repo, c.Name, remote)...)
+			}
 		}
+		printDryRun(operations)
+		return
 	}
 
-	// Delete unmerged branches (local + remote)
-	for _, branch := range unmergedSelected {
-		if err := deleteBranchWithRemote(branch); err != nil {
-			fmt.Printf("❌ Failed to delete branch %s: %v\n", branch, err)
-		} else {
-			fmt.Printf("✅ Deleted branch (local + remote): %s\n", branch)
-			deletedCount++
+	if atomicDelete {
+		deleted, err := deleteBranchesAtomic(repo, "", safeSelected, unmergedSelected, remote, summary)
+		if err != nil {
+			fatalf("%v", err)
 		}
-	}
-
-	fmt.Printf("\n🎉 Successfully deleted %d branches\n", deletedCount)
-}
-
-func checkGitRepository() error {
-	cmd := exec.Command("git", "status")
-	cmd.Env = append(os.Environ(), "LC_ALL=C")
-	return cmd.Run()
-}
-
-func updateRemoteRefs() error {
-	// Fetch all remotes with prune
-	cmd := exec.Command("git", "fetch", "--all", "--prune")
-	cmd.Env = append(os.Environ(), "LC_ALL=C")
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("fetch failed: %s", string(output))
-	}
-
-	// Update remote tracking branches
-	cmd = exec.Command("git", "remote", "update", "--prune")
-	cmd.Env = append(os.Environ(), "LC_ALL=C")
-	output, err = cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("remote update failed: %s", string(output))
-	}
-
-	return nil
-}
-
-func getDefaultBranch() (string, error) {
-	// Try to get the default branch from remote
-	cmd := exec.Command("git", "symbolic-ref", "refs/remotes/origin/HEAD")
-	cmd.Env = append(os.Environ(), "LC_ALL=C")
-	output, err := cmd.Output()
-	if err == nil {
-		// Extract branch name from refs/remotes/origin/main format
-		parts := strings.Split(strings.TrimSpace(string(output)), "/")
-		if len(parts) > 0 {
-			return parts[len(parts)-1], nil
+		if deleted > 0 {
+			fmt.Printf("\n🎉 Successfully deleted %d branches\n", deleted)
 		}
+		return
 	}
 
-	// Fallback: try common default branch names
-	commonDefaults := []string{"main", "master", "develop"}
-	for _, branch := range commonDefaults {
-		cmd := exec.Command("git", "rev-parse", "--verify", branch)
-		cmd.Env = append(os.Environ(), "LC_ALL=C")
-		if err := cmd.Run(); err == nil {
-			return branch, nil
+	// Delete safe branches; the classification already established they are
+	// merged, so git's own merge check may be overridden. Unmerged branches
+	// are deleted locally and from their remote. Branches that moved while
+	// the selector was open are kept.
+	deletedCount := 0
+	var moved []string
+	for _, c := range append(safeSelected, unmergedSelected...) {
+		record, err := repo.DeleteCandidate(c, remote)
+		if reportDeletion(c, record, err) {
+			deletedCount++
+		}
+		if branchMoved(err) {
+			moved = append(moved, c.Name)
 		}
+		emitDeletion(summary, events.Deletion{Kind: events.KindBranch, Name: c.Name}, err)
 	}
 
-	return "main", nil // Final fallback
-}
-
-func getCurrentBranch() (string, error) {
-	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
-	cmd.Env = append(os.Environ(), "LC_ALL=C")
-	output, err := cmd.Output()
-	if err != nil {
-		return "", err
+	if len(moved) > 0 {
+		fmt.Printf("\n⚠️  %d branch(es) received new commits while you were selecting and were kept: %s\n",
+			len(moved), strings.Join(moved, ", "))
+		fmt.Println("   Run git-gone again to review them.")
 	}
-	return strings.TrimSpace(string(output)), nil
+	fmt.Printf("\n🎉 Successfully deleted %d branches\n", deletedCount)
 }
 
-func getGoneBranches() ([]string, error) {
-	// Get branches whose remote tracking branch is gone
-	// Use LC_ALL=C to ensure consistent English output across all platforms
-	cmd := exec.Command("git", "branch", "--format", "%(refname:short) %(upstream:track)")
-	cmd.Env = append(os.Environ(), "LC_ALL=C")
-	output, err := cmd.Output()
-	if err != nil {
-		return nil, err
-	}
-
-	lines := strings.Split(string(output), "\n")
-	var branches []string
-	for _, line := range lines {
-		// Check if the branch has [gone] (always in English due to LC_ALL=C)
-		if strings.Contains(line, "[gone]") {
-			// Extract branch name (first word)
-			parts := strings.Fields(line)
-			if len(parts) > 0 {
-				branches = append(branches, parts[0])
-			}
+// reportDeletion prints the result of deleting a branch candidate and
+// returns whether the local branch was deleted. A deletion that left a step
+// undone, such as an unmerged branch kept on its remote, is reported as such.
+func reportDeletion(c git.DeletionCandidate, record git.DeletionRecord, err error) bool {
+	var incomplete *git.IncompleteDeletionError
+	switch {
+	case errors.As(err, &incomplete):
+		fmt.Printf("⚠️  Deleted branch %s, but:\n", c.Name)
+		for _, ref := range incomplete.Refs {
+			fmt.Printf("   • %s\n", ref.Reason)
 		}
-	}
-	return branches, nil
+	case err != nil:
+		reportDeletionFailure(c, err)
+		return false
+	case c.RiskLevel != git.RiskDangerous:
+		fmt.Printf("✅ Deleted branch: %s\n", c.Name)
+	case record.DeletedFromRemote != "":
+		fmt.Printf("✅ Deleted branch (local + remote): %s\n", c.Name)
+	default:
+		fmt.Printf("✅ Deleted branch (local only, not on a remote): %s\n", c.Name)
+	}
+	return true
 }
 
-func getMergedBranches(defaultBranch string) ([]string, error) {
-	// Get branches merged into the default branch
-	cmd := exec.Command("git", "branch", "--merged", defaultBranch)
-	cmd.Env = append(os.Environ(), "LC_ALL=C")
-	output, err := cmd.Output()
-	if err != nil {
-		return nil, err
-	}
-
-	lines := strings.Split(string(output), "\n")
-	var branches []string
-	for _, line := range lines {
-		// Remove the asterisk for current branch and trim spaces
-		branch := strings.TrimSpace(strings.TrimPrefix(line, "*"))
-		branch = strings.TrimSpace(branch)
-		if branch != "" {
-			branches = append(branches, branch)
-		}
+// reportDeletionFailure prints why a branch could not be deleted
+func reportDeletionFailure(c git.DeletionCandidate, err error) {
+	var movedErr *git.BranchMovedError
+	if errors.As(err, &movedErr) {
+		fmt.Printf("⚠️  Kept branch %s: it moved from %s to %s\n", c.Name, git.ShortSHA(movedErr.Expected), git.ShortSHA(movedErr.Actual))
+		return
 	}
-	return branches, nil
+	fmt.Printf("❌ Failed to delete branch %s: %v\n", c.Name, err)
 }
 
-func selectBranchesWithFzf(branches []string) ([]string, error) {
-	if len(branches) == 0 {
-		return []string{}, nil
-	}
+// branchMoved reports whether a deletion failed because the branch moved
+// since it was classified
+func branchMoved(err error) bool {
+	var movedErr *git.BranchMovedError
+	return errors.As(err, &movedErr)
+}
 
-	f, err := fzf.New(
-		fzf.WithLimit(len(branches)), // Allow selecting all branches
-		fzf.WithNoLimit(true),        // Remove limit on selections
-		fzf.WithPrompt("Select branches to delete > "),
-		fzf.WithCursor("> "),
-		fzf.WithSelectedPrefix("[✓] "),
-		fzf.WithUnselectedPrefix("[ ] "),
-		fzf.WithInputPlaceholder("Type to filter, Tab to select/deselect, Enter to confirm, Esc to cancel"),
-	)
+// deleteBranchesAtomic deletes the selected branches of r in a single
+// transaction, so either every branch is deleted or none is. Deletion events
+// carry repository, which is empty outside scan. It returns the number of
+// deleted branches, and an error if the transaction could not be run at all,
+// in which case no event is emitted.
+func deleteBranchesAtomic(r *git.Repository, repository string, safeSelected, unmergedSelected []git.DeletionCandidate, remote string, summary *events.Summary) (int, error) {
+	merged, err := r.FullyMergedBranches()
 	if err != nil {
-		return nil, err
+		return 0, fmt.Errorf("failed to list branches: %w", err)
 	}
 
-	indices, err := f.Find(branches, func(i int) string {
-		return branches[i]
-	})
-
-	if err != nil {
-		return nil, fmt.Errorf("abort")
+	// As in the one-by-one deletion, safe branches proven merged are
+	// force-deleted when git's own merge check would refuse them; unmerged
+	// branches always are and are also deleted from the remote
+	var deletions []git.BranchDeletion
+	for _, c := range safeSelected {
+		deletions = append(deletions, git.BranchDeletion{Name: c.Name, SHA: c.SHA, Force: c.ProvenMerged() && !merged[c.Name]})
 	}
-
-	selected := make([]string, len(indices))
-	for i, idx := range indices {
-		selected[i] = branches[idx]
+	for _, c := range unmergedSelected {
+		deletions = append(deletions, git.BranchDeletion{Name: c.Name, SHA: c.SHA, Force: true, DeleteRemote: true})
 	}
 
-	return selected, nil
-}
-
-func deleteBranch(branch string) error {
-	cmd := exec.Command("git", "branch", "-d", branch)
-	cmd.Env = append(os.Environ(), "LC_ALL=C")
-	err := cmd.Run()
-
-	// If safe delete fails, the branch might have unmerged commits
-	// but we already know it's merged to default branch
-	if err != nil {
-		// Try force delete
-		cmd = exec.Command("git", "branch", "-D", branch)
-		cmd.Env = append(os.Environ(), "LC_ALL=C")
-		output, err := cmd.CombinedOutput()
-		if err != nil {
-			return fmt.Errorf("%s", string(output))
+	records, err := r.DeleteBranchesAtomic(deletions, remote)
+	var incomplete *git.IncompleteDeletionError
+	if err != nil && !errors.As(err, &incomplete) {
+		var rejected *git.TransactionError
+		if !errors.As(err, &rejected) {
+			return 0, fmt.Errorf("failed to delete branches: %w", err)
 		}
+		fmt.Println("❌ Transaction rejected, no branches were deleted:")
+		reasons := make(map[string]string)
+		for _, ref := range rejected.Refs {
+			if ref.Branch == "" {
+				fmt.Printf("  • %s\n", ref.Reason)
+			} else {
+				fmt.Printf("  • %s: %s\n", ref.Branch, ref.Reason)
+				reasons[ref.Branch] = ref.Reason
+			}
+		}
+		for _, d := range deletions {
+			reason := reasons[d.Name]
+			if reason == "" {
+				reason = "transaction rejected"
+			}
+			emitDeletion(summary, events.Deletion{Kind: events.KindBranch, Name: d.Name, Repository: repository}, errors.New(reason))
+		}
+		return 0, nil
 	}
 
-	return nil
-}
-
-func getAllLocalBranches() ([]string, error) {
-	cmd := exec.Command("git", "branch", "--format", "%(refname:short)")
-	cmd.Env = append(os.Environ(), "LC_ALL=C")
-	output, err := cmd.Output()
-	if err != nil {
-		return nil, err
-	}
-
-	lines := strings.Split(string(output), "\n")
-	var branches []string
-	for _, line := range lines {
-		branch := strings.TrimSpace(line)
-		if branch != "" {
-			branches = append(branches, branch)
+	// Every branch is deleted; steps after the transaction may have failed
+	// for some of them
+	for i, c := range append(append([]git.DeletionCandidate{}, safeSelected...), unmergedSelected...) {
+		var branchErr error
+		if incomplete != nil {
+			branchErr = incomplete.For(c.Name)
 		}
+		reportDeletion(c, records[i], branchErr)
+		emitDeletion(summary, events.Deletion{Kind: events.KindBranch, Name: c.Name, Repository: repository}, branchErr)
 	}
-	return branches, nil
+	return len(deletions), nil
 }
 
-func deleteBranchWithRemote(branch string) error {
-	// First try to delete remote branch
-	cmd := exec.Command("git", "push", "origin", "--delete", branch)
-	cmd.Env = append(os.Environ(), "LC_ALL=C")
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		// Remote might not exist, log warning but continue
-		outputStr := string(output)
-		if !strings.Contains(outputStr, "remote ref does not exist") {
-			fmt.Printf("⚠️  Warning: Failed to delete remote branch %s: %s\n", branch, outputStr)
+// divergenceLabels builds selector labels showing how far each branch is
+// ahead of and behind the default branch and its upstream, e.g.
+// "(!) feature-x  main ↑25 ↓340  origin/feature-x ↑0 ↓2", from the snapshot.
+// It returns the labels and a map from label back to branch name.
+func divergenceLabels(snapshot *git.BranchSnapshot, displayNames map[string]string, defaultBranch string) ([]string, map[string]string) {
+	width := 0
+	for _, name := range displayNames {
+		if len(name) > width {
+			width = len(name)
 		}
 	}
 
-	// Force delete local branch
-	cmd = exec.Command("git", "branch", "-D", branch)
-	cmd.Env = append(os.Environ(), "LC_ALL=C")
-	output, err = cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("%s", string(output))
+	var labels []string
+	branchByLabel := make(map[string]string)
+	for branch, name := range displayNames {
+		label := name
+		if divergence, ok := snapshot.Divergence(branch, defaultBranch); ok {
+			label = fmt.Sprintf("%-*s  %s %s", width, name, defaultBranch, divergence)
+		}
+		if ref, _ := snapshot.Ref(branch); ref.HasUpstream() {
+			label += fmt.Sprintf("  %s %s", ref.Upstream, ref.UpstreamDivergence)
+		}
+		labels = append(labels, label)
+		branchByLabel[label] = branch
 	}
-
-	return nil
+	return labels, branchByLabel
 }
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"git-gone/internal/config"
+	"git-gone/internal/git"
+	"git-gone/internal/tui"
+
+	"github.com/spf13/cobra"
+)
+
+// loadConfig loads the effective configuration for the current repository and
+// applies its defaults to every flag not given on the command line. Warnings
+// go to stderr, so they never mix with JSON or events on stdout.
+func loadConfig(cmd *cobra.Command) {
+	// Outside a repository only the global config file applies
+	loaded, err := config.Load(repo)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s  Warning: %v\n", tui.EmojiWarning, err)
+	}
+	cfg = loaded
+	for _, warning := range cfg.Warnings {
+		fmt.Fprintf(os.Stderr, "%s  Warning: %s\n", tui.EmojiWarning, warning)
+	}
+
+	applyBoolDefault(cmd, "force", cfg.Defaults.Force)
+	applyBoolDefault(cmd, "all", cfg.Defaults.All)
+	applyBoolDefault(cmd, "unmerged", cfg.Defaults.Unmerged)
+	applyBoolDefault(cmd, "dry-run", cfg.Defaults.DryRun)
+	applyBoolDefault(cmd, "atomic", cfg.Defaults.Atomic)
+	// The output default is a report format. The event stream is set up
+	// before the config is loaded, so ndjson can only be chosen with --output.
+	if cfg.Defaults.Output != nil && !globalOutputFlag(cmd) {
+		if *cfg.Defaults.Output == "ndjson" {
+			fmt.Fprintf(os.Stderr, "%s  Warning: output: ndjson cannot be a config default, use --output ndjson\n", tui.EmojiWarning)
+		} else {
+			applyDefault(cmd, "output", *cfg.Defaults.Output)
+		}
+	}
+
+	if cfg.TagRetention.KeepPatches != nil {
+		applyDefault(cmd, "keep-patches", strconv.Itoa(*cfg.TagRetention.KeepPatches))
+	}
+	applyBoolDefault(cmd, "drop-prereleases", cfg.TagRetention.DropPrereleases)
+	if cfg.TagRetention.KeepNightlies != nil {
+		applyDefault(cmd, "keep-nightlies", strconv.Itoa(*cfg.TagRetention.KeepNightlies))
+	}
+	if cfg.TagRetention.NightlyPattern != nil {
+		applyDefault(cmd, "nightly-pattern", *cfg.TagRetention.NightlyPattern)
+	}
+
+	// Targets from the config apply unless --target was given
+	if flag := cmd.Flags().Lookup("target"); flag != nil && !flag.Changed {
+		targetPatterns = cfg.Targets
+	}
+	// Authors from the config apply unless --author was given
+	if flag := cmd.Flags().Lookup("author"); flag != nil && !flag.Changed {
+		authorPatterns = cfg.Authors
+	}
+	if cfg.Remote != "" {
+		applyDefault(cmd, "remote", cfg.Remote)
+	}
+	if cfg.Backend != "" {
+		applyDefault(cmd, "backend", cfg.Backend)
+	}
+}
+
+// resolveRemote returns the remote to compare against: --remote or the
+// configured remote, otherwise origin or the first configured remote. It
+// returns an empty string if the repository has no remotes.
+func resolveRemote() string {
+	remote, err := repo.ResolveRemote(remoteName)
+	if err != nil {
+		fatalf("%v", err)
+	}
+	return remote
+}
+
+// resolveTargets returns the integration targets for merge detection, with the
+// default branch first
+func resolveTargets(defaultBranch string) []string {
+	branches, err := repo.GetAllLocalBranches()
+	if err != nil {
+		return []string{defaultBranch}
+	}
+	return git.ResolveTargets(defaultBranch, targetPatterns, branches)
+}
+
+// applyBoolDefault sets a boolean flag from the config unless it was given explicitly
+func applyBoolDefault(cmd *cobra.Command, name string, value *bool) {
+	if value != nil {
+		applyDefault(cmd, name, strconv.FormatBool(*value))
+	}
+}
+
+// applyDefault sets a flag from the config unless it was given explicitly
+func applyDefault(cmd *cobra.Command, name, value string) {
+	flag := cmd.Flags().Lookup(name)
+	if flag == nil || flag.Changed {
+		return
+	}
+	if err := flag.Value.Set(value); err != nil {
+		fmt.Fprintf(os.Stderr, "%s  Warning: invalid config value %q for --%s: %v\n", tui.EmojiWarning, value, name, err)
+	}
+}
@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"git-gone/internal/events"
+	"git-gone/internal/git"
+	"git-gone/internal/tui"
+)
+
+// printDryRun lists the git operations a command would have executed
+func printDryRun(operations []string) {
+	events.Emit(&events.DryRun{Operations: append([]string{}, operations...)})
+	fmt.Printf("\n%s Dry run: no changes were made. The following git operations would run:\n", tui.EmojiDryRun)
+	for _, op := range operations {
+		fmt.Printf("   $ %s\n", op)
+	}
+}
+
+// plannedBackup returns the operation that copies a ref into the trash
+func plannedBackup(ref string) string {
+	return fmt.Sprintf("git update-ref %s<timestamp>/%s %s", git.TrashNamespace, strings.TrimPrefix(ref, "refs/"), ref)
+}
+
+// plannedBranchRefDeletion returns the update-ref transaction that backs up
+// and deletes a branch in one step
+func plannedBranchRefDeletion(branch string) []string {
+	ref := "refs/heads/" + branch
+	return []string{
+		"git update-ref --stdin <<EOF",
+		fmt.Sprintf("  update %s<timestamp>/heads/%s %s", git.TrashNamespace, branch, ref),
+		fmt.Sprintf("  delete %s <sha>", ref),
+		"EOF",
+		fmt.Sprintf("git config --remove-section branch.%s", branch),
+	}
+}
+
+// plannedBranchDeletion returns the git operations deleting a safe branch
+// candidate would run
+func plannedBranchDeletion(c git.DeletionCandidate) []string {
+	return plannedBranchRefDeletion(c.Name)
+}
+
+// plannedRemoteBranchDeletion returns the leased push deleting branches from
+// remote. Each deletion is leased on the commit in leases, or on the
+// remote-tracking ref of a branch missing from it
+func plannedRemoteBranchDeletion(remote string, branches []string, leases map[string]string) string {
+	var args []string
+	for _, branch := range branches {
+		lease, ok := leases[branch]
+		if !ok {
+			lease = fmt.Sprintf("<%s/%s>", remote, branch)
+		}
+		args = append(args, fmt.Sprintf("--force-with-lease=refs/heads/%s:%s", branch, lease))
+	}
+	return fmt.Sprintf("git push --porcelain %s %s --delete %s", strings.Join(args, " "), remote, strings.Join(branches, " "))
+}
+
+// plannedBranchDeletionWithRemote returns the git operations git.DeleteBranchWithRemote would run
+func plannedBranchDeletionWithRemote(r *git.Repository, branch, fallbackRemote string) []string {
+	operations := plannedBranchRefDeletion(branch)
+	if remote := r.GetBranchRemote(branch, fallbackRemote); remote != "" {
+		operations = append(operations, plannedRemoteBranchDeletion(remote, []string{branch}, nil))
+	}
+	return operations
+}
+
+// plannedAtomicDeletion returns the git operations git.DeleteBranchesAtomic
+// would run: one update-ref transaction backing up and deleting every branch,
+// then one push per remote for the dangerous candidates
+func plannedAtomicDeletion(r *git.Repository, candidates []git.DeletionCandidate, fallbackRemote string) []string {
+	operations := []string{"git update-ref --stdin <<EOF"}
+	byRemote := make(map[string][]string)
+	var remotes []string
+	for _, c := range candidates {
+		ref := "refs/heads/" + c.Name
+		operations = append(operations,
+			fmt.Sprintf("  update %s<timestamp>/heads/%s %s", git.TrashNamespace, c.Name, ref),
+			fmt.Sprintf("  delete %s <sha>", ref))
+		if c.RiskLevel != git.RiskDangerous {
+			continue
+		}
+		if remote := r.GetBranchRemote(c.Name, fallbackRemote); remote != "" {
+			if byRemote[remote] == nil {
+				remotes = append(remotes, remote)
+			}
+			byRemote[remote] = append(byRemote[remote], c.Name)
+		}
+	}
+	operations = append(operations, "EOF")
+	for _, remote := range remotes {
+		operations = append(operations, plannedRemoteBranchDeletion(remote, byRemote[remote], nil))
+	}
+	return operations
+}
+
+// plannedBranchRestore returns the git operations git.RestoreBranch would run
+func plannedBranchRestore(record git.DeletionRecord) []string {
+	operations := []string{fmt.Sprintf("git branch %s %s", record.Branch, record.SHA)}
+	if record.Remote != "" {
+		operations = append(operations, fmt.Sprintf("git config branch.%s.remote %s", record.Branch, record.Remote))
+	}
+	if record.Merge != "" {
+		operations = append(operations, fmt.Sprintf("git config branch.%s.merge %s", record.Branch, record.Merge))
+	}
+	if record.TrashRef != "" {
+		operations = append(operations, "git update-ref -d "+record.TrashRef)
+	}
+	return operations
+}
+
+// plannedBranchPush returns the push git.PushBranch would run
+func plannedBranchPush(branch, remote string) string {
+	return fmt.Sprintf("git push %s refs/heads/%s:refs/heads/%s", remote, branch, branch)
+}
+
+// plannedTrashRestore returns the git operations git.RestoreFromTrash would run
+func plannedTrashRestore(entry git.TrashEntry) []string {
+	return []string{
+		fmt.Sprintf("git update-ref %s %s \"\"", entry.Original, entry.SHA),
+		"git update-ref -d " + entry.Ref,
+	}
+}
+
+// plannedTagDeletion returns the git operations git.DeleteTag would run
+func plannedTagDeletion(tag string) []string {
+	return []string{
+		plannedBackup("refs/tags/" + tag),
+		"git tag -d " + tag,
+	}
+}
+
+// plannedTagReset returns the git operations git.ResetTagToRemote would run
+func plannedTagReset(d git.DivergentTag) []string {
+	return []string{
+		plannedBackup("refs/tags/" + d.Name),
+		fmt.Sprintf("git fetch --no-tags %s +refs/tags/%s:refs/tags/%s", d.Remote, d.Name, d.Name),
+	}
+}
+
+// plannedRemoteTagDeletion returns the leased push git.DeleteRemoteTags would run
+func plannedRemoteTagDeletion(remote string, leases map[string]string) string {
+	var args, refs []string
+	for _, tag := range sortedLeaseTags(leases) {
+		args = append(args, fmt.Sprintf("--force-with-lease=refs/tags/%s:%s", tag, leases[tag]))
+		refs = append(refs, "refs/tags/"+tag)
+	}
+	return fmt.Sprintf("git push %s %s --delete %s", strings.Join(args, " "), remote, strings.Join(refs, " "))
+}
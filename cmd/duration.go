@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseAge parses an age such as "30d", "2w" or "12h".
+//
+// In addition to the units understood by time.ParseDuration it accepts "d"
+// (days) and "w" (weeks), which are the natural units for branch and tag ages.
+func parseAge(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+
+	units := map[string]time.Duration{
+		"d": 24 * time.Hour,
+		"w": 7 * 24 * time.Hour,
+	}
+	for suffix, unit := range units {
+		if strings.HasSuffix(value, suffix) {
+			n, err := strconv.Atoi(strings.TrimSuffix(value, suffix))
+			if err != nil || n < 0 {
+				return 0, fmt.Errorf("invalid age %q", value)
+			}
+			return time.Duration(n) * unit, nil
+		}
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid age %q (use e.g. 30d, 2w or 12h)", value)
+	}
+	return d, nil
+}
+
+// parseBranchAgeFlags validates --older-than and --sort, exiting on invalid values
+func parseBranchAgeFlags() time.Duration {
+	if sortOrder != "name" && sortOrder != "age" {
+		fatalf("Invalid --sort value %q (use name or age)", sortOrder)
+	}
+	age, err := parseAge(olderThan)
+	if err != nil {
+		fatalf("%v", err)
+	}
+	return age
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"git-gone/internal/events"
+	"git-gone/internal/tui"
+)
+
+// Exit codes of git-gone, documented in the README. Scripts rely on them, so
+// existing codes never change meaning.
+const (
+	// exitOK: every selected item was handled, possibly none, or report
+	// --check found nothing
+	exitOK = 0
+	// exitError: the command stopped because of an error or invalid flags
+	exitError = 1
+	// exitNothingToDo: there was nothing to delete or restore to choose from
+	exitNothingToDo = 2
+	// exitCandidatesFound: report --check found branches to delete
+	exitCandidatesFound = 3
+	// exitPartialFailure: some of the selected items could not be handled
+	exitPartialFailure = 4
+	// exitTotalFailure: none of the selected items could be handled
+	exitTotalFailure = 5
+	// exitCancelled: the selection or a confirmation was declined
+	exitCancelled = 6
+	// exitNotRepository: the command needs a git repository
+	exitNotRepository = 7
+)
+
+// exitf reports an error that stops the command and exits with code. In
+// ndjson mode it is emitted as an error event.
+func exitf(code int, format string, args ...any) {
+	message := fmt.Sprintf(format, args...)
+	events.Emit(&events.Error{Message: message})
+	fmt.Printf("%s %s\n", tui.EmojiError, message)
+	os.Exit(code)
+}
+
+// fatalf reports an error that stops the command and exits with exitError
+func fatalf(format string, args ...any) {
+	exitf(exitError, format, args...)
+}
+
+// requireRepository stops the command unless it runs in a git repository
+func requireRepository() {
+	if err := repo.CheckGitRepository(); err != nil {
+		exitf(exitNotRepository, "Not in a git repository")
+	}
+}
+
+// exitCode returns the exit code of a command that completed with summary
+func exitCode(summary *events.Summary) int {
+	switch {
+	case summary.Cancelled:
+		return exitCancelled
+	case summary.Failed > 0 && summary.Succeeded == 0:
+		return exitTotalFailure
+	case summary.Failed > 0:
+		return exitPartialFailure
+	case summary.Candidates == 0:
+		// An empty selection among candidates is not "nothing to do": the
+		// user chose to keep everything
+		return exitNothingToDo
+	}
+	return exitOK
+}
+
+// finish emits the summary of a completed command and exits with its exit
+// code. Commands defer it first, so it runs after their other deferred calls.
+func finish(summary *events.Summary) {
+	events.Emit(summary)
+	if code := exitCode(summary); code != exitOK {
+		os.Exit(code)
+	}
+}
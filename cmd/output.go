@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"git-gone/internal/events"
+	"git-gone/internal/git"
+	"git-gone/internal/tui"
+
+	"github.com/spf13/cobra"
+)
+
+// outputFormat is the global --output flag: text, or ndjson for the event
+// stream. The report commands have their own --output flag, which also
+// accepts ndjson.
+var outputFormat string
+
+// eventOutput is the standard output the event stream is written to; human
+// text is discarded in ndjson mode
+var eventOutput *os.File
+
+// globalOutputFlag reports whether the --output flag of cmd is the global
+// one, rather than the report format flag of the report commands
+func globalOutputFlag(cmd *cobra.Command) bool {
+	return !cmd.HasParent() || cmd.InheritedFlags().Lookup("output") != nil
+}
+
+// setupOutput switches to the event stream when --output ndjson is set. It
+// runs before the config is loaded, so nothing is printed to stdout before.
+func setupOutput(cmd *cobra.Command) {
+	flag := cmd.Flags().Lookup("output")
+	if flag == nil {
+		return
+	}
+	format := flag.Value.String()
+	if format != "ndjson" {
+		if globalOutputFlag(cmd) && flag.Changed && format != "text" {
+			fmt.Printf("%s Invalid --output value %q (use text or ndjson)\n", tui.EmojiError, format)
+			os.Exit(exitError)
+		}
+		return
+	}
+
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		fmt.Printf("%s %v\n", tui.EmojiError, err)
+		os.Exit(exitError)
+	}
+	eventOutput = os.Stdout
+	events.Enable(eventOutput)
+	os.Stdout = devNull
+	os.Stderr = devNull
+}
+
+// updateRemoteRefs fetches the remotes with update, printing progress to w. A
+// failed update is only a warning: the command goes on with the references
+// it has. The fetch prunes remote-tracking refs, so it is skipped with
+// --dry-run and the command works from the references it already has.
+func updateRemoteRefs(w io.Writer, update func() error) {
+	if dryRun {
+		fmt.Fprintf(w, "%s Dry run: not updating remote references (git fetch --all --prune)\n", tui.EmojiDryRun)
+		return
+	}
+	fmt.Fprintln(w, "🔄 Updating remote references...")
+	events.Emit(&events.FetchStarted{})
+	finished := &events.FetchFinished{}
+	if err := update(); err != nil {
+		fmt.Fprintf(w, "%s  Warning: Failed to update remote refs: %v\n", tui.EmojiWarning, err)
+		finished.Error = err.Error()
+	}
+	events.Emit(finished)
+}
+
+// selectItems shows the interactive selector. It is not available in ndjson
+// mode, where items must be chosen with --all.
+func selectItems(items []string, prompt string) ([]string, error) {
+	if events.Enabled() && len(items) > 0 {
+		return nil, errors.New("interactive selection is not available with --output ndjson, use --all")
+	}
+	return tui.SelectItems(items, prompt)
+}
+
+// confirmDeletion asks for a y/N confirmation. Prompts are not shown with
+// --output ndjson, where --force skips the confirmation instead.
+func confirmDeletion(message string) bool {
+	if events.Enabled() {
+		fatalf("Confirmation is not available with --output ndjson, use --force")
+	}
+	return tui.ConfirmDeletion(message)
+}
+
+// confirmDangerousOperation requires typing DELETE. It cannot be answered with
+// --output ndjson, so dangerous deletions fail there instead of waiting on a
+// prompt nobody sees.
+func confirmDangerousOperation(items []string, itemType string) bool {
+	if events.Enabled() {
+		fatalf("Deleting %d %s requires typing DELETE, which is not available with --output ndjson", len(items), itemType)
+	}
+	return tui.ConfirmDangerousOperation(items, itemType)
+}
+
+// promptChoice asks the user to pick one of choices; not available with
+// --output ndjson
+func promptChoice(message string, choices ...string) string {
+	if events.Enabled() {
+		fatalf("%s is not available with --output ndjson", strings.TrimSuffix(message, "?"))
+	}
+	return tui.PromptChoice(message, choices...)
+}
+
+// riskName returns the event name of a risk level
+func riskName(risk git.RiskLevel) string {
+	if risk == git.RiskDangerous {
+		return "dangerous"
+	}
+	return "safe"
+}
+
+// emitCandidate emits a candidate event for a branch or tag candidate
+func emitCandidate(c git.DeletionCandidate) {
+	kind := events.KindBranch
+	if c.Type == git.CandidateTag {
+		kind = events.KindTag
+	}
+	events.Emit(&events.Candidate{Kind: kind, Name: c.Name, Reason: c.Reason.String(), Risk: riskName(c.RiskLevel), SHA: c.SHA})
+}
+
+// emitSelection emits the items chosen for a command
+func emitSelection(kind string, selected []string) {
+	events.Emit(&events.Selection{Kind: kind, Selected: append([]string{}, selected...)})
+}
+
+// emitCancelled emits a cancelled selection
+func emitCancelled(kind string) {
+	events.Emit(&events.Selection{Kind: kind, Selected: []string{}, Cancelled: true})
+}
+
+// emitDeletion emits the result of deleting an item and counts it in summary
+func emitDeletion(summary *events.Summary, deletion events.Deletion, err error) {
+	if err != nil {
+		deletion.Error = err.Error()
+		summary.Failed++
+	} else {
+		summary.Succeeded++
+	}
+	events.Emit(&deletion)
+}
+
+// emitRestore emits the result of restoring an item and counts it in summary
+func emitRestore(summary *events.Summary, restore events.Restore, err error) {
+	if err != nil {
+		restore.Error = err.Error()
+		summary.Failed++
+	} else {
+		summary.Succeeded++
+	}
+	events.Emit(&restore)
+}
@@ -0,0 +1,264 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"git-gone/internal/events"
+	"git-gone/internal/git"
+	"git-gone/internal/tui"
+
+	"github.com/spf13/cobra"
+)
+
+// Remote branch flags
+var authorPatterns []string
+
+var remoteBranchesCmd = &cobra.Command{
+	Use:   "remote-branches",
+	Short: "Delete merged or old branches from the remote",
+	Long: `Delete merged or old branches from the remote.
+
+This command will:
+  1. Update all remote references, pruning stale remote-tracking refs
+  2. Find branches on the remote that are merged into an integration target,
+     or whose last commit is older than --older-than
+  3. Show an interactive selector to choose branches to delete
+  4. Require typing DELETE to confirm (even with --force)
+  5. Delete the selected branches from the remote in a single push
+
+Use --author (or "authors" in the config) to only consider branches whose
+last commit was made by one of the given authors. Default and integration
+branches, and branches matching a protected pattern, are never offered.
+
+The remote-tracking refs are backed up in the trash before the push, so the
+commits stay available locally (see "git-gone trash list").
+
+Interactive Controls:
+  ↑/↓         Navigate through the list
+  Tab         Toggle selection
+  Enter       Confirm selection
+  Esc         Cancel operation
+  Type        Filter by branch or author`,
+	Example: `  # Choose merged branches to delete from origin
+  git-gone remote-branches
+
+  # Only your own branches, including unmerged ones untouched for 6 months
+  git-gone remote-branches --author 'me@example.com' --older-than 26w
+
+  # Show the push that would run without deleting anything
+  git-gone remote-branches --all --dry-run`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runRemoteBranches()
+	},
+}
+
+func init() {
+	remoteBranchesCmd.Flags().StringSliceVar(&authorPatterns, "author", nil, "Only consider branches whose last commit author name or email matches (globs like *@example.com)")
+}
+
+// remoteBranchCandidate is a remote branch offered for deletion
+type remoteBranchCandidate struct {
+	branch git.RemoteBranch
+	reason string
+	code   string // merged or stale, the reason in events
+}
+
+// formatRemoteBranchCandidate renders a remote branch for listings and the selector
+func formatRemoteBranchCandidate(c remoteBranchCandidate, now time.Time) string {
+	days := int(now.Sub(c.branch.CommitDate).Hours() / 24)
+	return fmt.Sprintf("%s  %s  (%d days ago, %s <%s>)",
+		c.branch.ShortName(),
+		c.reason,
+		days,
+		c.branch.AuthorName,
+		c.branch.AuthorEmail)
+}
+
+// findRemoteBranchCandidates returns the remote branches merged into a target
+// or older than minAge, limited to the configured authors
+func findRemoteBranchCandidates(remote string, targets []string, minAge time.Duration, now time.Time) []remoteBranchCandidate {
+	branches, err := repo.ListRemoteBranches(remote)
+	if err != nil {
+		fatalf("Failed to list branches of %s: %v", remote, err)
+	}
+
+	targetSet := make(map[string]bool)
+	mergedInto := make(map[string]string)
+	for _, target := range targets {
+		targetSet[target] = true
+		merged, err := repo.GetMergedRemoteBranches(remote, repo.RemoteTargetRef(remote, target))
+		if err != nil {
+			fmt.Printf("%s  Warning: Failed to get branches merged into %s: %v\n", tui.EmojiWarning, target, err)
+			continue
+		}
+		for _, branch := range merged {
+			if _, ok := mergedInto[branch]; !ok {
+				mergedInto[branch] = target
+			}
+		}
+	}
+
+	var candidates []remoteBranchCandidate
+	for _, branch := range branches {
+		if targetSet[branch.Name] {
+			continue
+		}
+		if _, protected := git.MatchProtectedPattern(branch.Name, cfg.Protected); protected {
+			continue
+		}
+		if !git.MatchAuthor(branch, authorPatterns) {
+			continue
+		}
+
+		c := remoteBranchCandidate{branch: branch}
+		switch {
+		case mergedInto[branch.Name] != "":
+			c.reason, c.code = "merged into "+mergedInto[branch.Name], "merged"
+		case git.IsOlderThan(branch.CommitDate, minAge, now):
+			c.reason, c.code = "no commits since "+branch.CommitDate.Format("2006-01-02"), "stale"
+		default:
+			continue
+		}
+		candidates = append(candidates, c)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if sortOrder == "age" && !candidates[i].branch.CommitDate.Equal(candidates[j].branch.CommitDate) {
+			return candidates[i].branch.CommitDate.Before(candidates[j].branch.CommitDate)
+		}
+		return candidates[i].branch.Name < candidates[j].branch.Name
+	})
+	return candidates
+}
+
+func runRemoteBranches() {
+	minAge := parseBranchAgeFlags()
+
+	summary := &events.Summary{Command: "remote-branches", DryRun: dryRun}
+	defer finish(summary)
+
+	requireRepository()
+
+	remote := resolveRemote()
+	if remote == "" {
+		fatalf("No remote configured")
+	}
+
+	updateRemoteRefs(os.Stdout, repo.UpdateRemoteRefs)
+	fmt.Printf("📡 Remote: %s\n", remote)
+
+	defaultBranch, err := repo.GetDefaultBranch(remote)
+	if err != nil {
+		fatalf("Failed to get default branch: %v", err)
+	}
+	targets := resolveTargets(defaultBranch)
+	fmt.Printf("🎯 Integration targets: %s\n", strings.Join(targets, ", "))
+	if len(authorPatterns) > 0 {
+		fmt.Printf("👤 Authors: %s\n", strings.Join(authorPatterns, ", "))
+	}
+
+	now := time.Now()
+	candidates := findRemoteBranchCandidates(remote, targets, minAge, now)
+	summary.Candidates = len(candidates)
+	for _, c := range candidates {
+		events.Emit(&events.Candidate{Kind: events.KindRemoteBranch, Name: c.branch.ShortName(), Reason: c.code, Risk: "dangerous", SHA: c.branch.SHA})
+	}
+	if len(candidates) == 0 {
+		fmt.Printf("%s No remote branches to delete.\n", tui.EmojiSuccess)
+		return
+	}
+
+	labels := make([]string, len(candidates))
+	candidatesByLabel := make(map[string]remoteBranchCandidate)
+	for i, c := range candidates {
+		labels[i] = formatRemoteBranchCandidate(c, now)
+		candidatesByLabel[labels[i]] = c
+	}
+
+	fmt.Printf("\n%s Found %d remote branch(es) to delete:\n", tui.EmojiSearch, len(labels))
+	for _, label := range labels {
+		fmt.Printf("   • %s\n", label)
+	}
+
+	// Select branches: use all if -a flag is set, otherwise use interactive fzf
+	var selected []string
+	if selectAll {
+		selected = labels
+	} else {
+		selected, err = selectItems(labels, "Select remote branches to delete > ")
+		if err != nil {
+			if err.Error() == "abort" {
+				fmt.Printf("\n%s Selection cancelled\n", tui.EmojiError)
+				emitCancelled(events.KindRemoteBranch)
+				summary.Cancelled = true
+				return
+			}
+			fatalf("Failed to select branches: %v", err)
+		}
+	}
+
+	if len(selected) == 0 {
+		fmt.Printf("\n%s No remote branches selected for deletion\n", tui.EmojiSuccess)
+		return
+	}
+
+	chosen := make([]git.RemoteBranch, len(selected))
+	names := make([]string, len(selected))
+	for i, label := range selected {
+		chosen[i] = candidatesByLabel[label].branch
+		names[i] = chosen[i].ShortName()
+	}
+	emitSelection(events.KindRemoteBranch, names)
+	summary.Selected = len(names)
+
+	// Deleting from the remote affects everyone, so always require typing DELETE (even with -f)
+	if !confirmDangerousOperation(names, "remote branch(es)") {
+		fmt.Printf("%s Deletion of remote branches cancelled\n", tui.EmojiError)
+		summary.Cancelled = true
+		return
+	}
+
+	// In dry-run mode, show what would have been executed and stop here
+	if dryRun {
+		var operations, branches []string
+		leases := make(map[string]string)
+		for _, branch := range chosen {
+			operations = append(operations, plannedBackup(branch.Ref()))
+			branches = append(branches, branch.Name)
+			leases[branch.Name] = branch.SHA
+		}
+		sort.Strings(branches)
+		printDryRun(append(operations, plannedRemoteBranchDeletion(remote, branches, leases)))
+		return
+	}
+
+	// The push is not atomic: each branch is deleted or kept on its own
+	err = repo.DeleteRemoteBranches(remote, chosen)
+	var kept *git.RemoteDeletionError
+	errors.As(err, &kept)
+	deleted := 0
+	for _, branch := range chosen {
+		branchErr := err
+		if kept != nil {
+			branchErr = kept.Kept(branch.Name)
+		}
+		emitDeletion(summary, events.Deletion{Kind: events.KindRemoteBranch, Name: branch.ShortName(), Remote: remote}, branchErr)
+		if branchErr != nil {
+			fmt.Printf("%s Failed to delete remote branch %s: %v\n", tui.EmojiError, branch.ShortName(), branchErr)
+			continue
+		}
+		fmt.Printf("%s Deleted remote branch: %s\n", tui.EmojiSuccess, branch.ShortName())
+		deleted++
+	}
+	if deleted == 0 {
+		return
+	}
+
+	fmt.Printf("\n%s Successfully deleted %d remote branch(es) from %s\n", tui.EmojiCelebrate, deleted, remote)
+	fmt.Println("   Backups of the deleted branches are listed by 'git-gone trash list'.")
+}
@@ -5,188 +5,241 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"os/exec"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
+
+	"git-gone/internal/events"
+	"git-gone/internal/git"
 )
 
 // BranchAnalysis contains detailed information about a single branch
 type BranchAnalysis struct {
 	Name         string `json:"name"`
-	Status       string `json:"status"`        // safe_to_delete, local_only, unmerged, protected
-	DeleteMethod string `json:"delete_method"` // merged, gone_remote, force, or empty for protected
-	Reason       string `json:"reason"`        // Human-readable explanation
-	RemoteStatus string `json:"remote_status"` // exists, gone, local_only
-	LastCommit   string `json:"last_commit"`   // Date of last commit
+	Status       string `json:"status"`                 // safe_to_delete, local_only, unmerged, protected
+	DeleteMethod string `json:"delete_method"`          // merged, gone_remote, rebase_merged, squash_merged, force, or empty for protected
+	Reason       string `json:"reason"`                 // Human-readable explanation
+	RemoteStatus string `json:"remote_status"`          // exists, gone, local_only
+	LastCommit   string `json:"last_commit"`            // Date of last commit
+	ProtectedBy  string `json:"protected_by,omitempty"` // Rule protecting the branch: default_branch, integration_target, current_branch, worktree:<path>, pattern:<glob>
+	MergedInto   string `json:"merged_into,omitempty"`  // Integration target that absorbed the branch
+	SHA          string `json:"sha"`                    // Commit the branch points to
+
+	// Committer date of the last commit and its age in whole days
+	LastCommitAt time.Time `json:"last_commit_at"`
+	AgeDays      int       `json:"age_days"`
+	Stale        bool      `json:"stale,omitempty"` // Last commit older than the --older-than threshold
+
+	// Commits ahead of and behind the default branch and the upstream branch
+	Ahead          int    `json:"ahead"`
+	Behind         int    `json:"behind"`
+	Upstream       string `json:"upstream,omitempty"` // e.g. origin/feature-x; empty if none or gone
+	UpstreamAhead  int    `json:"upstream_ahead,omitempty"`
+	UpstreamBehind int    `json:"upstream_behind,omitempty"`
+
+	// Patch-equivalence against the default branch, set for branches that
+	// are not merged by ancestry
+	UniqueCommits     int `json:"unique_commits,omitempty"`     // Commits with no equivalent on the default branch
+	EquivalentCommits int `json:"equivalent_commits,omitempty"` // Commits already applied to the default branch
 }
 
 // ReportSummary contains aggregated counts for the report
 type ReportSummary struct {
-	SafeCount       int `json:"safe_to_delete_count"`
-	LocalOnlyCount  int `json:"local_only_count"`
-	UnmergedCount   int `json:"unmerged_count"`
-	ProtectedCount  int `json:"protected_count"`
-	MergedCount     int `json:"merged_count"`
-	GoneRemoteCount int `json:"gone_remote_count"`
+	SafeCount         int `json:"safe_to_delete_count"`
+	LocalOnlyCount    int `json:"local_only_count"`
+	UnmergedCount     int `json:"unmerged_count"`
+	ProtectedCount    int `json:"protected_count"`
+	MergedCount       int `json:"merged_count"`
+	GoneRemoteCount   int `json:"gone_remote_count"`
+	SquashMergedCount int `json:"squash_merged_count"`
+	RebaseMergedCount int `json:"rebase_merged_count"`
+	StaleCount        int `json:"stale_count"`
 }
 
 // AnalysisReport contains the complete branch analysis
 type AnalysisReport struct {
 	Repository    string           `json:"repository"`
 	AnalysisDate  string           `json:"analysis_date"`
+	Remote        string           `json:"remote,omitempty"`
 	DefaultBranch string           `json:"default_branch"`
+	Targets       []string         `json:"targets"`
 	CurrentBranch string           `json:"current_branch"`
 	TotalBranches int              `json:"total_branches"`
 	SafeToDelete  []BranchAnalysis `json:"safe_to_delete"`
 	LocalOnly     []BranchAnalysis `json:"local_only"`
 	Unmerged      []BranchAnalysis `json:"unmerged"`
 	Protected     []BranchAnalysis `json:"protected"`
-	Summary       ReportSummary    `json:"summary"`
+	// Stale lists unprotected branches whose last commit is older than
+	// StaleThreshold, merged or not. They also appear in their own category.
+	StaleThreshold string           `json:"stale_threshold,omitempty"`
+	Stale          []BranchAnalysis `json:"stale"`
+	Summary        ReportSummary    `json:"summary"`
 }
 
-// getBranchRemoteStatus determines if a branch has a remote: exists, gone, or local_only
-func getBranchRemoteStatus(branch string) string {
-	// Check if branch has an upstream configured
-	cmd := exec.Command("git", "config", "--get", fmt.Sprintf("branch.%s.remote", branch))
-	cmd.Env = append(os.Environ(), "LC_ALL=C")
-	output, err := cmd.Output()
-
-	if err != nil || strings.TrimSpace(string(output)) == "" {
-		// No upstream configured - local only branch
-		return "local_only"
-	}
-
-	// Has upstream, check if it's gone
-	cmd = exec.Command("git", "branch", "--format", "%(upstream:track)", "--list", branch)
-	cmd.Env = append(os.Environ(), "LC_ALL=C")
-	output, err = cmd.Output()
-
-	if err != nil {
+// remoteStatusLabel returns the report name of a branch tracking status
+func remoteStatusLabel(status git.RemoteStatus) string {
+	switch status {
+	case git.TrackingGone:
+		return "gone"
+	case git.TrackingActive:
+		return "exists"
+	default:
 		return "local_only"
 	}
+}
 
-	trackStatus := strings.TrimSpace(string(output))
-	if strings.Contains(trackStatus, "[gone]") {
-		return "gone"
+// protectionReason returns the human-readable explanation of a protection rule
+func protectionReason(rule string) string {
+	switch {
+	case rule == "default_branch":
+		return "Default branch"
+	case rule == "integration_target":
+		return "Integration target"
+	case rule == "current_branch":
+		return "Currently checked out"
+	case strings.HasPrefix(rule, "worktree:"):
+		return "Checked out in worktree " + strings.TrimPrefix(rule, "worktree:")
+	case strings.HasPrefix(rule, "pattern:"):
+		return "Matches protected pattern " + strings.TrimPrefix(rule, "pattern:")
+	default:
+		return rule
 	}
-
-	return "exists"
 }
 
-// getBranchLastCommit returns the date of the last commit on a branch
-func getBranchLastCommit(branch string) string {
-	cmd := exec.Command("git", "log", "-1", "--format=%ci", branch)
-	cmd.Env = append(os.Environ(), "LC_ALL=C")
-	output, err := cmd.Output()
-
-	if err != nil {
-		return "unknown"
-	}
+// analysisScope is the repository analyzed by the reports and the settings
+// that apply to it
+type analysisScope struct {
+	repo           *git.Repository
+	remote         string   // Remote to compare against; empty if there is none
+	targetPatterns []string // Additional integration targets
+	protected      []string // Protected branch patterns
+	protectedTags  []string // Protected tag patterns
+}
 
-	// Parse and format the date nicely
-	dateStr := strings.TrimSpace(string(output))
-	if len(dateStr) >= 10 {
-		return dateStr[:10] // Return just YYYY-MM-DD
+// currentScope returns the analysis scope of the current repository, with the
+// settings of the command line and its config
+func currentScope() analysisScope {
+	return analysisScope{
+		repo:           repo,
+		remote:         resolveRemote(),
+		targetPatterns: targetPatterns,
+		protected:      cfg.Protected,
+		protectedTags:  cfg.ProtectedTags,
 	}
-
-	return dateStr
 }
 
-// getRepositoryPath returns the root path of the current git repository
-func getRepositoryPath() string {
-	cmd := exec.Command("git", "rev-parse", "--show-toplevel")
-	cmd.Env = append(os.Environ(), "LC_ALL=C")
-	output, err := cmd.Output()
-
+// getRepositoryPath returns the root path of a git repository
+func getRepositoryPath(r *git.Repository) string {
+	root, err := r.GetRepositoryRoot()
 	if err != nil {
 		return "unknown"
 	}
-
-	return strings.TrimSpace(string(output))
+	return root
 }
 
-// analyzeBranches collects and classifies all branches in the repository
-func analyzeBranches(includeUnmerged bool) *AnalysisReport {
+// analyzeBranches collects and classifies all branches in the repository of
+// scope. If the branches cannot be read, the report lists none of them and
+// the error is returned with it.
+//
+// Branches whose last commit is older than staleAfter are also listed in the
+// stale section; a zero duration disables stale detection. With sortByAge,
+// every section lists the oldest branches first.
+func analyzeBranches(scope analysisScope, includeUnmerged bool, staleAfter time.Duration, sortByAge bool) (*AnalysisReport, error) {
+	r := scope.repo
+	now := time.Now()
 	report := &AnalysisReport{
-		Repository:   getRepositoryPath(),
-		AnalysisDate: time.Now().Format("2006-01-02 15:04:05"),
+		Repository:   getRepositoryPath(r),
+		AnalysisDate: now.Format("2006-01-02 15:04:05"),
 		SafeToDelete: []BranchAnalysis{},
 		LocalOnly:    []BranchAnalysis{},
 		Unmerged:     []BranchAnalysis{},
 		Protected:    []BranchAnalysis{},
+		Stale:        []BranchAnalysis{},
+	}
+	if staleAfter > 0 {
+		report.StaleThreshold = olderThan
 	}
 
-	// Get default branch
-	defaultBranch, err := getDefaultBranch()
+	// Get default branch from the resolved remote
+	report.Remote = scope.remote
+	defaultBranch, err := r.GetDefaultBranch(report.Remote)
 	if err != nil {
 		defaultBranch = "main"
 	}
 	report.DefaultBranch = defaultBranch
 
 	// Get current branch
-	currentBranch, err := getCurrentBranch()
+	currentBranch, err := r.GetCurrentBranch()
 	if err != nil {
 		currentBranch = ""
 	}
 	report.CurrentBranch = currentBranch
 
 	// Get all local branches
-	allBranches, err := getAllLocalBranches()
+	allBranches, err := r.GetAllLocalBranches()
 	if err != nil {
-		return report
+		report.Targets = []string{defaultBranch}
+		return report, fmt.Errorf("failed to list branches: %w", err)
 	}
 	report.TotalBranches = len(allBranches)
 
-	// Get gone branches (remote deleted)
-	goneBranches, _ := getGoneBranches()
-	goneBranchesMap := make(map[string]bool)
-	for _, b := range goneBranches {
-		goneBranchesMap[strings.TrimSpace(b)] = true
+	// Resolve integration targets (default branch first)
+	targets := git.ResolveTargets(defaultBranch, scope.targetPatterns, allBranches)
+	report.Targets = targets
+
+	// Read every branch at once and classify it; failed checks are skipped
+	snapshot, err := r.LoadBranchSnapshot(git.BranchContext{
+		DefaultBranch: defaultBranch,
+		CurrentBranch: currentBranch,
+		Targets:       targets,
+		Protected:     scope.protected,
+		Worktrees:     r.GetWorktreeBranches(),
+	})
+	if snapshot == nil {
+		return report, err
 	}
 
-	// Get merged branches
-	mergedBranches, _ := getMergedBranches(defaultBranch)
-	mergedBranchesMap := make(map[string]bool)
-	for _, b := range mergedBranches {
-		mergedBranchesMap[strings.TrimSpace(b)] = true
-	}
-
-	// Classify each branch
-	for _, branch := range allBranches {
-		branch = strings.TrimSpace(branch)
-		if branch == "" {
-			continue
-		}
-
+	for _, c := range snapshot.Classify() {
+		branch := c.Name
+		ref, _ := snapshot.Ref(branch)
 		analysis := BranchAnalysis{
 			Name:         branch,
-			RemoteStatus: getBranchRemoteStatus(branch),
-			LastCommit:   getBranchLastCommit(branch),
+			RemoteStatus: remoteStatusLabel(ref.RemoteStatus()),
+			LastCommit:   "unknown",
+			MergedInto:   c.MergedInto,
+			SHA:          ref.SHA,
 		}
-
-		// Check if protected (default or current branch)
-		if branch == defaultBranch {
-			analysis.Status = "protected"
-			analysis.DeleteMethod = ""
-			analysis.Reason = "Default branch"
-			report.Protected = append(report.Protected, analysis)
-			report.Summary.ProtectedCount++
-			continue
+		if !ref.CommitDate.IsZero() {
+			analysis.LastCommitAt = ref.CommitDate
+			analysis.LastCommit = ref.CommitDate.Format("2006-01-02")
+			analysis.AgeDays = int(now.Sub(ref.CommitDate).Hours() / 24)
+		}
+		if divergence, ok := snapshot.Divergence(branch, defaultBranch); ok {
+			analysis.Ahead = divergence.Ahead
+			analysis.Behind = divergence.Behind
+		}
+		if ref.HasUpstream() {
+			analysis.Upstream = ref.Upstream
+			analysis.UpstreamAhead = ref.UpstreamDivergence.Ahead
+			analysis.UpstreamBehind = ref.UpstreamDivergence.Behind
 		}
 
-		if branch == currentBranch {
+		if c.IsProtected() {
 			analysis.Status = "protected"
-			analysis.DeleteMethod = ""
-			analysis.Reason = "Currently checked out"
+			analysis.Reason = protectionReason(c.ProtectedBy)
+			analysis.ProtectedBy = c.ProtectedBy
 			report.Protected = append(report.Protected, analysis)
 			report.Summary.ProtectedCount++
 			continue
 		}
 
-		// Check if gone (remote deleted)
-		if goneBranchesMap[branch] {
+		analysis.Stale = git.IsOlderThan(analysis.LastCommitAt, staleAfter, now)
+		reason := c.Candidate.Reason
+
+		if reason == git.ReasonGoneRemote {
 			analysis.Status = "safe_to_delete"
-			analysis.DeleteMethod = "gone_remote"
+			analysis.DeleteMethod = reason.String()
 			analysis.Reason = "Remote tracking branch deleted"
 			analysis.RemoteStatus = "gone"
 			report.SafeToDelete = append(report.SafeToDelete, analysis)
@@ -195,39 +248,91 @@ func analyzeBranches(includeUnmerged bool) *AnalysisReport {
 			continue
 		}
 
-		// Check if merged
-		if mergedBranchesMap[branch] {
+		// Commit equivalence is reported against the default branch for
+		// branches that are not merged by ancestry
+		var cherry git.CherryResult
+		if reason != git.ReasonMerged {
+			var ok bool
+			if cherry, ok = snapshot.Cherry(branch, defaultBranch); ok {
+				analysis.UniqueCommits = cherry.Unique
+				analysis.EquivalentCommits = cherry.Equivalent
+			}
+		}
+
+		if reason != git.ReasonUnmerged {
+			var merged, safeReason string
+			switch reason {
+			case git.ReasonRebaseMerged:
+				merged, safeReason = "Rebase-merged", "All commits rebase-merged into "+c.MergedInto
+				report.Summary.RebaseMergedCount++
+			case git.ReasonSquashMerged:
+				merged, safeReason = "Squash-merged", "Squash-merged into "+c.MergedInto
+				report.Summary.SquashMergedCount++
+			default:
+				merged, safeReason = "Merged", "Merged into "+c.MergedInto
+				report.Summary.MergedCount++
+			}
+
+			analysis.DeleteMethod = reason.String()
 			if analysis.RemoteStatus == "local_only" {
 				// Merged but never pushed - separate category
 				analysis.Status = "local_only"
-				analysis.DeleteMethod = "merged"
-				analysis.Reason = "Merged but never pushed to remote (local-only)"
+				analysis.Reason = merged + " but never pushed to remote (local-only)"
 				report.LocalOnly = append(report.LocalOnly, analysis)
 				report.Summary.LocalOnlyCount++
-				report.Summary.MergedCount++
 			} else {
-				// Merged with remote
 				analysis.Status = "safe_to_delete"
-				analysis.DeleteMethod = "merged"
-				analysis.Reason = fmt.Sprintf("Merged into %s", defaultBranch)
+				analysis.Reason = safeReason
 				report.SafeToDelete = append(report.SafeToDelete, analysis)
 				report.Summary.SafeCount++
-				report.Summary.MergedCount++
 			}
 			continue
 		}
 
 		// Not merged - only include if --unmerged flag is set
+		analysis.Status = "unmerged"
+		analysis.DeleteMethod = "force"
+		analysis.Reason = "Not merged, requires force delete"
+		if cherry.PartiallyAbsorbed() {
+			analysis.Reason = fmt.Sprintf("Partially merged, %d of %d commits still unique, requires force delete",
+				cherry.Unique, cherry.Total())
+		}
 		if includeUnmerged {
-			analysis.Status = "unmerged"
-			analysis.DeleteMethod = "force"
-			analysis.Reason = "Not merged, requires force delete"
 			report.Unmerged = append(report.Unmerged, analysis)
 			report.Summary.UnmergedCount++
+		} else if analysis.Stale {
+			// Stale unmerged branches are reported even without --unmerged
+			report.Stale = append(report.Stale, analysis)
+		}
+	}
+
+	// Collect stale branches from every deletable category
+	for _, group := range [][]BranchAnalysis{report.SafeToDelete, report.LocalOnly, report.Unmerged} {
+		for _, branch := range group {
+			if branch.Stale {
+				report.Stale = append(report.Stale, branch)
+			}
 		}
 	}
+	report.Summary.StaleCount = len(report.Stale)
 
-	return report
+	sortBranchAnalyses(report.Stale, true)
+	for _, group := range [][]BranchAnalysis{report.SafeToDelete, report.LocalOnly, report.Unmerged, report.Protected} {
+		sortBranchAnalyses(group, sortByAge)
+	}
+
+	return report, nil
+}
+
+// sortBranchAnalyses sorts branches by name, or by last commit date (oldest
+// first) when byAge is set
+func sortBranchAnalyses(branches []BranchAnalysis, byAge bool) {
+	sort.SliceStable(branches, func(i, j int) bool {
+		if byAge && !branches[i].LastCommitAt.Equal(branches[j].LastCommitAt) {
+			return branches[i].LastCommitAt.Before(branches[j].LastCommitAt)
+		}
+		return branches[i].Name < branches[j].Name
+	})
 }
 
 // generateTextReport creates a human-readable text report
@@ -239,7 +344,13 @@ func generateTextReport(report *AnalysisReport) string {
 	sb.WriteString("============================================================\n")
 	sb.WriteString(fmt.Sprintf("Repository: %s\n", report.Repository))
 	sb.WriteString(fmt.Sprintf("Date: %s\n", report.AnalysisDate))
+	if report.Remote != "" && report.Remote != git.DefaultRemote {
+		sb.WriteString(fmt.Sprintf("Remote: %s\n", report.Remote))
+	}
 	sb.WriteString(fmt.Sprintf("Default Branch: %s\n", report.DefaultBranch))
+	if len(report.Targets) > 1 {
+		sb.WriteString(fmt.Sprintf("Integration Targets: %s\n", strings.Join(report.Targets, ", ")))
+	}
 	sb.WriteString(fmt.Sprintf("Current Branch: %s\n", report.CurrentBranch))
 	sb.WriteString("\n")
 
@@ -252,6 +363,7 @@ func generateTextReport(report *AnalysisReport) string {
 			sb.WriteString(fmt.Sprintf("  * %s\n", branch.Name))
 			sb.WriteString(fmt.Sprintf("    Method: %s | Reason: %s\n", branch.DeleteMethod, branch.Reason))
 			sb.WriteString(fmt.Sprintf("    Remote: %s | Last commit: %s\n", branch.RemoteStatus, branch.LastCommit))
+			sb.WriteString(formatDivergenceLine(branch, report.DefaultBranch))
 			sb.WriteString("\n")
 		}
 	}
@@ -265,6 +377,7 @@ func generateTextReport(report *AnalysisReport) string {
 			sb.WriteString(fmt.Sprintf("  * %s\n", branch.Name))
 			sb.WriteString(fmt.Sprintf("    Method: %s | Reason: %s\n", branch.DeleteMethod, branch.Reason))
 			sb.WriteString(fmt.Sprintf("    Remote: %s | Last commit: %s\n", branch.RemoteStatus, branch.LastCommit))
+			sb.WriteString(formatDivergenceLine(branch, report.DefaultBranch))
 			sb.WriteString("\n")
 		}
 	}
@@ -278,6 +391,20 @@ func generateTextReport(report *AnalysisReport) string {
 			sb.WriteString(fmt.Sprintf("  * %s\n", branch.Name))
 			sb.WriteString(fmt.Sprintf("    Method: %s | Reason: %s\n", branch.DeleteMethod, branch.Reason))
 			sb.WriteString(fmt.Sprintf("    Remote: %s | Last commit: %s\n", branch.RemoteStatus, branch.LastCommit))
+			sb.WriteString(formatDivergenceLine(branch, report.DefaultBranch))
+			sb.WriteString(fmt.Sprintf("    Unique commits: %d | Already merged: %d\n", branch.UniqueCommits, branch.EquivalentCommits))
+			sb.WriteString("\n")
+		}
+	}
+
+	// Stale
+	if len(report.Stale) > 0 {
+		sb.WriteString("------------------------------------------------------------\n")
+		sb.WriteString(fmt.Sprintf("STALE (%d branches) - No commits in the last %s\n", len(report.Stale), report.StaleThreshold))
+		sb.WriteString("------------------------------------------------------------\n")
+		for _, branch := range report.Stale {
+			sb.WriteString(fmt.Sprintf("  * %s\n", branch.Name))
+			sb.WriteString(fmt.Sprintf("    Status: %s | Last commit: %s (%d days ago)\n", branch.Status, branch.LastCommit, branch.AgeDays))
 			sb.WriteString("\n")
 		}
 	}
@@ -289,7 +416,7 @@ func generateTextReport(report *AnalysisReport) string {
 		sb.WriteString("------------------------------------------------------------\n")
 		for _, branch := range report.Protected {
 			sb.WriteString(fmt.Sprintf("  * %s\n", branch.Name))
-			sb.WriteString(fmt.Sprintf("    Reason: %s\n", branch.Reason))
+			sb.WriteString(fmt.Sprintf("    Reason: %s | Rule: %s\n", branch.Reason, branch.ProtectedBy))
 			sb.WriteString("\n")
 		}
 	}
@@ -301,13 +428,25 @@ func generateTextReport(report *AnalysisReport) string {
 		report.Summary.LocalOnlyCount,
 		report.Summary.UnmergedCount,
 		report.Summary.ProtectedCount))
+	if report.StaleThreshold != "" {
+		sb.WriteString(fmt.Sprintf("         %d stale (older than %s)\n", report.Summary.StaleCount, report.StaleThreshold))
+	}
 	sb.WriteString("============================================================\n")
 
 	return sb.String()
 }
 
+// formatDivergenceLine renders the ahead/behind counts of a branch for the text report
+func formatDivergenceLine(branch BranchAnalysis, defaultBranch string) string {
+	line := fmt.Sprintf("    Ahead/behind %s: %d/%d", defaultBranch, branch.Ahead, branch.Behind)
+	if branch.Upstream != "" {
+		line += fmt.Sprintf(" | %s: %d/%d", branch.Upstream, branch.UpstreamAhead, branch.UpstreamBehind)
+	}
+	return line + "\n"
+}
+
 // generateJSONReport creates a JSON formatted report
-func generateJSONReport(report *AnalysisReport) string {
+func generateJSONReport(report renderableReport) string {
 	output, err := json.MarshalIndent(report, "", "  ")
 	if err != nil {
 		return fmt.Sprintf(`{"error": "%s"}`, err.Error())
@@ -316,44 +455,114 @@ func generateJSONReport(report *AnalysisReport) string {
 }
 
 // generateCSVReport creates a CSV formatted report
-func generateCSVReport(report *AnalysisReport) string {
+func generateCSVReport(report renderableReport) string {
 	var sb strings.Builder
 	writer := csv.NewWriter(&sb)
 
-	// Header
-	_ = writer.Write([]string{"Name", "Status", "Delete Method", "Reason", "Remote Status", "Last Commit"})
+	_ = writer.Write(report.csvHeader())
+	for _, row := range report.csvRows() {
+		_ = writer.Write(row)
+	}
+
+	writer.Flush()
+	return sb.String()
+}
 
+// csvHeader returns the CSV column names of the branch report
+func (report *AnalysisReport) csvHeader() []string {
+	return []string{"Name", "Status", "Delete Method", "Reason", "Remote Status", "Last Commit", "Unique Commits", "Equivalent Commits", "Protected By", "Merged Into", "Age Days", "Stale", "Ahead", "Behind", "Upstream", "Upstream Ahead", "Upstream Behind"}
+}
+
+// csvRows returns one CSV row per branch
+func (report *AnalysisReport) csvRows() [][]string {
 	// All branches
 	allBranches := append(report.SafeToDelete, report.LocalOnly...)
 	allBranches = append(allBranches, report.Unmerged...)
 	allBranches = append(allBranches, report.Protected...)
 
+	var rows [][]string
 	for _, branch := range allBranches {
-		_ = writer.Write([]string{
+		rows = append(rows, []string{
 			branch.Name,
 			branch.Status,
 			branch.DeleteMethod,
 			branch.Reason,
 			branch.RemoteStatus,
 			branch.LastCommit,
+			strconv.Itoa(branch.UniqueCommits),
+			strconv.Itoa(branch.EquivalentCommits),
+			branch.ProtectedBy,
+			branch.MergedInto,
+			strconv.Itoa(branch.AgeDays),
+			strconv.FormatBool(branch.Stale),
+			strconv.Itoa(branch.Ahead),
+			strconv.Itoa(branch.Behind),
+			branch.Upstream,
+			strconv.Itoa(branch.UpstreamAhead),
+			strconv.Itoa(branch.UpstreamBehind),
 		})
 	}
+	return rows
+}
 
-	writer.Flush()
-	return sb.String()
+// renderText returns the human-readable branch report
+func (report *AnalysisReport) renderText() string {
+	return generateTextReport(report)
+}
+
+// deletableBranches returns the branches of a report that the cleanup would
+// offer: safe, local-only and (with -u) unmerged branches, only stale ones
+// when --older-than is set, sorted by name
+func deletableBranches(report *AnalysisReport) []BranchAnalysis {
+	var candidates []BranchAnalysis
+	for _, group := range [][]BranchAnalysis{report.SafeToDelete, report.LocalOnly, report.Unmerged} {
+		for _, branch := range group {
+			if olderThan == "" || branch.Stale {
+				candidates = append(candidates, branch)
+			}
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].Name < candidates[j].Name
+	})
+	return candidates
+}
+
+// emitEvents emits a candidate event for every deletable branch and a summary
+func (report *AnalysisReport) emitEvents() {
+	summary := &events.Summary{Command: "report"}
+	for _, list := range [][]BranchAnalysis{report.SafeToDelete, report.LocalOnly, report.Unmerged} {
+		for _, branch := range list {
+			emitCandidate(analysisCandidate(branch))
+			summary.Candidates++
+		}
+	}
+	events.Emit(summary)
+}
+
+// renderableReport is a report that outputReport can render as text, JSON,
+// CSV or an event stream
+type renderableReport interface {
+	renderText() string
+	csvHeader() []string
+	csvRows() [][]string
+	emitEvents()
 }
 
 // outputReport writes the report to stdout or a file
-func outputReport(report *AnalysisReport, format string, filePath string) {
+func outputReport(report renderableReport, format string, filePath string) {
 	var output string
 
 	switch format {
+	case "ndjson":
+		report.emitEvents()
+		return
 	case "json":
 		output = generateJSONReport(report)
 	case "csv":
 		output = generateCSVReport(report)
 	default:
-		output = generateTextReport(report)
+		output = report.renderText()
 	}
 
 	if filePath != "" {
@@ -11,6 +11,7 @@ import (
 var (
 	reportOutputFormat string
 	reportOutputFile   string
+	reportCheck        bool
 )
 
 var reportCmd = &cobra.Command{
@@ -27,11 +28,16 @@ The report includes:
   - Local-only: Branches that were never pushed to remote
   - Unmerged: Branches with unmerged changes (when -u flag is used)
   - Protected: Default branch and currently checked out branch
+  - Stale: Branches with no commits newer than --older-than, merged or not
 
 Output formats available:
   - text: Human-readable formatted report (default)
   - json: Machine-readable JSON format
-  - csv: Spreadsheet-compatible CSV format`,
+  - csv: Spreadsheet-compatible CSV format
+  - ndjson: Candidate and summary events, see --output of the other commands
+
+With --check, the exit code tells whether the cleanup would offer branches:
+3 if there are branches to delete, 0 otherwise. This suits CI jobs and hooks.`,
 	Example: `  # Generate a text report to stdout
   git-gone report
 
@@ -42,31 +48,41 @@ Output formats available:
   git-gone report --file branches-report.txt
 
   # Generate CSV report including unmerged branches
-  git-gone report -u --output csv --file report.csv`,
+  git-gone report -u --output csv --file report.csv
+
+  # List branches without commits in the last 90 days, oldest first
+  git-gone report --older-than 90d --sort age
+
+  # Fail a CI job or hook if merged branches are left behind
+  git-gone report --check > /dev/null`,
 	Run: func(cmd *cobra.Command, args []string) {
 		runReport()
 	},
 }
 
 func init() {
-	reportCmd.Flags().StringVarP(&reportOutputFormat, "output", "o", "text", "Report output format (text, json, csv)")
+	reportCmd.Flags().StringVarP(&reportOutputFormat, "output", "o", "text", "Report output format (text, json, csv, ndjson)")
 	reportCmd.Flags().StringVar(&reportOutputFile, "file", "", "Write report to file instead of stdout")
+	reportCmd.Flags().BoolVar(&reportCheck, "check", false, "Exit with code 3 if there are branches to delete, 0 otherwise")
 	// Note: --unmerged/-u flag is inherited from root command as a persistent flag
 }
 
 func runReport() {
-	// Check if we're in a git repository
-	if err := checkGitRepository(); err != nil {
-		fmt.Println("❌ Not in a git repository")
-		os.Exit(1)
-	}
+	staleAfter := parseBranchAgeFlags()
 
-	fmt.Println("🔄 Updating remote references...")
-	if err := updateRemoteRefs(); err != nil {
-		fmt.Printf("⚠️  Warning: Failed to update remote refs: %v\n", err)
-	}
+	requireRepository()
 
-	fmt.Println("📊 Analyzing branches...")
-	report := analyzeBranches(includeUnmerged)
+	// Progress goes to stderr so JSON and CSV on stdout stay machine-readable
+	updateRemoteRefs(os.Stderr, repo.UpdateRemoteRefsSync)
+
+	fmt.Fprintln(os.Stderr, "📊 Analyzing branches...")
+	report, err := analyzeBranches(currentScope(), includeUnmerged, staleAfter, sortOrder == "age")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Warning: %v\n", err)
+	}
 	outputReport(report, reportOutputFormat, reportOutputFile)
+
+	if reportCheck && len(deletableBranches(report)) > 0 {
+		os.Exit(exitCandidatesFound)
+	}
 }
@@ -0,0 +1,184 @@
+package cmd
+
+import (
+	"fmt"
+
+	"git-gone/internal/events"
+	"git-gone/internal/git"
+	"git-gone/internal/tui"
+
+	"github.com/spf13/cobra"
+)
+
+// Restore command flags
+var (
+	restorePush  bool
+	restoreLimit int
+)
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Restore recently deleted branches",
+	Long: `Restore branches previously deleted by git-gone.
+
+Every branch deletion is recorded in a journal under .git/git-gone/ with the
+branch name, its SHA, its upstream configuration and the remote it was
+deleted from. This command lists recent deletions in the interactive selector
+and recreates the chosen branches at their recorded SHA.
+
+Use --push to also push the restored branches back to the remote they were
+deleted from (or to their upstream remote).
+
+Interactive Controls:
+  ↑/↓         Navigate through the list
+  Tab         Toggle selection
+  Enter       Confirm selection
+  Esc         Cancel operation
+  Type        Filter branches by name`,
+	Example: `  # Choose branches to restore from recent deletions
+  git-gone restore
+
+  # Restore and push back to the remote
+  git-gone restore --push
+
+  # Show more history
+  git-gone restore --limit 100`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runRestore()
+	},
+}
+
+func init() {
+	restoreCmd.Flags().BoolVar(&restorePush, "push", false, "Push restored branches back to the remote they were deleted from")
+	restoreCmd.Flags().IntVar(&restoreLimit, "limit", 20, "Maximum number of recent deletions to list")
+}
+
+func runRestore() {
+	summary := &events.Summary{Command: "restore", DryRun: dryRun}
+	defer finish(summary)
+
+	requireRepository()
+
+	records, err := repo.ReadJournal()
+	if err != nil {
+		fatalf("Failed to read deletion journal: %v", err)
+	}
+
+	if len(records) == 0 {
+		fmt.Printf("%s No recorded deletions to restore.\n", tui.EmojiSuccess)
+		return
+	}
+
+	if restoreLimit > 0 && len(records) > restoreLimit {
+		records = records[:restoreLimit]
+	}
+
+	// Build selector labels, most recent deletion first
+	labels := make([]string, len(records))
+	recordsByLabel := make(map[string]git.DeletionRecord)
+	for i, record := range records {
+		labels[i] = formatDeletionRecord(record)
+		recordsByLabel[labels[i]] = record
+		events.Emit(&events.Candidate{Kind: events.KindBranch, Name: record.Branch, SHA: record.SHA})
+	}
+	summary.Candidates = len(records)
+
+	fmt.Printf("\n%s Found %d recent deletion(s)\n", tui.EmojiSearch, len(records))
+
+	// Select branches: use all if -a flag is set, otherwise use interactive fzf
+	var selected []string
+	if selectAll {
+		selected = labels
+	} else {
+		selected, err = selectItems(labels, "Select branches to restore > ")
+		if err != nil {
+			if err.Error() == "abort" {
+				fmt.Printf("\n%s Selection cancelled\n", tui.EmojiError)
+				emitCancelled(events.KindBranch)
+				summary.Cancelled = true
+				return
+			}
+			fatalf("Failed to select branches: %v", err)
+		}
+	}
+
+	names := make([]string, len(selected))
+	for i, label := range selected {
+		names[i] = recordsByLabel[label].Branch
+	}
+	emitSelection(events.KindBranch, names)
+	summary.Selected = len(names)
+
+	if len(selected) == 0 {
+		fmt.Printf("\n%s No branches selected for restore\n", tui.EmojiSuccess)
+		return
+	}
+
+	// In dry-run mode, show what would have been executed and stop here
+	if dryRun {
+		var operations []string
+		for _, label := range selected {
+			record := recordsByLabel[label]
+			operations = append(operations, plannedBranchRestore(record)...)
+			if remote := restoreRemote(record); restorePush && remote != "" {
+				operations = append(operations, plannedBranchPush(record.Branch, remote))
+			}
+		}
+		printDryRun(operations)
+		return
+	}
+
+	restoredCount := 0
+	for _, label := range selected {
+		record := recordsByLabel[label]
+		if err := repo.RestoreBranch(record); err != nil {
+			fmt.Printf("%s Failed to restore branch %s: %v\n", tui.EmojiError, record.Branch, err)
+			emitRestore(summary, events.Restore{Kind: events.KindBranch, Name: record.Branch}, err)
+			continue
+		}
+		fmt.Printf("%s Restored branch: %s (%s)\n", tui.EmojiSuccess, record.Branch, git.ShortSHA(record.SHA))
+		restoredCount++
+
+		if !restorePush {
+			emitRestore(summary, events.Restore{Kind: events.KindBranch, Name: record.Branch}, nil)
+			continue
+		}
+		remote := restoreRemote(record)
+		if remote == "" {
+			fmt.Printf("%s  No remote recorded for %s, skipping push\n", tui.EmojiWarning, record.Branch)
+			emitRestore(summary, events.Restore{Kind: events.KindBranch, Name: record.Branch}, nil)
+			continue
+		}
+		err := repo.PushBranch(record.Branch, remote)
+		if err != nil {
+			fmt.Printf("%s Failed to push branch %s to %s: %v\n", tui.EmojiError, record.Branch, remote, err)
+		} else {
+			fmt.Printf("%s Pushed branch %s to %s\n", tui.EmojiSuccess, record.Branch, remote)
+		}
+		emitRestore(summary, events.Restore{Kind: events.KindBranch, Name: record.Branch, Remote: remote}, err)
+	}
+
+	fmt.Printf("\n%s Successfully restored %d branch(es)\n", tui.EmojiCelebrate, restoredCount)
+}
+
+// restoreRemote returns the remote a restored branch is pushed back to: the
+// remote it was deleted from, otherwise its upstream remote
+func restoreRemote(record git.DeletionRecord) string {
+	if record.DeletedFromRemote != "" {
+		return record.DeletedFromRemote
+	}
+	return record.Remote
+}
+
+// formatDeletionRecord renders a journal entry for the selector
+func formatDeletionRecord(record git.DeletionRecord) string {
+	where := "local"
+	if record.DeletedFromRemote != "" {
+		where = "local + " + record.DeletedFromRemote
+	}
+	return fmt.Sprintf("%s  %s  deleted %s (%s)",
+		record.Branch,
+		git.ShortSHA(record.SHA),
+		record.DeletedAt.Local().Format("2006-01-02 15:04"),
+		where)
+}
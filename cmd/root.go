@@ -4,6 +4,10 @@ import (
 	"fmt"
 	"os"
 
+	"git-gone/internal/config"
+	"git-gone/internal/git"
+	"git-gone/internal/tui"
+
 	"github.com/spf13/cobra"
 )
 
@@ -19,15 +23,54 @@ var (
 	forceDelete     bool
 	selectAll       bool
 	includeUnmerged bool
+	dryRun          bool
+	atomicDelete    bool
+	targetPatterns  []string
+	remoteName      string
+	verbose         bool
+	trace           bool
+	backend         string
 )
 
+// Branch age flags, shared by the branches, report and remote-branches commands
+var (
+	olderThan string
+	sortOrder string
+)
+
+// cfg holds the settings loaded from config files and git config
+var cfg = &config.Config{}
+
+// repo is the repository in the current directory every command works on
+var repo = git.OpenRepository("", nil)
+
 var rootCmd = &cobra.Command{
 	Use:   "git-gone",
 	Short: "Clean up merged git branches interactively",
 	Long: `git-gone - Clean up merged git branches interactively
 
 git-gone helps you clean up local git branches that have been merged
-or whose remote tracking branches have been deleted.`,
+or whose remote tracking branches have been deleted.
+
+Protected branch patterns and default flags can be set in .git-gone.yaml at
+the repository root, in $XDG_CONFIG_HOME/git-gone/config.yaml, or with
+gitgone.* keys in git config.
+
+Exit codes:
+  0  Every selected item was handled
+  1  Error, or invalid flags
+  2  Nothing to delete or restore
+  3  Branches to delete were found (report --check)
+  4  Some of the selected items could not be handled
+  5  None of the selected items could be handled
+  6  The selection or a confirmation was cancelled
+  7  Not in a git repository`,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		setupRunner()
+		setupOutput(cmd)
+		loadConfig(cmd)
+		setupBackend()
+	},
 	Run: func(cmd *cobra.Command, args []string) {
 		// By default, run the branches command when no subcommand is provided
 		branchesCmd.Run(cmd, args)
@@ -37,15 +80,49 @@ or whose remote tracking branches have been deleted.`,
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		os.Exit(exitError)
+	}
+}
+
+// setupRunner logs git commands to stderr when --verbose or --trace is set
+func setupRunner() {
+	if verbose || trace {
+		repo = git.OpenRepository("", git.NewRecordingRunner(git.ExecRunner{}, os.Stderr, trace))
+	}
+}
+
+// setupBackend selects the backend that reads the repository. The native
+// backend falls back to running git if the repository cannot be opened.
+func setupBackend() {
+	if err := repo.SetBackend(backend); err != nil {
+		if backend != git.BackendNative {
+			fatalf("%v", err)
+		}
+		if repo.CheckGitRepository() == nil {
+			fmt.Fprintf(os.Stderr, "%s  Warning: %v, using git instead\n", tui.EmojiWarning, err)
+		}
 	}
 }
 
 func init() {
 	// Add persistent flags that are available to root and all subcommands
 	rootCmd.PersistentFlags().BoolVarP(&forceDelete, "force", "f", false, "Skip confirmation prompt and delete selected branches immediately")
-	rootCmd.PersistentFlags().BoolVarP(&selectAll, "all", "a", false, "Select all candidate branches without interactive selection (incompatible with -f)")
+	rootCmd.PersistentFlags().BoolVarP(&selectAll, "all", "a", false, "Select all candidate branches without interactive selection (incompatible with -f, except with --output ndjson)")
 	rootCmd.PersistentFlags().BoolVarP(&includeUnmerged, "unmerged", "u", false, "Include unmerged branches in the list (marked with (!), always requires confirmation)")
+	rootCmd.PersistentFlags().StringSliceVar(&targetPatterns, "target", nil, "Additional integration branches (names or globs like release/*) that count for merge detection")
+	rootCmd.PersistentFlags().StringVar(&remoteName, "remote", "", "Remote to compare against (default: origin, or the first configured remote)")
+	rootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "Run discovery, selection and confirmation, then print the git operations instead of executing them")
+	rootCmd.PersistentFlags().BoolVar(&atomicDelete, "atomic", false, "Delete all selected branches in a single transaction: either every branch is deleted or none")
+	rootCmd.PersistentFlags().BoolVar(&verbose, "verbose", false, "Log every git command to stderr")
+	rootCmd.PersistentFlags().StringVar(&backend, "backend", git.BackendExec, "How to read the repository: exec (run git) or native (in-process, faster on repositories with many branches)")
+	rootCmd.PersistentFlags().BoolVar(&trace, "trace", false, "Log every git command with its duration, exit status and output to stderr")
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "text", "Output format: text, or ndjson for a stream of JSON events on stdout")
+
+	// Root runs the branches command by default, so it takes the same local flags
+	for _, c := range []*cobra.Command{rootCmd, branchesCmd, reportCmd, remoteBranchesCmd, scanCmd} {
+		c.Flags().StringVar(&olderThan, "older-than", "", "Only consider branches whose last commit is older than this age (e.g. 90d, 12w)")
+		c.Flags().StringVar(&sortOrder, "sort", "name", "Sort branches by name or by age of the last commit (name, age)")
+	}
 
 	// Add subcommands
 	rootCmd.AddCommand(branchesCmd)
@@ -53,4 +130,10 @@ func init() {
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(selfUpdateCmd)
 	rootCmd.AddCommand(reportCmd)
+	rootCmd.AddCommand(restoreCmd)
+	rootCmd.AddCommand(trashCmd)
+	rootCmd.AddCommand(worktreesCmd)
+	rootCmd.AddCommand(stashesCmd)
+	rootCmd.AddCommand(remoteBranchesCmd)
+	rootCmd.AddCommand(scanCmd)
 }
@@ -0,0 +1,654 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"git-gone/internal/config"
+	"git-gone/internal/events"
+	"git-gone/internal/git"
+	"git-gone/internal/tui"
+
+	"github.com/spf13/cobra"
+)
+
+// Scan command flags
+var (
+	scanJobs    int
+	scanClean   bool
+	scanGrouped bool
+)
+
+// Flags of scan passed on to the git-gone process cleaning up each repository
+var scanCleanFlags = []string{"unmerged", "target", "remote", "backend", "older-than", "sort",
+	"force", "all", "dry-run", "atomic", "verbose", "trace"}
+
+var scanCmd = &cobra.Command{
+	Use:   "scan [dir]",
+	Short: "Analyze and clean up every git repository under a directory",
+	Long: `Analyze and clean up every git repository under a directory.
+
+The directory (default: the current one) is searched recursively for git
+repositories; repositories nested in another one, such as submodules, are
+skipped. Each repository is analyzed as "git-gone report" and "git-gone tags
+report" would, several repositories at a time, and a summary line is printed
+per repository. Every repository uses its own .git-gone.yaml and git config.
+
+With --clean, the branches of each repository with deletable branches are then
+cleaned up one repository at a time, exactly like running git-gone inside it.
+With --clean --grouped, the deletable branches of every repository are offered
+in a single selector, grouped by repository, and confirmed once; with --atomic
+the branches of each repository are deleted in a single transaction. With
+--output ndjson, --clean always works as with --grouped, and needs --all.
+
+Stale tags are only reported; run "git-gone tags" in a repository to delete them.`,
+	Example: `  # Summarize every repository under ~/src
+  git-gone scan ~/src
+
+  # Analyze 4 repositories at a time, including unmerged branches
+  git-gone scan ~/src -j 4 -u
+
+  # Clean up the repositories one after another
+  git-gone scan ~/src --clean
+
+  # Choose branches of every repository in one selector
+  git-gone scan ~/src --clean --grouped`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		root := "."
+		if len(args) > 0 {
+			root = args[0]
+		}
+		runScan(cmd, root)
+	},
+}
+
+func init() {
+	scanCmd.Flags().IntVarP(&scanJobs, "jobs", "j", runtime.NumCPU(), "Number of repositories analyzed at the same time")
+	scanCmd.Flags().BoolVar(&scanClean, "clean", false, "After the summary, clean up the branches of each repository in turn")
+	scanCmd.Flags().BoolVar(&scanGrouped, "grouped", false, "With --clean, choose the branches of every repository in a single selector grouped by repository")
+}
+
+// repoScan is the analysis of one repository found by scan
+type repoScan struct {
+	Path     string
+	Name     string // Path relative to the scanned directory
+	Branches *AnalysisReport
+	Tags     *TagAnalysisReport
+	Err      error
+
+	repo *git.Repository // Opened on Path, with the backend of its config
+}
+
+func runScan(cmd *cobra.Command, root string) {
+	if scanGrouped && !scanClean {
+		fatalf("Option --grouped requires --clean")
+	}
+	if scanClean && selectAll && forceDelete && !events.Enabled() {
+		fatalf("Options -a (--all) and -f (--force) are incompatible")
+	}
+	if scanJobs < 1 {
+		fatalf("Invalid --jobs value %d (must be at least 1)", scanJobs)
+	}
+	staleAfter := parseBranchAgeFlags()
+
+	// Without --clean scan only reports, and exits with exitOK like report
+	summary := &events.Summary{Command: "scan", DryRun: dryRun}
+	defer func() {
+		if scanClean {
+			finish(summary)
+		} else {
+			events.Emit(summary)
+		}
+	}()
+
+	root, err := filepath.Abs(root)
+	if err != nil {
+		fatalf("%v", err)
+	}
+	repos, err := git.FindRepositories(root)
+	if err != nil {
+		fatalf("Failed to search %s: %v", root, err)
+	}
+	if len(repos) == 0 {
+		fmt.Printf("%s No git repositories found under %s\n", tui.EmojiSuccess, root)
+		return
+	}
+
+	jobs := min(scanJobs, len(repos))
+	fmt.Printf("%s Analyzing %d repositories under %s (%d at a time)...\n", tui.EmojiSearch, len(repos), root, jobs)
+	// Every repository is found by its directory, so GIT_DIR and GIT_WORK_TREE
+	// must not send its git commands elsewhere
+	_ = os.Unsetenv("GIT_DIR")
+	_ = os.Unsetenv("GIT_WORK_TREE")
+	scans := scanRepositories(cmd, repos, root, jobs, staleAfter)
+	printScanSummary(scans)
+
+	var deletable []repoScan
+	for _, scan := range scans {
+		if scan.Err != nil {
+			events.Emit(&events.Error{Message: scan.Err.Error(), Repository: scan.Name})
+		}
+		candidates := scanCandidates(scan)
+		for _, branch := range candidates {
+			c := analysisCandidate(branch)
+			events.Emit(&events.Candidate{Kind: events.KindBranch, Name: c.Name, Reason: c.Reason.String(),
+				Risk: riskName(c.RiskLevel), SHA: c.SHA, Repository: scan.Name})
+		}
+		summary.Candidates += len(candidates)
+		if len(candidates) > 0 {
+			deletable = append(deletable, scan)
+		}
+	}
+
+	if !scanClean {
+		return
+	}
+	if len(deletable) == 0 {
+		fmt.Printf("\n%s No branches to delete in any repository\n", tui.EmojiSuccess)
+		return
+	}
+	// The branches processes of a cleanup in turn would report events without
+	// their repository, so the event stream always uses a grouped cleanup
+	if scanGrouped || events.Enabled() {
+		cleanRepositoriesGrouped(deletable, summary)
+	} else {
+		cleanRepositoriesInTurn(deletable, forwardedFlags(cmd, scanCleanFlags...), summary)
+	}
+}
+
+// scanRepositories analyzes repos with a pool of jobs workers and returns the
+// results in the order of repos
+func scanRepositories(cmd *cobra.Command, repos []string, root string, jobs int, staleAfter time.Duration) []repoScan {
+	scans := make([]repoScan, len(repos))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				scans[i] = scanRepository(cmd, repos[i], root, staleAfter)
+			}
+		}()
+	}
+	for i := range repos {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	return scans
+}
+
+// scanRepository runs the branch and tag reports in a repository, as
+// "git-gone report" and "git-gone tags report" would inside it
+func scanRepository(cmd *cobra.Command, path, root string, staleAfter time.Duration) repoScan {
+	scan := repoScan{Path: path, Name: repositoryName(path, root)}
+
+	scope, unmerged, err := openScanScope(cmd, path)
+	if err != nil {
+		scan.Err = err
+		return scan
+	}
+	scan.repo = scope.repo
+	// The fetch prunes remote-tracking refs, so a dry run uses the refs it has
+	if !dryRun {
+		if err := scope.repo.UpdateRemoteRefsSync(); err != nil {
+			fmt.Fprintf(os.Stderr, "%s  Warning: %s: failed to update remote refs: %v\n", tui.EmojiWarning, scan.Name, err)
+		}
+	}
+
+	scan.Branches, scan.Err = analyzeBranches(scope, unmerged, staleAfter, sortOrder == "age")
+	if scan.Err != nil {
+		scan.Branches = nil
+		return scan
+	}
+
+	var remotes []string
+	if scope.remote != "" {
+		remotes = []string{scope.remote}
+	}
+	scan.Tags, scan.Err = analyzeTags(scope, remotes)
+	return scan
+}
+
+// openScanScope opens the repository at path and returns its analysis scope,
+// and whether unmerged branches are included. Like git-gone run inside the
+// repository, every setting not given on the command line comes from the
+// config of the repository.
+func openScanScope(cmd *cobra.Command, path string) (analysisScope, bool, error) {
+	r := git.OpenRepository(path, repo.Runner)
+	conf, err := config.Load(r)
+	if err != nil {
+		return analysisScope{}, false, err
+	}
+	for _, warning := range conf.Warnings {
+		fmt.Fprintf(os.Stderr, "%s  Warning: %s\n", tui.EmojiWarning, warning)
+	}
+
+	name := conf.Backend
+	if flagChanged(cmd, "backend") {
+		name = backend
+	}
+	// The native backend falls back to running git, as in setupBackend
+	if err := r.SetBackend(name); err != nil && name != git.BackendNative {
+		return analysisScope{}, false, err
+	}
+
+	preferred := conf.Remote
+	if flagChanged(cmd, "remote") {
+		preferred = remoteName
+	}
+	remote, err := r.ResolveRemote(preferred)
+	if err != nil {
+		return analysisScope{}, false, err
+	}
+
+	scope := analysisScope{
+		repo:           r,
+		remote:         remote,
+		targetPatterns: conf.Targets,
+		protected:      conf.Protected,
+		protectedTags:  conf.ProtectedTags,
+	}
+	if flagChanged(cmd, "target") {
+		scope.targetPatterns = targetPatterns
+	}
+	unmerged := conf.Defaults.Unmerged != nil && *conf.Defaults.Unmerged
+	if flagChanged(cmd, "unmerged") {
+		unmerged = includeUnmerged
+	}
+	return scope, unmerged, nil
+}
+
+// flagChanged reports whether the flag name of cmd was given on the command line
+func flagChanged(cmd *cobra.Command, name string) bool {
+	flag := cmd.Flags().Lookup(name)
+	return flag != nil && flag.Changed
+}
+
+// repositoryName returns how a repository is shown in the scan output: its
+// path relative to the scanned directory, or its directory name if it is the
+// scanned directory itself
+func repositoryName(path, root string) string {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." {
+		return filepath.Base(path)
+	}
+	return rel
+}
+
+// gitGoneCommand returns a command running this git-gone binary with args in
+// dir. GIT_DIR and GIT_WORK_TREE are dropped so git finds the repository of dir.
+func gitGoneCommand(dir string, args ...string) (*exec.Cmd, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.Command(exe, args...)
+	cmd.Dir = dir
+	for _, env := range os.Environ() {
+		if !strings.HasPrefix(env, "GIT_DIR=") && !strings.HasPrefix(env, "GIT_WORK_TREE=") {
+			cmd.Env = append(cmd.Env, env)
+		}
+	}
+	return cmd, nil
+}
+
+// forwardedFlags returns the given flags of cmd that were set on the command
+// line, in a form another git-gone process accepts
+func forwardedFlags(cmd *cobra.Command, names ...string) []string {
+	var args []string
+	for _, name := range names {
+		flag := cmd.Flags().Lookup(name)
+		if flag == nil || !flag.Changed {
+			continue
+		}
+		if slice, ok := flag.Value.(interface{ GetSlice() []string }); ok {
+			for _, value := range slice.GetSlice() {
+				args = append(args, "--"+name+"="+value)
+			}
+			continue
+		}
+		args = append(args, "--"+name+"="+flag.Value.String())
+	}
+	return args
+}
+
+// scanCandidates returns the branches of a scanned repository that its
+// cleanup would offer
+func scanCandidates(scan repoScan) []BranchAnalysis {
+	if scan.Branches == nil {
+		return nil
+	}
+	return deletableBranches(scan.Branches)
+}
+
+// printScanSummary prints one line per repository and the totals
+func printScanSummary(scans []repoScan) {
+	width := 0
+	for _, scan := range scans {
+		width = max(width, len(scan.Name))
+	}
+
+	var total ReportSummary
+	staleTags, failed := 0, 0
+	fmt.Println()
+	for _, scan := range scans {
+		if scan.Err != nil {
+			fmt.Printf("%s %-*s  %s %v\n", tui.EmojiRepo, width, scan.Name, tui.EmojiError, scan.Err)
+			failed++
+			continue
+		}
+		summary := scan.Branches.Summary
+		line := fmt.Sprintf("%s %-*s  %d safe | %d local-only | %d unmerged | %d stale tags",
+			tui.EmojiRepo, width, scan.Name, summary.SafeCount, summary.LocalOnlyCount, summary.UnmergedCount, scan.Tags.Summary.StaleCount)
+		if scan.Branches.StaleThreshold != "" {
+			line += fmt.Sprintf(" | %d stale", summary.StaleCount)
+		}
+		fmt.Println(line)
+
+		total.SafeCount += summary.SafeCount
+		total.LocalOnlyCount += summary.LocalOnlyCount
+		total.UnmergedCount += summary.UnmergedCount
+		total.StaleCount += summary.StaleCount
+		staleTags += scan.Tags.Summary.StaleCount
+	}
+
+	fmt.Println("============================================================")
+	line := fmt.Sprintf("SUMMARY: %d repositories | %d safe | %d local-only | %d unmerged | %d stale tags",
+		len(scans), total.SafeCount, total.LocalOnlyCount, total.UnmergedCount, staleTags)
+	if olderThan != "" {
+		line += fmt.Sprintf(" | %d stale", total.StaleCount)
+	}
+	fmt.Println(line)
+	if failed > 0 {
+		fmt.Printf("%s  %d repositories could not be analyzed\n", tui.EmojiWarning, failed)
+	}
+}
+
+// cleanRepositoriesInTurn runs the branch cleanup in each repository, one
+// after another, with the terminal attached. The exit code of each cleanup is
+// counted in summary, one repository standing for one item, so scan exits as
+// a single cleanup of every repository would.
+func cleanRepositoriesInTurn(scans []repoScan, flags []string, summary *events.Summary) {
+	cancelled := false
+	for i, scan := range scans {
+		fmt.Printf("\n%s [%d/%d] %s\n", tui.EmojiRepo, i+1, len(scans), scan.Path)
+		cmd, err := gitGoneCommand(scan.Path, append([]string{"branches"}, flags...)...)
+		if err == nil {
+			cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+			err = cmd.Run()
+		}
+
+		code := exitOK
+		if err != nil {
+			code = exitError
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) {
+				code = exitErr.ExitCode()
+			}
+		}
+		switch code {
+		case exitOK:
+			summary.Selected++
+			summary.Succeeded++
+		case exitNothingToDo:
+			// The branches found by the scan are gone by now
+			summary.Candidates -= len(scanCandidates(scan))
+		case exitCancelled:
+			cancelled = true
+		case exitPartialFailure:
+			summary.Selected++
+			summary.Succeeded++
+			summary.Failed++
+		default:
+			fmt.Printf("%s  Warning: cleanup of %s failed: %v\n", tui.EmojiWarning, scan.Name, err)
+			summary.Selected++
+			summary.Failed++
+		}
+	}
+	// Declining one repository is only a cancellation if nothing else was done
+	summary.Cancelled = cancelled && summary.Selected == 0
+}
+
+// scanCandidate is a branch of a scanned repository offered for deletion
+type scanCandidate struct {
+	repo      *repoScan
+	candidate git.DeletionCandidate
+}
+
+// analysisCandidate rebuilds the deletion candidate of a reported branch. The
+// candidate keeps the reported SHA, so the branch is kept if it moved since.
+func analysisCandidate(branch BranchAnalysis) git.DeletionCandidate {
+	reason := git.ReasonUnmerged
+	for _, r := range []git.DeletionReason{git.ReasonMerged, git.ReasonGoneRemote, git.ReasonRebaseMerged, git.ReasonSquashMerged} {
+		if branch.DeleteMethod == r.String() {
+			reason = r
+		}
+	}
+	c := git.NewBranchCandidate(branch.Name, reason)
+	c.SHA = branch.SHA
+	c.MergedInto = branch.MergedInto
+	return c
+}
+
+// cleanRepositoriesGrouped offers the deletable branches of every repository
+// in a single selector and deletes the selected ones repository by repository
+func cleanRepositoriesGrouped(scans []repoScan, summary *events.Summary) {
+	width := 0
+	for _, scan := range scans {
+		width = max(width, len(scan.Name))
+	}
+
+	// Labels are grouped by repository, then sorted by branch name
+	var labels []string
+	byLabel := make(map[string]scanCandidate)
+	for i := range scans {
+		for _, branch := range scanCandidates(scans[i]) {
+			c := analysisCandidate(branch)
+			label := fmt.Sprintf("%-*s  %s", width, scans[i].Name, c.DisplayLabel)
+			labels = append(labels, label)
+			byLabel[label] = scanCandidate{repo: &scans[i], candidate: c}
+		}
+	}
+
+	fmt.Printf("\n%s Found %d deletable branches in %d repositories\n", tui.EmojiSearch, len(labels), len(scans))
+	selected := labels
+	if !selectAll {
+		var err error
+		selected, err = selectItems(labels, "Select branches to delete > ")
+		if err != nil {
+			if err.Error() == "abort" {
+				fmt.Printf("\n%s Selection cancelled\n", tui.EmojiError)
+				emitCancelled(events.KindBranch)
+				summary.Cancelled = true
+				return
+			}
+			fatalf("Failed to select branches: %v", err)
+		}
+	}
+
+	// One selection event per repository, in scan order
+	chosen := make([]scanCandidate, len(selected))
+	for i, label := range selected {
+		chosen[i] = byLabel[label]
+	}
+	for i, c := range groupByRepository(chosen) {
+		if i == 0 || chosen[i-1].repo != c.repo {
+			var names []string
+			for _, other := range chosen[i:] {
+				if other.repo != c.repo {
+					break
+				}
+				names = append(names, other.candidate.Name)
+			}
+			events.Emit(&events.Selection{Kind: events.KindBranch, Selected: names, Repository: c.repo.Name})
+		}
+	}
+	summary.Selected = len(selected)
+
+	if len(selected) == 0 {
+		fmt.Printf("\n%s No branches selected for deletion\n", tui.EmojiSuccess)
+		return
+	}
+
+	// Keep the selection grouped by repository, in scan order
+	var safeSelected, unmergedSelected []scanCandidate
+	for _, label := range selected {
+		if c := byLabel[label]; c.candidate.RiskLevel == git.RiskDangerous {
+			unmergedSelected = append(unmergedSelected, c)
+		} else {
+			safeSelected = append(safeSelected, c)
+		}
+	}
+
+	fmt.Printf("\n%s  The following branches will be deleted:\n", tui.EmojiWarning)
+	var current *repoScan
+	for _, c := range groupByRepository(append(append([]scanCandidate(nil), safeSelected...), unmergedSelected...)) {
+		if c.repo != current {
+			current = c.repo
+			fmt.Printf("%s %s\n", tui.EmojiRepo, current.Name)
+		}
+		if c.candidate.RiskLevel == git.RiskDangerous {
+			fmt.Printf("  • %s (local + remote)\n", c.candidate.DisplayLabel)
+		} else {
+			fmt.Printf("  • %s\n", c.candidate.Name)
+		}
+	}
+
+	if len(safeSelected) > 0 && !forceDelete {
+		if !confirmDeletion("Are you sure you want to delete these branches?") {
+			fmt.Printf("%s Deletion cancelled\n", tui.EmojiError)
+			summary.Cancelled = true
+			return
+		}
+	}
+	if len(unmergedSelected) > 0 {
+		items := make([]string, len(unmergedSelected))
+		for i, c := range unmergedSelected {
+			items[i] = c.repo.Name + ": " + c.candidate.Name + " (will be deleted locally AND from remote)"
+		}
+		if !confirmDangerousOperation(items, "UNMERGED branch(es)") {
+			fmt.Printf("%s Deletion of unmerged branches cancelled\n", tui.EmojiError)
+			if !forceDelete || len(safeSelected) == 0 {
+				summary.Cancelled = true
+				return
+			}
+			unmergedSelected = nil
+		}
+	}
+
+	deleteScanCandidates(groupByRepository(append(safeSelected, unmergedSelected...)), summary)
+}
+
+// groupByRepository orders candidates by repository, keeping their order
+// within each repository
+func groupByRepository(candidates []scanCandidate) []scanCandidate {
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].repo.Path < candidates[j].repo.Path
+	})
+	return candidates
+}
+
+// deleteScanCandidates deletes candidates grouped by repository, working in
+// each repository in turn. With --atomic the branches of each repository are
+// deleted in a single transaction. A repository whose deletion cannot be run
+// is reported and skipped. With --dry-run the git operations are printed
+// instead.
+func deleteScanCandidates(candidates []scanCandidate, summary *events.Summary) {
+	deletedCount := 0
+	repos := make(map[string]bool)
+	var moved []string
+	for start := 0; start < len(candidates); {
+		current := candidates[start].repo
+		end := start + 1
+		for end < len(candidates) && candidates[end].repo == current {
+			end++
+		}
+		batch := make([]git.DeletionCandidate, 0, end-start)
+		for _, c := range candidates[start:end] {
+			batch = append(batch, c.candidate)
+		}
+		start = end
+
+		remote := current.Branches.Remote
+		if dryRun {
+			fmt.Printf("\n%s %s", tui.EmojiRepo, current.Name)
+			printDryRun(plannedScanDeletion(current.repo, batch, remote))
+			continue
+		}
+
+		fmt.Printf("\n%s %s\n", tui.EmojiRepo, current.Name)
+		if atomicDelete {
+			var safe, unmerged []git.DeletionCandidate
+			for _, c := range batch {
+				if c.RiskLevel == git.RiskDangerous {
+					unmerged = append(unmerged, c)
+				} else {
+					safe = append(safe, c)
+				}
+			}
+			deleted, err := deleteBranchesAtomic(current.repo, current.Name, safe, unmerged, remote, summary)
+			if err != nil {
+				fmt.Printf("❌ Skipped %s: %v\n", current.Name, err)
+				for _, c := range batch {
+					emitDeletion(summary, events.Deletion{Kind: events.KindBranch, Name: c.Name, Repository: current.Name}, err)
+				}
+				continue
+			}
+			deletedCount += deleted
+			if deleted > 0 {
+				repos[current.Path] = true
+			}
+			continue
+		}
+
+		for _, c := range batch {
+			record, err := current.repo.DeleteCandidate(c, remote)
+			emitDeletion(summary, events.Deletion{Kind: events.KindBranch, Name: c.Name, Repository: current.Name}, err)
+			if branchMoved(err) {
+				moved = append(moved, current.Name+": "+c.Name)
+			}
+			if reportDeletion(c, record, err) {
+				deletedCount++
+				repos[current.Path] = true
+			}
+		}
+	}
+	if dryRun {
+		return
+	}
+
+	if len(moved) > 0 {
+		fmt.Printf("\n⚠️  %d branch(es) received new commits while you were selecting and were kept: %s\n",
+			len(moved), strings.Join(moved, ", "))
+		fmt.Println("   Run git-gone scan again to review them.")
+	}
+	fmt.Printf("\n🎉 Successfully deleted %d branches in %d repositories\n", deletedCount, len(repos))
+}
+
+// plannedScanDeletion returns the git operations deleting the candidates of
+// one scanned repository would run
+func plannedScanDeletion(r *git.Repository, candidates []git.DeletionCandidate, remote string) []string {
+	if atomicDelete {
+		return plannedAtomicDeletion(r, candidates, remote)
+	}
+	var operations []string
+	for _, c := range candidates {
+		if c.RiskLevel == git.RiskDangerous {
+			operations = append(operations, plannedBranchDeletionWithRemote(r, c.Name, remote)...)
+		} else {
+			operations = append(operations, plannedBranchDeletion(c)...)
+		}
+	}
+	return operations
+}
@@ -83,7 +83,7 @@ func runSelfUpdate() {
 		fmt.Println("\nℹ️  Possible reasons:")
 		fmt.Println("  • No internet connection")
 		fmt.Println("  • Latest release not available for your platform")
-		os.Exit(1)
+		os.Exit(exitError)
 	}
 	defer func() { _ = resp.Body.Close() }()
 
@@ -93,14 +93,13 @@ func runSelfUpdate() {
 			fmt.Println("\nℹ️  No release found for your platform.")
 			fmt.Printf("    Platform: %s/%s\n", runtime.GOOS, runtime.GOARCH)
 		}
-		os.Exit(1)
+		os.Exit(exitError)
 	}
 
 	// Show download progress
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		fmt.Printf("❌ Failed to read update: %v\n", err)
-		os.Exit(1)
+		fatalf("Failed to read update: %v", err)
 	}
 
 	fmt.Printf("✅ Downloaded %d bytes\n", len(body))
@@ -112,8 +111,7 @@ func runSelfUpdate() {
 		if rerr := selfupdate.RollbackError(err); rerr != nil {
 			fmt.Printf("❌ Failed to rollback from bad update: %v\n", rerr)
 		}
-		fmt.Printf("❌ Update failed: %v\n", err)
-		os.Exit(1)
+		fatalf("Update failed: %v", err)
 	}
 
 	fmt.Println("✅ Successfully updated to the latest version!")
@@ -0,0 +1,321 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"git-gone/internal/events"
+	"git-gone/internal/git"
+	"git-gone/internal/tui"
+
+	"github.com/spf13/cobra"
+)
+
+// Stash-specific flags
+var stashOlderThan string
+
+var stashesCmd = &cobra.Command{
+	Use:   "stashes",
+	Short: "List and drop leftover stash entries",
+	Long: `List and drop leftover stash entries.
+
+Stashes are easy to forget. Once the branch they were created on has been
+merged or deleted, they usually only hold changes nobody needs anymore.
+This command shows every stash entry with its age, the branch it was created
+on, and whether that branch still exists or was merged.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Default behavior: show help
+		_ = cmd.Help()
+	},
+}
+
+var stashesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List stash entries with their age and branch status",
+	Example: `  # List all stash entries
+  git-gone stashes list`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runStashesList()
+	},
+}
+
+var stashesCleanCmd = &cobra.Command{
+	Use:   "clean",
+	Short: "Drop stash entries interactively",
+	Long: `Drop stash entries interactively.
+
+This command will:
+  1. Find stashes whose branch was deleted or merged
+  2. Show an interactive selector to choose stashes to drop
+  3. Confirm before dropping (unless --force is used)
+  4. Drop the selected stashes with "git stash drop"
+
+Use --unmerged (-u) to also offer stashes whose branch still exists, and
+--older-than to also offer any stash older than the given age.
+
+Dropped stashes can be recovered with "git stash store <sha>" until
+"git gc" collects them.
+
+Interactive Controls:
+  ↑/↓         Navigate through the list
+  Tab         Toggle selection
+  Enter       Confirm selection
+  Esc         Cancel operation
+  Type        Filter by branch or message`,
+	Example: `  # Choose stashes of merged or deleted branches to drop
+  git-gone stashes clean
+
+  # Also offer stashes older than 90 days
+  git-gone stashes clean --older-than 90d
+
+  # Offer every stash
+  git-gone stashes clean --unmerged
+
+  # Show the git operations without dropping anything
+  git-gone stashes clean --all --dry-run`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runStashesClean()
+	},
+}
+
+func init() {
+	stashesCleanCmd.Flags().StringVar(&stashOlderThan, "older-than", "", "Also offer stashes older than this age (e.g. 90d, 12w)")
+
+	stashesCmd.AddCommand(stashesListCmd)
+	stashesCmd.AddCommand(stashesCleanCmd)
+}
+
+// stashStatus describes what happened to the branch a stash was created on
+type stashStatus struct {
+	stash git.Stash
+	// status is "branch deleted", "merged into <target>", "branch exists" or "detached"
+	status string
+	// code is the status in events: detached, branch_deleted, merged or branch_exists
+	code string
+	// orphaned is true if the branch was deleted or merged
+	orphaned bool
+}
+
+// classifyStashes determines for every stash whether its branch still exists
+// or was merged into an integration target.
+func classifyStashes(stashes []git.Stash) []stashStatus {
+	defaultBranch, err := repo.GetDefaultBranch(resolveRemote())
+	if err != nil {
+		fatalf("Failed to get default branch: %v", err)
+	}
+	targets := resolveTargets(defaultBranch)
+	targetSet := make(map[string]bool)
+	for _, target := range targets {
+		targetSet[target] = true
+	}
+
+	mergedTargets, err := repo.GetMergedTargets(targets)
+	if err != nil {
+		fmt.Printf("%s  Warning: Failed to get merged branches: %v\n", tui.EmojiWarning, err)
+	}
+	localBranches := make(map[string]bool)
+	if branches, err := repo.GetAllLocalBranches(); err == nil {
+		for _, branch := range branches {
+			localBranches[branch] = true
+		}
+	}
+
+	statuses := make([]stashStatus, len(stashes))
+	for i, stash := range stashes {
+		s := stashStatus{stash: stash}
+		switch {
+		case stash.Branch == "":
+			s.status, s.code = "detached", "detached"
+		case !localBranches[stash.Branch]:
+			s.status, s.code = "branch deleted", "branch_deleted"
+			s.orphaned = true
+		case !targetSet[stash.Branch] && mergedTargets[stash.Branch] != "":
+			s.status, s.code = "merged into "+mergedTargets[stash.Branch], "merged"
+			s.orphaned = true
+		default:
+			s.status, s.code = "branch exists", "branch_exists"
+		}
+		statuses[i] = s
+	}
+	return statuses
+}
+
+// formatStash renders a stash entry for listings and the selector
+func formatStash(s stashStatus, now time.Time) string {
+	branch := s.stash.Branch
+	if branch == "" {
+		branch = "detached"
+	}
+	days := int(now.Sub(s.stash.CreatedAt).Hours() / 24)
+	return fmt.Sprintf("%s  [%s]  %s  (%d days ago, %s)",
+		s.stash.Ref(),
+		branch,
+		s.stash.Message,
+		days,
+		s.status)
+}
+
+// loadStashes checks the repository and returns the classified stash entries
+func loadStashes() []stashStatus {
+	requireRepository()
+
+	stashes, err := repo.ListStashes()
+	if err != nil {
+		fatalf("Failed to list stashes: %v", err)
+	}
+	if len(stashes) == 0 {
+		return nil
+	}
+	return classifyStashes(stashes)
+}
+
+func runStashesList() {
+	statuses := loadStashes()
+
+	if len(statuses) == 0 {
+		fmt.Printf("%s No stash entries.\n", tui.EmojiSuccess)
+		return
+	}
+
+	now := time.Now()
+	orphaned := 0
+	fmt.Printf("\n%s Found %d stash(es):\n", tui.EmojiSearch, len(statuses))
+	for _, s := range statuses {
+		fmt.Printf("   • %s\n", formatStash(s, now))
+		if s.orphaned {
+			orphaned++
+		}
+	}
+
+	if orphaned > 0 {
+		fmt.Printf("\n%d stash(es) belong to merged or deleted branches. Run 'git-gone stashes clean' to drop them.\n", orphaned)
+	}
+}
+
+func runStashesClean() {
+	age, err := parseAge(stashOlderThan)
+	if err != nil {
+		fatalf("%v", err)
+	}
+
+	summary := &events.Summary{Command: "stashes clean", DryRun: dryRun}
+	defer finish(summary)
+
+	statuses := loadStashes()
+
+	now := time.Now()
+	var labels []string
+	stashesByLabel := make(map[string]git.Stash)
+	for _, s := range statuses {
+		if !s.orphaned && !includeUnmerged && !git.IsOlderThan(s.stash.CreatedAt, age, now) {
+			continue
+		}
+		label := formatStash(s, now)
+		labels = append(labels, label)
+		stashesByLabel[label] = s.stash
+		// Only stashes of merged or deleted branches are safe to drop; the
+		// others are offered because of --unmerged or --older-than
+		risk := "safe"
+		if !s.orphaned {
+			risk = "unmerged"
+		}
+		events.Emit(&events.Candidate{Kind: events.KindStash, Name: s.stash.Ref(), Reason: s.code, Risk: risk, SHA: s.stash.SHA})
+	}
+	summary.Candidates = len(labels)
+
+	if len(labels) == 0 {
+		fmt.Printf("%s No stashes to drop.\n", tui.EmojiSuccess)
+		return
+	}
+
+	fmt.Printf("\n%s Found %d stash(es) to drop:\n", tui.EmojiSearch, len(labels))
+	for _, label := range labels {
+		fmt.Printf("   • %s\n", label)
+	}
+
+	// Select stashes: use all if -a flag is set, otherwise use interactive fzf
+	var selected []string
+	if selectAll {
+		selected = labels
+	} else {
+		selected, err = selectItems(labels, "Select stashes to drop > ")
+		if err != nil {
+			if err.Error() == "abort" {
+				fmt.Printf("\n%s Selection cancelled\n", tui.EmojiError)
+				emitCancelled(events.KindStash)
+				summary.Cancelled = true
+				return
+			}
+			fatalf("Failed to select stashes: %v", err)
+		}
+	}
+
+	chosen := make([]git.Stash, len(selected))
+	refs := make([]string, len(selected))
+	for i, label := range selected {
+		chosen[i] = stashesByLabel[label]
+		refs[i] = chosen[i].Ref()
+	}
+	emitSelection(events.KindStash, refs)
+	summary.Selected = len(refs)
+
+	if len(selected) == 0 {
+		fmt.Printf("\n%s No stashes selected for dropping\n", tui.EmojiSuccess)
+		return
+	}
+
+	fmt.Printf("\n%s The following stashes will be dropped:\n", tui.EmojiWarning)
+	for _, label := range selected {
+		fmt.Printf("  • %s\n", label)
+	}
+
+	// Confirm dropping (unless --force is used)
+	if !forceDelete {
+		if !confirmDeletion("Are you sure you want to drop these stashes?") {
+			fmt.Printf("%s Dropping cancelled\n", tui.EmojiError)
+			summary.Cancelled = true
+			return
+		}
+	}
+
+	// In dry-run mode, show what would have been executed and stop here
+	if dryRun {
+		// Stashes are dropped from the highest index down, see git.DropStashes
+		sort.Slice(chosen, func(i, j int) bool {
+			return chosen[i].Index > chosen[j].Index
+		})
+		var operations []string
+		for _, stash := range chosen {
+			operations = append(operations, "git stash drop "+stash.Ref())
+		}
+		printDryRun(operations)
+		return
+	}
+
+	dropped, err := repo.DropStashes(chosen)
+	for _, stash := range dropped {
+		fmt.Printf("%s Dropped %s (%s)\n", tui.EmojiSuccess, stash.Ref(), git.ShortSHA(stash.SHA))
+		emitDeletion(summary, events.Deletion{Kind: events.KindStash, Name: stash.Ref()}, nil)
+	}
+	if err != nil {
+		fmt.Printf("%s Failed to drop stashes: %v\n", tui.EmojiError, err)
+		// Dropping stops at the first failure, so every other stash was kept
+		droppedSHAs := make(map[string]bool)
+		for _, stash := range dropped {
+			droppedSHAs[stash.SHA] = true
+		}
+		for _, stash := range chosen {
+			if !droppedSHAs[stash.SHA] {
+				emitDeletion(summary, events.Deletion{Kind: events.KindStash, Name: stash.Ref()}, err)
+			}
+		}
+	}
+
+	if err == nil {
+		fmt.Printf("\n%s Successfully dropped %d stash(es)\n", tui.EmojiCelebrate, len(dropped))
+	}
+	if len(dropped) > 0 {
+		fmt.Println("   Recover a dropped stash with 'git stash store <sha>' before the next 'git gc'.")
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"os"
 	"sort"
+	"strings"
 
+	"git-gone/internal/events"
 	"git-gone/internal/git"
 	"git-gone/internal/tui"
 
@@ -12,7 +14,11 @@ import (
 )
 
 // Tag-specific flags
-var includeNonStale bool
+var (
+	includeNonStale  bool
+	allRemotes       bool
+	pushTagDeletions bool
+)
 
 var tagsCmd = &cobra.Command{
 	Use:   "tags",
@@ -20,9 +26,13 @@ var tagsCmd = &cobra.Command{
 	Long: `Manage and clean up tags in the repository.
 
 This command provides subcommands to list and clean stale tags
-(tags that exist locally but not on the remote).
+(tags that exist locally but not on the remote), and divergent tags
+(tags that exist on both sides but point to different objects).
+
+Use --no-stale (-n) to include ALL local tags, not just stale ones.
 
-Use --no-stale (-n) to include ALL local tags, not just stale ones.`,
+Tags are compared against the remote chosen with --remote (origin by default).
+Use --all-remotes to only treat tags missing from every remote as stale.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		// Default behavior: show help
 		_ = cmd.Help()
@@ -35,6 +45,8 @@ var tagsListCmd = &cobra.Command{
 	Long: `List stale tags that exist locally but not on the remote.
 
 These tags may have been deleted from the remote or were never pushed.
+Tags that exist on the remote but point to a different object there are
+listed separately as divergent.
 
 Use --no-stale (-n) to list ALL local tags instead.`,
 	Example: `  # List stale tags only
@@ -42,7 +54,13 @@ Use --no-stale (-n) to list ALL local tags instead.`,
 
   # List ALL local tags
   git-gone tags list --no-stale
-  git-gone tags list -n`,
+  git-gone tags list -n
+
+  # Compare against the upstream remote of a fork
+  git-gone tags list --remote upstream
+
+  # List tags that exist on none of the remotes
+  git-gone tags list --all-remotes`,
 	Run: func(cmd *cobra.Command, args []string) {
 		runTagsList()
 	},
@@ -61,6 +79,15 @@ This command will:
 
 Use --no-stale (-n) to include ALL local tags, not just stale ones.
 
+Divergent tags, marked (d), point to a different object on the remote because
+the tag was retagged locally or moved on the remote. They can be reset to the
+remote instead of being deleted; deleting them requires typing DELETE.
+
+Use --push to also delete the selected tags from the remote. Remote deletions
+always require typing DELETE, even with --force. A tag is only deleted from
+the remote if it still points to the object shown there (or to the local
+object for a tag that is not divergent).
+
 Interactive Controls:
   ↑/↓         Navigate through the list
   Tab         Toggle selection
@@ -78,7 +105,13 @@ Interactive Controls:
 
   # Clean ANY local tag (not just stale)
   git-gone tags clean --no-stale
-  git-gone tags clean -n`,
+  git-gone tags clean -n
+
+  # Delete the selected tags locally and from the remote
+  git-gone tags clean --no-stale --push
+
+  # Show the git operations without deleting anything
+  git-gone tags clean --dry-run`,
 	Run: func(cmd *cobra.Command, args []string) {
 		runTagsClean()
 	},
@@ -88,49 +121,49 @@ func init() {
 	// Add --no-stale flag to tags subcommands
 	tagsListCmd.Flags().BoolVarP(&includeNonStale, "no-stale", "n", false, "Include ALL local tags, not just stale ones")
 	tagsCleanCmd.Flags().BoolVarP(&includeNonStale, "no-stale", "n", false, "Include ALL local tags, not just stale ones")
+	tagsCleanCmd.Flags().BoolVar(&pushTagDeletions, "push", false, "Also delete the selected tags from the remote (requires typing DELETE)")
+	tagsCmd.PersistentFlags().BoolVar(&allRemotes, "all-remotes", false, "Compare against the union of tags on all remotes instead of a single remote")
 
 	tagsCmd.AddCommand(tagsListCmd)
 	tagsCmd.AddCommand(tagsCleanCmd)
 }
 
 func runTagsList() {
-	// Check if we're in a git repository
-	if err := git.CheckGitRepository(); err != nil {
-		fmt.Printf("%s Not in a git repository\n", tui.EmojiError)
-		os.Exit(1)
-	}
+	requireRepository()
 
 	var tags []string
 	var err error
 	var listType string
 
+	remotes := staleTagRemotes()
 	if includeNonStale {
 		// List ALL local tags
-		tags, err = git.GetLocalTags()
+		tags, err = repo.GetLocalTags()
 		if err != nil {
-			fmt.Printf("%s Failed to get local tags: %v\n", tui.EmojiError, err)
-			os.Exit(1)
+			fatalf("Failed to get local tags: %v", err)
 		}
 		listType = "local"
 	} else {
 		// Check if remote exists for stale detection
-		if !git.HasRemote() {
-			fmt.Printf("%s No remote 'origin' configured. Cannot determine stale tags.\n", tui.EmojiWarning)
+		if len(remotes) == 0 {
+			fmt.Printf("%s No remote configured. Cannot determine stale tags.\n", tui.EmojiWarning)
 			fmt.Printf("   Use --no-stale (-n) to list all local tags instead.\n")
 			return
 		}
 
-		fmt.Printf("%s Fetching remote tags...\n", tui.EmojiRefresh)
+		fmt.Printf("%s Fetching remote tags from %s...\n", tui.EmojiRefresh, strings.Join(remotes, ", "))
 
-		tags, err = git.GetStaleTags()
+		tags, err = repo.GetStaleTags(remotes...)
 		if err != nil {
-			fmt.Printf("%s Failed to get stale tags: %v\n", tui.EmojiError, err)
-			os.Exit(1)
+			fatalf("Failed to get stale tags: %v", err)
 		}
 		listType = "stale"
 	}
 
-	if len(tags) == 0 {
+	tags = filterProtectedTags(tags)
+	divergent := findDivergentTags(repo, cfg.ProtectedTags, remotes)
+
+	if len(tags) == 0 && len(divergent) == 0 {
 		if includeNonStale {
 			fmt.Printf("%s No local tags found.\n", tui.EmojiSuccess)
 		} else {
@@ -141,55 +174,84 @@ func runTagsList() {
 
 	sort.Strings(tags)
 
-	if includeNonStale {
-		fmt.Printf("\n%s  Found %d local tag(s):\n", tui.EmojiTag, len(tags))
-	} else {
-		fmt.Printf("\n%s  Found %d %s tag(s) (local only, not on remote):\n", tui.EmojiTag, len(tags), listType)
+	if len(tags) > 0 {
+		if includeNonStale {
+			fmt.Printf("\n%s  Found %d local tag(s):\n", tui.EmojiTag, len(tags))
+		} else {
+			fmt.Printf("\n%s  Found %d %s tag(s) (local only, not on remote):\n", tui.EmojiTag, len(tags), listType)
+		}
+		for _, tag := range tags {
+			fmt.Printf("   • %s\n", tag)
+		}
 	}
-	for _, tag := range tags {
-		fmt.Printf("   • %s\n", tag)
+
+	if len(divergent) > 0 {
+		fmt.Printf("\n%s  Found %d divergent tag(s) (pointing to a different object on the remote):\n", tui.EmojiWarning, len(divergent))
+		for _, name := range sortedTagNames(divergent) {
+			fmt.Printf("   • %s\n", formatDivergentTag(divergent[name]))
+		}
 	}
 }
 
 func runTagsClean() {
-	// Unlike branches, tags have no unmerged/dangerous distinction, so
+	// Unlike branches, stale tags have no unmerged/dangerous distinction, so
 	// "clean --all --force" is a valid batch workflow: select every stale
 	// tag and delete it without confirmation. No incompatibility check here.
+	// Divergent tags and remote deletions always require typing DELETE.
 
-	// Check if we're in a git repository
-	if err := git.CheckGitRepository(); err != nil {
-		fmt.Printf("%s Not in a git repository\n", tui.EmojiError)
-		os.Exit(1)
-	}
+	summary := &events.Summary{Command: "tags clean", DryRun: dryRun}
+	defer finish(summary)
+
+	requireRepository()
 
 	var tags []string
 	var err error
 
+	remotes := staleTagRemotes()
 	if includeNonStale {
 		// Get ALL local tags
-		tags, err = git.GetLocalTags()
+		tags, err = repo.GetLocalTags()
 		if err != nil {
-			fmt.Printf("%s Failed to get local tags: %v\n", tui.EmojiError, err)
-			os.Exit(1)
+			fatalf("Failed to get local tags: %v", err)
 		}
 	} else {
 		// Check if remote exists for stale detection
-		if !git.HasRemote() {
-			fmt.Printf("%s No remote 'origin' configured. Cannot determine stale tags.\n", tui.EmojiWarning)
+		if len(remotes) == 0 {
+			fmt.Printf("%s No remote configured. Cannot determine stale tags.\n", tui.EmojiWarning)
 			fmt.Printf("   Use --no-stale (-n) to manage all local tags instead.\n")
 			return
 		}
 
-		fmt.Printf("%s Fetching remote tags...\n", tui.EmojiRefresh)
+		fmt.Printf("%s Fetching remote tags from %s...\n", tui.EmojiRefresh, strings.Join(remotes, ", "))
+		events.Emit(&events.FetchStarted{Remotes: remotes})
 
-		tags, err = git.GetStaleTags()
+		tags, err = repo.GetStaleTags(remotes...)
 		if err != nil {
-			fmt.Printf("%s Failed to get stale tags: %v\n", tui.EmojiError, err)
-			os.Exit(1)
+			events.Emit(&events.FetchFinished{Error: err.Error()})
+			fatalf("Failed to get stale tags: %v", err)
 		}
+		events.Emit(&events.FetchFinished{})
 	}
 
-	if len(tags) == 0 {
+	tags = filterProtectedTags(tags)
+	divergent := findDivergentTags(repo, cfg.ProtectedTags, remotes)
+
+	// Build the candidates; divergent tags are marked and always dangerous
+	var candidates []git.DeletionCandidate
+	for _, tag := range tags {
+		if _, ok := divergent[tag]; !ok {
+			candidates = append(candidates, git.NewTagCandidate(tag))
+		}
+	}
+	for _, name := range sortedTagNames(divergent) {
+		candidates = append(candidates, git.NewDivergentTagCandidate(name))
+	}
+	for _, c := range candidates {
+		emitCandidate(c)
+	}
+	summary.Candidates = len(candidates)
+
+	if len(candidates) == 0 {
 		if includeNonStale {
 			fmt.Printf("%s No local tags found.\n", tui.EmojiSuccess)
 		} else {
@@ -198,59 +260,331 @@ func runTagsClean() {
 		return
 	}
 
-	sort.Strings(tags)
+	labels := make([]string, len(candidates))
+	candidateByLabel := make(map[string]git.DeletionCandidate)
+	for i, c := range candidates {
+		labels[i] = c.DisplayLabel
+		if d, ok := divergent[c.Name]; ok {
+			labels[i] = git.DivergentPrefix + formatDivergentTag(d)
+		}
+		candidateByLabel[labels[i]] = c
+	}
+	sort.Strings(labels)
 
-	if includeNonStale {
-		fmt.Printf("\n%s  Found %d local tag(s):\n", tui.EmojiTag, len(tags))
-	} else {
-		fmt.Printf("\n%s  Found %d stale tag(s) (local only, not on remote):\n", tui.EmojiTag, len(tags))
+	if safeCount := len(candidates) - len(divergent); includeNonStale {
+		fmt.Printf("\n%s  Found %d local tag(s):\n", tui.EmojiTag, safeCount)
+	} else if safeCount > 0 {
+		fmt.Printf("\n%s  Found %d stale tag(s) (local only, not on remote):\n", tui.EmojiTag, safeCount)
+	}
+	if len(divergent) > 0 {
+		fmt.Printf("   • %d divergent tag(s) ((d) points to a different object on the remote, requires confirmation)\n", len(divergent))
 	}
 
 	// Select tags: use all if -a flag is set, otherwise use interactive fzf
-	var selectedTags []string
+	var selectedLabels []string
 	if selectAll {
-		selectedTags = tags
+		selectedLabels = labels
 	} else {
-		selectedTags, err = tui.SelectTags(tags)
+		selectedLabels, err = selectItems(labels, "Select tags to delete > ")
 		if err != nil {
 			if err.Error() == "abort" {
 				fmt.Printf("\n%s Selection cancelled\n", tui.EmojiError)
+				emitCancelled(events.KindTag)
+				summary.Cancelled = true
 				return
 			}
-			fmt.Printf("%s Failed to select tags: %v\n", tui.EmojiError, err)
-			os.Exit(1)
+			fatalf("Failed to select tags: %v", err)
 		}
 	}
 
-	if len(selectedTags) == 0 {
+	selectedNames := make([]string, len(selectedLabels))
+	for i, label := range selectedLabels {
+		selectedNames[i] = candidateByLabel[label].Name
+	}
+	emitSelection(events.KindTag, selectedNames)
+	summary.Selected = len(selectedNames)
+
+	if len(selectedLabels) == 0 {
 		fmt.Printf("\n%s No tags selected for deletion\n", tui.EmojiSuccess)
 		return
 	}
 
+	// Separate divergent tags from safe tags
+	var selectedTags []string
+	var divergentSelected []string
+	for _, label := range selectedLabels {
+		c := candidateByLabel[label]
+		if c.RiskLevel == git.RiskDangerous {
+			divergentSelected = append(divergentSelected, c.Name)
+		} else {
+			selectedTags = append(selectedTags, c.Name)
+		}
+	}
+
 	// Show tags to delete
+	scope := ""
+	if pushTagDeletions {
+		scope = " (local + remote)"
+	}
 	fmt.Printf("\n%s The following tags will be deleted:\n", tui.EmojiWarning)
 	for _, tag := range selectedTags {
-		fmt.Printf("  • %s\n", tag)
+		fmt.Printf("  • %s%s\n", tag, scope)
+	}
+	for _, tag := range divergentSelected {
+		fmt.Printf("  • %s%s\n", git.DivergentPrefix+formatDivergentTag(divergent[tag]), scope)
 	}
 
-	// Confirm deletion (unless --force is used)
-	if !forceDelete {
-		if !tui.ConfirmDeletion("Are you sure you want to delete these tags?") {
+	// Confirm deletion of safe tags (unless --force is used). Remote deletions
+	// are confirmed below with the dangerous ones.
+	if len(selectedTags) > 0 && !forceDelete && !pushTagDeletions {
+		if !confirmDeletion("Are you sure you want to delete these tags?") {
 			fmt.Printf("%s Deletion cancelled\n", tui.EmojiError)
+			summary.Cancelled = true
 			return
 		}
 	}
 
+	// Divergent tags can be reset to the remote instead of being deleted
+	var resetTags []string
+	if len(divergentSelected) > 0 {
+		fmt.Printf("\n%s %d selected tag(s) point to a different object on the remote.\n", tui.EmojiWarning, len(divergentSelected))
+		switch promptChoice("Reset them to the remote, delete them, or skip them?", "reset", "delete", "skip") {
+		case "reset":
+			resetTags = divergentSelected
+			divergentSelected = nil
+		case "delete":
+			// Confirmed below
+		default:
+			fmt.Printf("%s Skipping divergent tags\n", tui.EmojiError)
+			divergentSelected = nil
+		}
+	}
+
+	// Always confirm dangerous deletions (even with -f): divergent tags, and
+	// every tag when deletions are pushed to the remote
+	dangerous := append([]string(nil), divergentSelected...)
+	if pushTagDeletions {
+		dangerous = append(dangerous, selectedTags...)
+	}
+	if len(dangerous) > 0 && !confirmDangerousOperation(dangerous, "tag(s)") {
+		fmt.Printf("%s Deletion of dangerous tags cancelled\n", tui.EmojiError)
+		// Still delete safe tags if force was used, and reset the tags chosen
+		// for reset; the run is only cancelled if nothing proceeds
+		if forceDelete && !pushTagDeletions && len(selectedTags) > 0 {
+			divergentSelected = nil
+		} else if len(resetTags) > 0 {
+			selectedTags, divergentSelected = nil, nil
+		} else {
+			summary.Cancelled = true
+			return
+		}
+	}
+
+	toDelete := append(selectedTags, divergentSelected...)
+
+	// Only tags that exist on the remote can be deleted there
+	var remote string
+	var remoteDeletes map[string]string
+	if pushTagDeletions && len(toDelete) > 0 {
+		remote = resolveRemote()
+		if remote == "" {
+			fatalf("No remote configured")
+		}
+		remoteDeletes = remoteTagLeases(remote, toDelete, divergent)
+	}
+
+	// In dry-run mode, show what would have been executed and stop here
+	if dryRun {
+		var operations []string
+		for _, tag := range toDelete {
+			operations = append(operations, plannedTagDeletion(tag)...)
+		}
+		for _, tag := range resetTags {
+			operations = append(operations, plannedTagReset(divergent[tag])...)
+		}
+		if len(remoteDeletes) > 0 {
+			operations = append(operations, plannedRemoteTagDeletion(remote, remoteDeletes))
+		}
+		printDryRun(operations)
+		return
+	}
+
 	// Delete tags
 	deletedCount := 0
-	for _, tag := range selectedTags {
-		if err := git.DeleteTag(tag); err != nil {
+	for _, tag := range toDelete {
+		err := repo.DeleteTag(tag)
+		if err != nil {
 			fmt.Printf("%s Failed to delete tag %s: %v\n", tui.EmojiError, tag, err)
 		} else {
 			fmt.Printf("%s Deleted tag: %s\n", tui.EmojiSuccess, tag)
 			deletedCount++
 		}
+		emitDeletion(summary, events.Deletion{Kind: events.KindTag, Name: tag}, err)
+	}
+
+	// Reset divergent tags to the remote
+	resetCount := 0
+	for _, tag := range resetTags {
+		d := divergent[tag]
+		err := repo.ResetTagToRemote(tag, d.Remote)
+		if err != nil {
+			fmt.Printf("%s Failed to reset tag %s: %v\n", tui.EmojiError, tag, err)
+		} else {
+			fmt.Printf("%s Reset tag %s to %s (%s)\n", tui.EmojiSuccess, tag, d.Remote, git.ShortSHA(d.RemoteSHA))
+			resetCount++
+		}
+		emitRestore(summary, events.Restore{Kind: events.KindTag, Name: tag}, err)
+	}
+
+	// Delete tags from the remote in a single push
+	if len(remoteDeletes) > 0 {
+		deleteRemoteTags(summary, remote, remoteDeletes)
 	}
 
 	fmt.Printf("\n%s Successfully deleted %d tag(s)\n", tui.EmojiCelebrate, deletedCount)
+	if resetCount > 0 {
+		fmt.Printf("   Reset %d tag(s) to the remote\n", resetCount)
+	}
+}
+
+// remoteTagLeases returns the tags to delete from remote, each mapped to the
+// object it must still point to there: the remote object shown for a
+// divergent tag, the local object otherwise. Tags missing on the remote are
+// left out, and tags pointing to another object there are kept.
+func remoteTagLeases(remote string, names []string, divergent map[string]git.DivergentTag) map[string]string {
+	remoteTags, err := repo.GetRemoteTagObjects(remote)
+	if err != nil {
+		fatalf("Failed to get tags from %s: %v", remote, err)
+	}
+	localTags, err := repo.GetLocalTagObjects()
+	if err != nil {
+		fatalf("Failed to get local tags: %v", err)
+	}
+
+	leases := make(map[string]string)
+	for _, name := range names {
+		remoteSHA, ok := remoteTags[name]
+		if !ok {
+			continue
+		}
+		expected := localTags[name]
+		if d, ok := divergent[name]; ok && d.Remote == remote {
+			expected = d.RemoteSHA
+		}
+		if remoteSHA != expected {
+			fmt.Printf("%s  Keeping tag %s on %s: it points to %s there, not %s\n", tui.EmojiWarning, name, remote, git.ShortSHA(remoteSHA), git.ShortSHA(expected))
+			continue
+		}
+		leases[name] = expected
+	}
+	return leases
+}
+
+// deleteRemoteTags deletes the leased tags from remote in a single push and
+// emits the result. Their local deletions are already counted in summary, so
+// only failures are added.
+func deleteRemoteTags(summary *events.Summary, remote string, leases map[string]string) {
+	deleted, err := repo.DeleteRemoteTags(remote, leases)
+	if err != nil {
+		fmt.Printf("%s Failed to delete tags from %s: %v\n", tui.EmojiError, remote, err)
+	}
+	if len(deleted) > 0 {
+		fmt.Printf("%s Deleted %d tag(s) from %s\n", tui.EmojiSuccess, len(deleted), remote)
+	}
+
+	done := make(map[string]bool)
+	for _, tag := range deleted {
+		done[tag] = true
+	}
+	for _, tag := range sortedLeaseTags(leases) {
+		deletion := &events.Deletion{Kind: events.KindTag, Name: tag, Remote: remote}
+		if !done[tag] {
+			deletion.Error = "not deleted from " + remote
+			if err != nil {
+				deletion.Error = err.Error()
+			}
+			summary.Failed++
+		}
+		events.Emit(deletion)
+	}
+}
+
+// findDivergentTags returns the local tags that point to a different object
+// on one of the remotes, keyed by name. Protected tags are left out.
+func findDivergentTags(r *git.Repository, protectedTags, remotes []string) map[string]git.DivergentTag {
+	divergent := make(map[string]git.DivergentTag)
+	for _, remote := range remotes {
+		tags, err := r.GetDivergentTags(remote)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s  Warning: Failed to compare tags with %s: %v\n", tui.EmojiWarning, remote, err)
+			continue
+		}
+		for _, tag := range tags {
+			if _, protected := git.MatchProtectedPattern(tag.Name, protectedTags); protected {
+				continue
+			}
+			if _, ok := divergent[tag.Name]; !ok {
+				divergent[tag.Name] = tag
+			}
+		}
+	}
+	return divergent
+}
+
+// sortedLeaseTags returns the names of the leased tags in order
+func sortedLeaseTags(leases map[string]string) []string {
+	names := make([]string, 0, len(leases))
+	for name := range leases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// sortedTagNames returns the names of the divergent tags in order
+func sortedTagNames(divergent map[string]git.DivergentTag) []string {
+	names := make([]string, 0, len(divergent))
+	for name := range divergent {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// formatDivergentTag renders a divergent tag with both objects
+func formatDivergentTag(d git.DivergentTag) string {
+	return fmt.Sprintf("%s  (local %s, %s %s)", d.Name, git.ShortSHA(d.LocalSHA), d.Remote, git.ShortSHA(d.RemoteSHA))
+}
+
+// staleTagRemotes returns the remotes whose tags count for stale detection:
+// every remote with --all-remotes, otherwise the resolved remote
+func staleTagRemotes() []string {
+	if allRemotes {
+		remotes, err := repo.GetRemotes()
+		if err != nil {
+			fatalf("Failed to list remotes: %v", err)
+		}
+		return remotes
+	}
+	if remote := resolveRemote(); remote != "" {
+		return []string{remote}
+	}
+	return nil
+}
+
+// filterProtectedTags removes tags matching the configured protected_tags patterns
+func filterProtectedTags(tags []string) []string {
+	var filtered []string
+	protectedCount := 0
+	for _, tag := range tags {
+		if _, protected := git.MatchProtectedPattern(tag, cfg.ProtectedTags); protected {
+			protectedCount++
+			continue
+		}
+		filtered = append(filtered, tag)
+	}
+	if protectedCount > 0 {
+		fmt.Printf("🔒 %d tag(s) protected by configured patterns\n", protectedCount)
+	}
+	return filtered
 }
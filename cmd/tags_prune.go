@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"fmt"
+
+	"git-gone/internal/events"
+	"git-gone/internal/git"
+	"git-gone/internal/tui"
+
+	"github.com/spf13/cobra"
+)
+
+// Tag retention flags
+var (
+	keepPatches     int
+	dropPrereleases bool
+	keepNightlies   int
+	nightlyPattern  string
+)
+
+var tagsPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Delete tags according to semantic version retention rules",
+	Long: `Delete tags according to semantic version retention rules.
+
+Tag names are parsed as semantic versions (v1.2.3, 1.2.3-rc.1). The rules
+decide which tags to keep:
+  --keep-patches N      Keep the last N patch releases of every minor line
+  --drop-prereleases    Drop pre-releases (-rc, -beta, ...) once the final
+                        release is tagged
+  --keep-nightlies N    Keep only the latest N nightly tags (matching
+                        --nightly-pattern)
+
+Tags no rule applies to, and tags matching protected_tags, are always kept.
+The keep/drop decision for every tag is shown before confirmation.
+
+Rules can also be set in the tag_retention section of the config.
+
+Use --push to also delete the dropped tags from the remote. Remote deletions
+always require typing DELETE, even with --force.`,
+	Example: `  # Keep the last 5 patch releases per minor and drop released pre-releases
+  git-gone tags prune --keep-patches 5 --drop-prereleases
+
+  # Keep only the latest 7 nightlies, also on the remote
+  git-gone tags prune --keep-nightlies 7 --push
+
+  # Show the git operations without deleting anything
+  git-gone tags prune --keep-patches 3 --dry-run`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runTagsPrune()
+	},
+}
+
+func init() {
+	tagsPruneCmd.Flags().IntVar(&keepPatches, "keep-patches", 0, "Keep the last N patch releases of every minor line (0 keeps all)")
+	tagsPruneCmd.Flags().BoolVar(&dropPrereleases, "drop-prereleases", false, "Drop pre-release tags whose final release is tagged")
+	tagsPruneCmd.Flags().IntVar(&keepNightlies, "keep-nightlies", 0, "Keep only the latest N nightly tags (0 keeps all)")
+	tagsPruneCmd.Flags().StringVar(&nightlyPattern, "nightly-pattern", git.DefaultNightlyPattern, "Glob matching nightly tags")
+	tagsPruneCmd.Flags().BoolVar(&pushTagDeletions, "push", false, "Also delete the dropped tags from the remote (requires typing DELETE)")
+
+	tagsCmd.AddCommand(tagsPruneCmd)
+}
+
+func runTagsPrune() {
+	summary := &events.Summary{Command: "tags prune", DryRun: dryRun}
+	defer finish(summary)
+
+	requireRepository()
+
+	policy := git.RetentionPolicy{
+		KeepPatches:     keepPatches,
+		DropPrereleases: dropPrereleases,
+		KeepNightlies:   keepNightlies,
+		NightlyPattern:  nightlyPattern,
+		Protected:       cfg.ProtectedTags,
+	}
+	if keepPatches < 0 || keepNightlies < 0 {
+		fatalf("--keep-patches and --keep-nightlies must not be negative")
+	}
+	if policy.IsEmpty() {
+		fatalf("No retention rule given. Use --keep-patches, --drop-prereleases or --keep-nightlies.")
+	}
+
+	tags, err := repo.GetLocalTags()
+	if err != nil {
+		fatalf("Failed to get local tags: %v", err)
+	}
+	dates, err := repo.GetTagDates()
+	if err != nil {
+		fmt.Printf("%s  Warning: Failed to get tag dates: %v\n", tui.EmojiWarning, err)
+	}
+
+	decisions := git.ApplyRetention(tags, dates, policy)
+	if len(decisions) == 0 {
+		fmt.Printf("%s No local tags found.\n", tui.EmojiSuccess)
+		return
+	}
+
+	// Show the decision for every tag before asking for confirmation
+	var candidates []git.DeletionCandidate
+	fmt.Printf("\n%s  Retention decisions for %d tag(s):\n", tui.EmojiTag, len(decisions))
+	for _, d := range decisions {
+		verdict := "keep"
+		if !d.Keep {
+			verdict = "DROP"
+			candidates = append(candidates, git.NewPrunedTagCandidate(d.Tag))
+		}
+		fmt.Printf("   %s  %s  (%s)\n", verdict, d.Tag, d.Reason)
+	}
+
+	for _, c := range candidates {
+		emitCandidate(c)
+	}
+	summary.Candidates = len(candidates)
+	if len(candidates) == 0 {
+		fmt.Printf("\n%s Nothing to prune. Every tag is kept.\n", tui.EmojiSuccess)
+		return
+	}
+
+	// Every dropped tag is selected; there is no selector
+	names := make([]string, len(candidates))
+	for i, c := range candidates {
+		names[i] = c.Name
+	}
+	emitSelection(events.KindTag, names)
+	summary.Selected = len(names)
+	scope := ""
+	if pushTagDeletions {
+		scope = " (local + remote)"
+	}
+	fmt.Printf("\n%s %d tag(s) will be deleted%s, %d kept\n", tui.EmojiWarning, len(names), scope, len(decisions)-len(names))
+
+	// Confirm deletion (unless --force is used); remote deletions always require typing DELETE
+	if pushTagDeletions {
+		if !confirmDangerousOperation(names, "tag(s)") {
+			fmt.Printf("%s Deletion cancelled\n", tui.EmojiError)
+			summary.Cancelled = true
+			return
+		}
+	} else if !forceDelete {
+		if !confirmDeletion("Are you sure you want to delete these tags?") {
+			fmt.Printf("%s Deletion cancelled\n", tui.EmojiError)
+			summary.Cancelled = true
+			return
+		}
+	}
+
+	// Only tags that exist on the remote can be deleted there
+	var remote string
+	var remoteDeletes map[string]string
+	if pushTagDeletions {
+		remote = resolveRemote()
+		if remote == "" {
+			fatalf("No remote configured")
+		}
+		remoteDeletes = remoteTagLeases(remote, names, nil)
+	}
+
+	// In dry-run mode, show what would have been executed and stop here
+	if dryRun {
+		var operations []string
+		for _, name := range names {
+			operations = append(operations, plannedTagDeletion(name)...)
+		}
+		if len(remoteDeletes) > 0 {
+			operations = append(operations, plannedRemoteTagDeletion(remote, remoteDeletes))
+		}
+		printDryRun(operations)
+		return
+	}
+
+	deletedCount := 0
+	for _, name := range names {
+		err := repo.DeleteTag(name)
+		if err != nil {
+			fmt.Printf("%s Failed to delete tag %s: %v\n", tui.EmojiError, name, err)
+		} else {
+			fmt.Printf("%s Deleted tag: %s\n", tui.EmojiSuccess, name)
+			deletedCount++
+		}
+		emitDeletion(summary, events.Deletion{Kind: events.KindTag, Name: name}, err)
+	}
+
+	// Delete tags from the remote in a single push
+	if len(remoteDeletes) > 0 {
+		deleteRemoteTags(summary, remote, remoteDeletes)
+	}
+
+	fmt.Printf("\n%s Successfully deleted %d tag(s)\n", tui.EmojiCelebrate, deletedCount)
+}
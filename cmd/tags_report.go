@@ -0,0 +1,303 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"git-gone/internal/events"
+	"git-gone/internal/git"
+	"git-gone/internal/tui"
+
+	"github.com/spf13/cobra"
+)
+
+// TagAnalysis contains detailed information about a single tag
+type TagAnalysis struct {
+	Name         string    `json:"name"`
+	Status       string    `json:"status"` // stale, divergent, in_sync, or no_remote
+	Annotated    bool      `json:"annotated"`
+	Tagger       string    `json:"tagger,omitempty"` // Empty for lightweight tags
+	Date         string    `json:"date"`             // Tagger date, or commit date for lightweight tags
+	CreatedAt    time.Time `json:"created_at"`
+	Target       string    `json:"target"`                  // Commit the tag points to
+	Object       string    `json:"object"`                  // Tag object for annotated tags, the commit otherwise
+	OnRemote     bool      `json:"on_remote"`               // Exists on at least one compared remote
+	Divergent    bool      `json:"divergent"`               // Points to a different object on the remote
+	Remote       string    `json:"remote,omitempty"`        // Remote the tag diverges from
+	RemoteObject string    `json:"remote_object,omitempty"` // Object the remote tag points to
+	ProtectedBy  string    `json:"protected_by,omitempty"`  // pattern:<glob> from protected_tags
+}
+
+// TagReportSummary contains aggregated counts for the tag report
+type TagReportSummary struct {
+	StaleCount       int `json:"stale_count"`
+	DivergentCount   int `json:"divergent_count"`
+	InSyncCount      int `json:"in_sync_count"`
+	AnnotatedCount   int `json:"annotated_count"`
+	LightweightCount int `json:"lightweight_count"`
+	ProtectedCount   int `json:"protected_count"`
+}
+
+// TagAnalysisReport contains the complete tag analysis
+type TagAnalysisReport struct {
+	Repository   string           `json:"repository"`
+	AnalysisDate string           `json:"analysis_date"`
+	Remotes      []string         `json:"remotes"`
+	TotalTags    int              `json:"total_tags"`
+	Tags         []TagAnalysis    `json:"tags"`
+	Summary      TagReportSummary `json:"summary"`
+}
+
+var tagsReportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Generate tag analysis report without deleting",
+	Long: `Generate a detailed analysis report of all local tags.
+
+For every tag the report shows whether it is annotated or lightweight, the
+tagger and date, the commit it points to, whether it exists on the remote,
+and whether it diverges from the remote (exists there but points to a
+different object).
+
+Output formats available:
+  - text: Human-readable formatted report (default)
+  - json: Machine-readable JSON format
+  - csv: Spreadsheet-compatible CSV format
+  - ndjson: Candidate and summary events, see --output of the other commands`,
+	Example: `  # Generate a text report to stdout
+  git-gone tags report
+
+  # Generate a JSON report
+  git-gone tags report --output json
+
+  # Save a CSV report comparing against every remote
+  git-gone tags report --all-remotes --output csv --file tags.csv`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runTagsReport()
+	},
+}
+
+func init() {
+	tagsReportCmd.Flags().StringVarP(&reportOutputFormat, "output", "o", "text", "Report output format (text, json, csv, ndjson)")
+	tagsReportCmd.Flags().StringVar(&reportOutputFile, "file", "", "Write report to file instead of stdout")
+
+	tagsCmd.AddCommand(tagsReportCmd)
+}
+
+func runTagsReport() {
+	requireRepository()
+
+	// Progress goes to stderr so JSON and CSV on stdout stay machine-readable
+	fmt.Fprintf(os.Stderr, "%s  Analyzing tags...\n", tui.EmojiTag)
+	scope := analysisScope{repo: repo, protectedTags: cfg.ProtectedTags}
+	report, err := analyzeTags(scope, staleTagRemotes())
+	if err != nil {
+		fatalf("Failed to analyze tags: %v", err)
+	}
+	outputReport(report, reportOutputFormat, reportOutputFile)
+}
+
+// analyzeTags collects every local tag of the repository of scope and
+// compares it with the remotes
+func analyzeTags(scope analysisScope, remotes []string) (*TagAnalysisReport, error) {
+	r := scope.repo
+	report := &TagAnalysisReport{
+		Repository:   getRepositoryPath(r),
+		AnalysisDate: time.Now().Format("2006-01-02 15:04:05"),
+		Remotes:      remotes,
+		Tags:         []TagAnalysis{},
+	}
+	if report.Remotes == nil {
+		report.Remotes = []string{}
+	}
+
+	tags, err := r.ListTags()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get local tags: %w", err)
+	}
+	report.TotalTags = len(tags)
+
+	// Union of the tags on every compared remote
+	onRemote := make(map[string]bool)
+	for _, remote := range remotes {
+		objects, err := r.GetRemoteTagObjects(remote)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s  Warning: Failed to get tags from %s: %v\n", tui.EmojiWarning, remote, err)
+			continue
+		}
+		for name := range objects {
+			onRemote[name] = true
+		}
+	}
+	divergent := findDivergentTags(r, scope.protectedTags, remotes)
+
+	for _, tag := range tags {
+		analysis := TagAnalysis{
+			Name:      tag.Name,
+			Annotated: tag.IsAnnotated,
+			Tagger:    tag.Tagger,
+			Date:      "unknown",
+			CreatedAt: tag.CreatedAt,
+			Target:    tag.Target,
+			Object:    tag.SHA,
+			OnRemote:  onRemote[tag.Name],
+		}
+		if !tag.CreatedAt.IsZero() {
+			analysis.Date = tag.CreatedAt.Format("2006-01-02")
+		}
+		if pattern, protected := git.MatchProtectedPattern(tag.Name, scope.protectedTags); protected {
+			analysis.ProtectedBy = "pattern:" + pattern
+			report.Summary.ProtectedCount++
+		}
+
+		switch d, isDivergent := divergent[tag.Name]; {
+		case len(remotes) == 0:
+			analysis.Status = "no_remote"
+		case isDivergent:
+			analysis.Status = "divergent"
+			analysis.Divergent = true
+			analysis.Remote = d.Remote
+			analysis.RemoteObject = d.RemoteSHA
+			report.Summary.DivergentCount++
+		case !analysis.OnRemote:
+			analysis.Status = "stale"
+			report.Summary.StaleCount++
+		default:
+			analysis.Status = "in_sync"
+			report.Summary.InSyncCount++
+		}
+
+		if tag.IsAnnotated {
+			report.Summary.AnnotatedCount++
+		} else {
+			report.Summary.LightweightCount++
+		}
+		report.Tags = append(report.Tags, analysis)
+	}
+	return report, nil
+}
+
+// emitEvents emits a candidate event for every stale or divergent tag that is
+// not protected, and a summary
+func (report *TagAnalysisReport) emitEvents() {
+	summary := &events.Summary{Command: "tags report"}
+	for _, tag := range report.Tags {
+		if tag.ProtectedBy != "" {
+			continue
+		}
+		var c git.DeletionCandidate
+		switch tag.Status {
+		case "stale":
+			c = git.NewTagCandidate(tag.Name)
+		case "divergent":
+			c = git.NewDivergentTagCandidate(tag.Name)
+		default:
+			continue
+		}
+		c.SHA = tag.Object
+		emitCandidate(c)
+		summary.Candidates++
+	}
+	events.Emit(summary)
+}
+
+// renderText returns the human-readable tag report
+func (report *TagAnalysisReport) renderText() string {
+	var sb strings.Builder
+
+	sb.WriteString("============================================================\n")
+	sb.WriteString("               GIT-GONE TAG ANALYSIS REPORT\n")
+	sb.WriteString("============================================================\n")
+	sb.WriteString(fmt.Sprintf("Repository: %s\n", report.Repository))
+	sb.WriteString(fmt.Sprintf("Date: %s\n", report.AnalysisDate))
+	if len(report.Remotes) > 0 {
+		sb.WriteString(fmt.Sprintf("Remotes: %s\n", strings.Join(report.Remotes, ", ")))
+	} else {
+		sb.WriteString("Remotes: none (existence on remote not checked)\n")
+	}
+	sb.WriteString("\n")
+
+	sections := []struct {
+		status string
+		title  string
+	}{
+		{"divergent", "DIVERGENT (%d tags) - Point to a different object on the remote"},
+		{"stale", "STALE (%d tags) - Local only, not on remote"},
+		{"in_sync", "IN SYNC (%d tags)"},
+		{"no_remote", "LOCAL (%d tags)"},
+	}
+	for _, section := range sections {
+		var tags []TagAnalysis
+		for _, tag := range report.Tags {
+			if tag.Status == section.status {
+				tags = append(tags, tag)
+			}
+		}
+		if len(tags) == 0 {
+			continue
+		}
+
+		sb.WriteString("------------------------------------------------------------\n")
+		sb.WriteString(fmt.Sprintf(section.title+"\n", len(tags)))
+		sb.WriteString("------------------------------------------------------------\n")
+		for _, tag := range tags {
+			kind := "lightweight"
+			if tag.Annotated {
+				kind = "annotated by " + tag.Tagger
+			}
+			sb.WriteString(fmt.Sprintf("  * %s\n", tag.Name))
+			sb.WriteString(fmt.Sprintf("    Type: %s | Date: %s | Target: %s\n", kind, tag.Date, git.ShortSHA(tag.Target)))
+			if tag.Divergent {
+				sb.WriteString(fmt.Sprintf("    Local object: %s | %s object: %s\n", git.ShortSHA(tag.Object), tag.Remote, git.ShortSHA(tag.RemoteObject)))
+			}
+			if tag.ProtectedBy != "" {
+				sb.WriteString(fmt.Sprintf("    Protected by: %s\n", tag.ProtectedBy))
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	// Summary
+	sb.WriteString("============================================================\n")
+	sb.WriteString(fmt.Sprintf("SUMMARY: %d tags | %d stale | %d divergent | %d in sync\n",
+		report.TotalTags,
+		report.Summary.StaleCount,
+		report.Summary.DivergentCount,
+		report.Summary.InSyncCount))
+	sb.WriteString(fmt.Sprintf("         %d annotated | %d lightweight | %d protected\n",
+		report.Summary.AnnotatedCount,
+		report.Summary.LightweightCount,
+		report.Summary.ProtectedCount))
+	sb.WriteString("============================================================\n")
+
+	return sb.String()
+}
+
+// csvHeader returns the CSV column names of the tag report
+func (report *TagAnalysisReport) csvHeader() []string {
+	return []string{"Name", "Status", "Annotated", "Tagger", "Date", "Target", "Object", "On Remote", "Divergent", "Remote", "Remote Object", "Protected By"}
+}
+
+// csvRows returns one CSV row per tag
+func (report *TagAnalysisReport) csvRows() [][]string {
+	var rows [][]string
+	for _, tag := range report.Tags {
+		rows = append(rows, []string{
+			tag.Name,
+			tag.Status,
+			strconv.FormatBool(tag.Annotated),
+			tag.Tagger,
+			tag.Date,
+			tag.Target,
+			tag.Object,
+			strconv.FormatBool(tag.OnRemote),
+			strconv.FormatBool(tag.Divergent),
+			tag.Remote,
+			tag.RemoteObject,
+			tag.ProtectedBy,
+		})
+	}
+	return rows
+}
@@ -0,0 +1,289 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"git-gone/internal/events"
+	"git-gone/internal/git"
+	"git-gone/internal/tui"
+
+	"github.com/spf13/cobra"
+)
+
+// Trash-specific flags
+var trashOlderThan string
+
+var trashCmd = &cobra.Command{
+	Use:   "trash",
+	Short: "Manage backups of deleted branches and tags",
+	Long: `Manage backups of deleted branches and tags.
+
+Before git-gone deletes a branch or tag it copies the ref to a hidden
+namespace (refs/git-gone/trash/<timestamp>/...). The backup keeps the commits
+reachable, so "git gc" does not collect them and the deletion can be undone.
+
+Backups are kept until they are removed with "git-gone trash empty".`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Default behavior: show help
+		_ = cmd.Help()
+	},
+}
+
+var trashListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List backups of deleted branches and tags",
+	Example: `  # List all backups
+  git-gone trash list`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runTrashList()
+	},
+}
+
+var trashRestoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Restore deleted branches and tags from the trash",
+	Long: `Restore deleted branches and tags from the trash.
+
+Shows an interactive selector with the backups in the trash and recreates the
+chosen branches and tags at their backed-up commit. Existing refs are never
+overwritten.
+
+Interactive Controls:
+  ↑/↓         Navigate through the list
+  Tab         Toggle selection
+  Enter       Confirm selection
+  Esc         Cancel operation
+  Type        Filter by name`,
+	Example: `  # Choose backups to restore
+  git-gone trash restore
+
+  # Restore every backup
+  git-gone trash restore --all`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runTrashRestore()
+	},
+}
+
+var trashEmptyCmd = &cobra.Command{
+	Use:   "empty",
+	Short: "Permanently remove backups from the trash",
+	Long: `Permanently remove backups from the trash.
+
+Once a backup is removed its commits can be collected by "git gc" and the
+deletion can no longer be undone. Use --older-than to only remove old backups.`,
+	Example: `  # Remove backups older than 30 days
+  git-gone trash empty --older-than 30d
+
+  # Remove every backup without confirmation
+  git-gone trash empty --force`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runTrashEmpty()
+	},
+}
+
+func init() {
+	trashEmptyCmd.Flags().StringVar(&trashOlderThan, "older-than", "", "Only remove backups older than this age (e.g. 30d, 2w, 12h)")
+
+	trashCmd.AddCommand(trashListCmd)
+	trashCmd.AddCommand(trashRestoreCmd)
+	trashCmd.AddCommand(trashEmptyCmd)
+}
+
+// loadTrash checks the repository and returns the current trash entries
+func loadTrash() []git.TrashEntry {
+	requireRepository()
+
+	entries, err := repo.ListTrash()
+	if err != nil {
+		fatalf("Failed to list trash: %v", err)
+	}
+	return entries
+}
+
+// formatTrashEntry renders a trash entry for listings and the selector
+func formatTrashEntry(entry git.TrashEntry) string {
+	kind := "branch"
+	if entry.IsTag() {
+		kind = "tag"
+	} else if entry.IsRemoteBranch() {
+		kind = "remote branch"
+	}
+	return fmt.Sprintf("%s  %s %s  trashed %s",
+		entry.Name(),
+		kind,
+		git.ShortSHA(entry.SHA),
+		entry.TrashedAt.Format("2006-01-02 15:04"))
+}
+
+// trashEventKind returns the event kind of the ref a trash entry backs up
+func trashEventKind(entry git.TrashEntry) string {
+	if entry.IsTag() {
+		return events.KindTag
+	} else if entry.IsRemoteBranch() {
+		return events.KindRemoteBranch
+	}
+	return events.KindBranch
+}
+
+func runTrashList() {
+	entries := loadTrash()
+
+	if len(entries) == 0 {
+		fmt.Printf("%s Trash is empty.\n", tui.EmojiSuccess)
+		return
+	}
+
+	fmt.Printf("\n%s Found %d backup(s) in the trash:\n", tui.EmojiSearch, len(entries))
+	for _, entry := range entries {
+		fmt.Printf("   • %s\n", formatTrashEntry(entry))
+	}
+}
+
+func runTrashRestore() {
+	summary := &events.Summary{Command: "trash restore", DryRun: dryRun}
+	defer finish(summary)
+
+	entries := loadTrash()
+	for _, entry := range entries {
+		events.Emit(&events.Candidate{Kind: trashEventKind(entry), Name: entry.Name(), SHA: entry.SHA})
+	}
+	summary.Candidates = len(entries)
+
+	if len(entries) == 0 {
+		fmt.Printf("%s Trash is empty.\n", tui.EmojiSuccess)
+		return
+	}
+
+	labels := make([]string, len(entries))
+	entriesByLabel := make(map[string]git.TrashEntry)
+	for i, entry := range entries {
+		labels[i] = formatTrashEntry(entry)
+		entriesByLabel[labels[i]] = entry
+	}
+
+	// Select backups: use all if -a flag is set, otherwise use interactive fzf
+	var selected []string
+	if selectAll {
+		selected = labels
+	} else {
+		var err error
+		selected, err = selectItems(labels, "Select backups to restore > ")
+		if err != nil {
+			if err.Error() == "abort" {
+				fmt.Printf("\n%s Selection cancelled\n", tui.EmojiError)
+				emitCancelled(events.KindBackup)
+				summary.Cancelled = true
+				return
+			}
+			fatalf("Failed to select backups: %v", err)
+		}
+	}
+
+	refs := make([]string, len(selected))
+	for i, label := range selected {
+		refs[i] = entriesByLabel[label].Ref
+	}
+	emitSelection(events.KindBackup, refs)
+	summary.Selected = len(refs)
+
+	if len(selected) == 0 {
+		fmt.Printf("\n%s No backups selected for restore\n", tui.EmojiSuccess)
+		return
+	}
+
+	// In dry-run mode, show what would have been executed and stop here
+	if dryRun {
+		var operations []string
+		for _, label := range selected {
+			operations = append(operations, plannedTrashRestore(entriesByLabel[label])...)
+		}
+		printDryRun(operations)
+		return
+	}
+
+	restoredCount := 0
+	for _, label := range selected {
+		entry := entriesByLabel[label]
+		err := repo.RestoreFromTrash(entry)
+		if err != nil {
+			fmt.Printf("%s Failed to restore %s: %v\n", tui.EmojiError, entry.Original, err)
+		} else {
+			fmt.Printf("%s Restored %s (%s)\n", tui.EmojiSuccess, entry.Original, git.ShortSHA(entry.SHA))
+			restoredCount++
+		}
+		emitRestore(summary, events.Restore{Kind: trashEventKind(entry), Name: entry.Name()}, err)
+	}
+
+	fmt.Printf("\n%s Successfully restored %d ref(s)\n", tui.EmojiCelebrate, restoredCount)
+}
+
+func runTrashEmpty() {
+	olderThan, err := parseAge(trashOlderThan)
+	if err != nil {
+		fatalf("%v", err)
+	}
+
+	summary := &events.Summary{Command: "trash empty", DryRun: dryRun}
+	defer finish(summary)
+
+	entries := loadTrash()
+
+	// Collect what would be removed before asking for confirmation
+	cutoff := time.Now().Add(-olderThan)
+	var expired []git.TrashEntry
+	for _, entry := range entries {
+		if !entry.TrashedAt.After(cutoff) {
+			expired = append(expired, entry)
+		}
+	}
+
+	for _, entry := range expired {
+		events.Emit(&events.Candidate{Kind: events.KindBackup, Name: entry.Ref, SHA: entry.SHA})
+	}
+	summary.Candidates = len(expired)
+	summary.Selected = len(expired)
+
+	if len(expired) == 0 {
+		fmt.Printf("%s Nothing to remove from the trash.\n", tui.EmojiSuccess)
+		return
+	}
+
+	fmt.Printf("\n%s The following %d backup(s) will be permanently removed:\n", tui.EmojiWarning, len(expired))
+	for _, entry := range expired {
+		fmt.Printf("  • %s\n", formatTrashEntry(entry))
+	}
+
+	// Confirm removal (unless --force is used)
+	if !forceDelete {
+		if !confirmDeletion("Are you sure you want to empty these backups?") {
+			fmt.Printf("%s Emptying trash cancelled\n", tui.EmojiError)
+			summary.Cancelled = true
+			return
+		}
+	}
+
+	// In dry-run mode, show what would have been executed and stop here
+	if dryRun {
+		var operations []string
+		for _, entry := range expired {
+			operations = append(operations, "git update-ref -d "+entry.Ref)
+		}
+		printDryRun(operations)
+		return
+	}
+
+	// Remove exactly the confirmed backups; the cutoff is not computed again
+	removed := 0
+	for _, entry := range expired {
+		err := repo.DropTrashRef(entry.Ref)
+		if err != nil {
+			fmt.Printf("%s Failed to remove %s: %v\n", tui.EmojiError, entry.Ref, err)
+		} else {
+			removed++
+		}
+		emitDeletion(summary, events.Deletion{Kind: events.KindBackup, Name: entry.Ref}, err)
+	}
+
+	fmt.Printf("\n%s Removed %d backup(s) from the trash\n", tui.EmojiCelebrate, removed)
+}
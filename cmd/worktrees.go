@@ -0,0 +1,219 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+
+	"git-gone/internal/events"
+	"git-gone/internal/git"
+	"git-gone/internal/tui"
+
+	"github.com/spf13/cobra"
+)
+
+var worktreesCmd = &cobra.Command{
+	Use:   "worktrees",
+	Short: "Remove worktrees of merged branches and prune missing worktrees",
+	Long: `Remove worktrees of merged branches and prune missing worktrees.
+
+A branch checked out in a worktree cannot be deleted, so git-gone protects it.
+This command finds linked worktrees that are no longer needed:
+  - worktrees whose branch is merged into an integration target
+  - worktrees whose branch's remote tracking branch was deleted
+  - worktrees whose directory no longer exists
+
+The chosen worktrees are removed with "git worktree remove" (missing ones are
+pruned). Worktrees with uncommitted changes and locked worktrees are never
+removed. Afterwards their branches can be cleaned up with "git gone".
+
+Interactive Controls:
+  ↑/↓         Navigate through the list
+  Tab         Toggle selection
+  Enter       Confirm selection
+  Esc         Cancel operation
+  Type        Filter by path or branch`,
+	Example: `  # Choose worktrees to remove
+  git-gone worktrees
+
+  # Show the git operations without removing anything
+  git-gone worktrees --all --dry-run`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runWorktrees()
+	},
+}
+
+// worktreeCandidate is a worktree offered for removal
+type worktreeCandidate struct {
+	worktree git.Worktree
+	reason   string
+	code     string // missing, gone_remote or merged, the reason in events
+}
+
+// formatWorktreeCandidate renders a worktree candidate for listings and the selector
+func formatWorktreeCandidate(c worktreeCandidate) string {
+	branch := c.worktree.Branch
+	if branch == "" {
+		branch = "detached"
+	}
+	return fmt.Sprintf("%s  [%s]  %s", c.worktree.Path, branch, c.reason)
+}
+
+// findWorktreeCandidates returns linked worktrees whose branch is merged or
+// gone, or whose directory is missing. Locked worktrees are reported and skipped.
+func findWorktreeCandidates(worktrees []git.Worktree) []worktreeCandidate {
+	defaultBranch, err := repo.GetDefaultBranch(resolveRemote())
+	if err != nil {
+		fatalf("Failed to get default branch: %v", err)
+	}
+	targets := resolveTargets(defaultBranch)
+	targetSet := make(map[string]bool)
+	for _, target := range targets {
+		targetSet[target] = true
+	}
+
+	mergedTargets, err := repo.GetMergedTargets(targets)
+	if err != nil {
+		fmt.Printf("%s  Warning: Failed to get merged branches: %v\n", tui.EmojiWarning, err)
+	}
+	goneBranches := make(map[string]bool)
+	if gone, err := repo.GetGoneBranches(); err == nil {
+		for _, branch := range gone {
+			goneBranches[branch] = true
+		}
+	}
+
+	currentRoot, _ := repo.GetRepositoryRoot()
+	var candidates []worktreeCandidate
+	for _, wt := range worktrees {
+		if wt.IsMain || wt.Bare || wt.Path == currentRoot {
+			continue
+		}
+
+		c := worktreeCandidate{worktree: wt}
+		switch {
+		case wt.Missing:
+			c.reason, c.code = "directory missing", "missing"
+		case wt.Branch == "" || targetSet[wt.Branch]:
+			continue
+		case goneBranches[wt.Branch]:
+			c.reason, c.code = "remote branch deleted", git.ReasonGoneRemote.String()
+		case mergedTargets[wt.Branch] != "":
+			c.reason, c.code = "merged into "+mergedTargets[wt.Branch], git.ReasonMerged.String()
+		default:
+			continue
+		}
+
+		if wt.Locked {
+			fmt.Printf("🔒 Skipping locked worktree %s (%s)\n", wt.Path, c.reason)
+			continue
+		}
+		candidates = append(candidates, c)
+	}
+	return candidates
+}
+
+func runWorktrees() {
+	summary := &events.Summary{Command: "worktrees", DryRun: dryRun}
+	defer finish(summary)
+
+	requireRepository()
+
+	worktrees, err := repo.ListWorktrees()
+	if err != nil {
+		fatalf("Failed to list worktrees: %v", err)
+	}
+
+	candidates := findWorktreeCandidates(worktrees)
+	for _, c := range candidates {
+		events.Emit(&events.Candidate{Kind: events.KindWorktree, Name: c.worktree.Path, Reason: c.code, Risk: "safe"})
+	}
+	summary.Candidates = len(candidates)
+	if len(candidates) == 0 {
+		fmt.Printf("%s No worktrees to remove.\n", tui.EmojiSuccess)
+		return
+	}
+
+	labels := make([]string, len(candidates))
+	candidatesByLabel := make(map[string]worktreeCandidate)
+	for i, c := range candidates {
+		labels[i] = formatWorktreeCandidate(c)
+		candidatesByLabel[labels[i]] = c
+	}
+	sort.Strings(labels)
+
+	fmt.Printf("\n%s Found %d removable worktree(s):\n", tui.EmojiSearch, len(labels))
+	for _, label := range labels {
+		fmt.Printf("   • %s\n", label)
+	}
+
+	// Select worktrees: use all if -a flag is set, otherwise use interactive fzf
+	var selected []string
+	if selectAll {
+		selected = labels
+	} else {
+		selected, err = selectItems(labels, "Select worktrees to remove > ")
+		if err != nil {
+			if err.Error() == "abort" {
+				fmt.Printf("\n%s Selection cancelled\n", tui.EmojiError)
+				emitCancelled(events.KindWorktree)
+				summary.Cancelled = true
+				return
+			}
+			fatalf("Failed to select worktrees: %v", err)
+		}
+	}
+
+	paths := make([]string, len(selected))
+	for i, label := range selected {
+		paths[i] = candidatesByLabel[label].worktree.Path
+	}
+	emitSelection(events.KindWorktree, paths)
+	summary.Selected = len(paths)
+
+	if len(selected) == 0 {
+		fmt.Printf("\n%s No worktrees selected for removal\n", tui.EmojiSuccess)
+		return
+	}
+
+	fmt.Printf("\n%s The following worktrees will be removed:\n", tui.EmojiWarning)
+	for _, label := range selected {
+		fmt.Printf("  • %s\n", label)
+	}
+
+	// Confirm removal (unless --force is used)
+	if !forceDelete {
+		if !confirmDeletion("Are you sure you want to remove these worktrees?") {
+			fmt.Printf("%s Removal cancelled\n", tui.EmojiError)
+			summary.Cancelled = true
+			return
+		}
+	}
+
+	// In dry-run mode, show what would have been executed and stop here
+	if dryRun {
+		var operations []string
+		for _, label := range selected {
+			operations = append(operations, "git worktree remove "+candidatesByLabel[label].worktree.Path)
+		}
+		printDryRun(operations)
+		return
+	}
+
+	removedCount := 0
+	for _, label := range selected {
+		wt := candidatesByLabel[label].worktree
+		err := repo.RemoveWorktree(wt.Path)
+		if err != nil {
+			fmt.Printf("%s Failed to remove worktree %s: %v\n", tui.EmojiError, wt.Path, err)
+		} else {
+			fmt.Printf("%s Removed worktree: %s\n", tui.EmojiSuccess, wt.Path)
+			removedCount++
+		}
+		emitDeletion(summary, events.Deletion{Kind: events.KindWorktree, Name: wt.Path}, err)
+	}
+
+	fmt.Printf("\n%s Successfully removed %d worktree(s)\n", tui.EmojiCelebrate, removedCount)
+	if removedCount > 0 {
+		fmt.Println("   Run 'git gone' to delete their branches.")
+	}
+}
@@ -0,0 +1,386 @@
+// Package config loads git-gone settings from config files and git config.
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"git-gone/internal/git"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the name of the per-repository config file at the repository root.
+const FileName = ".git-gone.yaml"
+
+// GitConfigPrefix is the section used for settings stored in git config.
+const GitConfigPrefix = "gitgone."
+
+// Defaults holds default values for command-line flags. Unset fields leave
+// the built-in flag default untouched; flags given on the command line always win.
+type Defaults struct {
+	Force    *bool   `yaml:"force"`
+	All      *bool   `yaml:"all"`
+	Unmerged *bool   `yaml:"unmerged"`
+	DryRun   *bool   `yaml:"dry_run"`
+	Atomic   *bool   `yaml:"atomic"`
+	Output   *string `yaml:"output"`
+}
+
+// TagRetention holds the retention rules of "git-gone tags prune". Unset
+// fields leave the flag defaults untouched.
+type TagRetention struct {
+	KeepPatches     *int    `yaml:"keep_patches"`
+	DropPrereleases *bool   `yaml:"drop_prereleases"`
+	KeepNightlies   *int    `yaml:"keep_nightlies"`
+	NightlyPattern  *string `yaml:"nightly_pattern"`
+}
+
+// Config holds git-gone settings.
+type Config struct {
+	// Protected lists glob patterns (e.g. release/*) of branches that are never deleted.
+	Protected []string `yaml:"protected"`
+	// ProtectedTags lists glob patterns of tags that are never deleted.
+	ProtectedTags []string `yaml:"protected_tags"`
+	// Targets lists integration branches (names or globs) that count for merge
+	// detection in addition to the default branch.
+	Targets []string `yaml:"targets"`
+	// Authors lists author names or emails (globs allowed) whose branches
+	// "git-gone remote-branches" may delete from the remote.
+	Authors []string `yaml:"authors"`
+	// Remote is the remote to compare against instead of origin.
+	Remote string `yaml:"remote"`
+	// Backend selects how the repository is read: exec (default) or native.
+	Backend string `yaml:"backend"`
+	// Defaults holds default flag values.
+	Defaults Defaults `yaml:"defaults"`
+	// TagRetention holds the rules applied by "git-gone tags prune".
+	TagRetention TagRetention `yaml:"tag_retention"`
+	// Repos holds per-repository overrides, keyed by repository path or glob.
+	// Only read from the global config file.
+	Repos map[string]Config `yaml:"repos"`
+
+	// Sources lists the files and git config scopes that were loaded, in order.
+	Sources []string `yaml:"-"`
+	// Warnings lists settings that were ignored while loading.
+	Warnings []string `yaml:"-"`
+}
+
+// GlobalPath returns the path of the global config file,
+// $XDG_CONFIG_HOME/git-gone/config.yaml (or ~/.config/git-gone/config.yaml).
+func GlobalPath() string {
+	base := os.Getenv("XDG_CONFIG_HOME")
+	if base == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		base = filepath.Join(home, ".config")
+	}
+	return filepath.Join(base, "git-gone", "config.yaml")
+}
+
+// Load builds the effective configuration for a repository. Sources are
+// applied from lowest to highest precedence:
+//
+//  1. the global config file
+//  2. the matching entries under "repos" in the global config file, the most
+//     specific last
+//  3. .git-gone.yaml at the repository root
+//  4. gitgone.* keys in git config
+//
+// Protected patterns, targets and authors accumulate across sources; the
+// remote and defaults are overridden. The force, all and unmerged defaults are
+// ignored in .git-gone.yaml, see dropDestructiveDefaults.
+// A nil repo, or one that is not a git repository, loads the global config
+// file only.
+func Load(repo *git.Repository) (*Config, error) {
+	cfg := &Config{}
+	var repoRoot string
+	if repo != nil {
+		repoRoot, _ = repo.GetRepositoryRoot()
+	}
+
+	if path := GlobalPath(); path != "" {
+		global, err := readFile(path)
+		if err != nil {
+			return cfg, err
+		}
+		if global != nil {
+			cfg.merge(global)
+			cfg.Sources = append(cfg.Sources, path)
+			for _, pattern := range matchingRepos(global.Repos, repoRoot) {
+				override := global.Repos[pattern]
+				cfg.merge(&override)
+				cfg.Sources = append(cfg.Sources, fmt.Sprintf("%s (repos.%s)", path, pattern))
+			}
+		}
+	}
+
+	if repoRoot == "" {
+		return cfg, nil
+	}
+
+	path := filepath.Join(repoRoot, FileName)
+	local, err := readFile(path)
+	if err != nil {
+		return cfg, err
+	}
+	if local != nil {
+		cfg.Warnings = append(cfg.Warnings, local.dropDestructiveDefaults(path)...)
+		cfg.merge(local)
+		cfg.Sources = append(cfg.Sources, path)
+	}
+
+	fromGit, err := fromGitConfig(repo.GetConfigEntries(GitConfigPrefix))
+	if err != nil {
+		return cfg, err
+	}
+	if fromGit != nil {
+		cfg.merge(fromGit)
+		cfg.Sources = append(cfg.Sources, "git config ("+strings.TrimSuffix(GitConfigPrefix, ".")+".*)")
+	}
+
+	return cfg, nil
+}
+
+// readFile parses a YAML config file. A missing file is not an error.
+func readFile(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// fromGitConfig builds a Config from gitgone.* entries. Git lowercases
+// variable names, so keys are matched case-insensitively.
+func fromGitConfig(entries map[string][]string) (*Config, error) {
+	if len(entries) == 0 {
+		return nil, nil
+	}
+
+	cfg := &Config{}
+	for key, values := range entries {
+		last := values[len(values)-1]
+		switch strings.ToLower(strings.TrimPrefix(key, GitConfigPrefix)) {
+		case "protected":
+			cfg.Protected = append(cfg.Protected, values...)
+		case "protectedtags":
+			cfg.ProtectedTags = append(cfg.ProtectedTags, values...)
+		case "target":
+			cfg.Targets = append(cfg.Targets, values...)
+		case "author":
+			cfg.Authors = append(cfg.Authors, values...)
+		case "remote":
+			cfg.Remote = last
+		case "backend":
+			cfg.Backend = last
+		case "force":
+			b, err := parseBool(key, last)
+			if err != nil {
+				return nil, err
+			}
+			cfg.Defaults.Force = &b
+		case "all":
+			b, err := parseBool(key, last)
+			if err != nil {
+				return nil, err
+			}
+			cfg.Defaults.All = &b
+		case "unmerged":
+			b, err := parseBool(key, last)
+			if err != nil {
+				return nil, err
+			}
+			cfg.Defaults.Unmerged = &b
+		case "dryrun":
+			b, err := parseBool(key, last)
+			if err != nil {
+				return nil, err
+			}
+			cfg.Defaults.DryRun = &b
+		case "atomic":
+			b, err := parseBool(key, last)
+			if err != nil {
+				return nil, err
+			}
+			cfg.Defaults.Atomic = &b
+		case "output":
+			cfg.Defaults.Output = &last
+		case "keeppatches":
+			n, err := parseInt(key, last)
+			if err != nil {
+				return nil, err
+			}
+			cfg.TagRetention.KeepPatches = &n
+		case "dropprereleases":
+			b, err := parseBool(key, last)
+			if err != nil {
+				return nil, err
+			}
+			cfg.TagRetention.DropPrereleases = &b
+		case "keepnightlies":
+			n, err := parseInt(key, last)
+			if err != nil {
+				return nil, err
+			}
+			cfg.TagRetention.KeepNightlies = &n
+		case "nightlypattern":
+			cfg.TagRetention.NightlyPattern = &last
+		}
+	}
+	return cfg, nil
+}
+
+// parseBool accepts the boolean spellings understood by git config.
+func parseBool(key, value string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "true", "yes", "on", "1":
+		return true, nil
+	case "false", "no", "off", "0", "":
+		return false, nil
+	default:
+		return false, fmt.Errorf("invalid boolean value %q for %s", value, key)
+	}
+}
+
+// parseInt parses a non-negative integer setting.
+func parseInt(key, value string) (int, error) {
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid number %q for %s", value, key)
+	}
+	return n, nil
+}
+
+// dropDestructiveDefaults clears the defaults that make git-gone delete more,
+// or without asking: force, all and unmerged. A file committed to a repository
+// must not turn them on for everyone who clones it, so they are only read from
+// the global config file and git config. It returns a warning per ignored
+// setting.
+func (c *Config) dropDestructiveDefaults(source string) []string {
+	var warnings []string
+	for _, d := range []struct {
+		name  string
+		value **bool
+	}{
+		{"force", &c.Defaults.Force},
+		{"all", &c.Defaults.All},
+		{"unmerged", &c.Defaults.Unmerged},
+	} {
+		if *d.value != nil {
+			warnings = append(warnings, fmt.Sprintf("%s: defaults.%s is ignored, set it in %s or git config (%s%s)",
+				source, d.name, GlobalPath(), GitConfigPrefix, d.name))
+			*d.value = nil
+		}
+	}
+	return warnings
+}
+
+// merge applies other on top of c.
+func (c *Config) merge(other *Config) {
+	c.Protected = append(c.Protected, other.Protected...)
+	c.ProtectedTags = append(c.ProtectedTags, other.ProtectedTags...)
+	c.Targets = append(c.Targets, other.Targets...)
+	c.Authors = append(c.Authors, other.Authors...)
+	if other.Remote != "" {
+		c.Remote = other.Remote
+	}
+	if other.Backend != "" {
+		c.Backend = other.Backend
+	}
+
+	if other.Defaults.Force != nil {
+		c.Defaults.Force = other.Defaults.Force
+	}
+	if other.Defaults.All != nil {
+		c.Defaults.All = other.Defaults.All
+	}
+	if other.Defaults.Unmerged != nil {
+		c.Defaults.Unmerged = other.Defaults.Unmerged
+	}
+	if other.Defaults.DryRun != nil {
+		c.Defaults.DryRun = other.Defaults.DryRun
+	}
+	if other.Defaults.Atomic != nil {
+		c.Defaults.Atomic = other.Defaults.Atomic
+	}
+	if other.Defaults.Output != nil {
+		c.Defaults.Output = other.Defaults.Output
+	}
+
+	if other.TagRetention.KeepPatches != nil {
+		c.TagRetention.KeepPatches = other.TagRetention.KeepPatches
+	}
+	if other.TagRetention.DropPrereleases != nil {
+		c.TagRetention.DropPrereleases = other.TagRetention.DropPrereleases
+	}
+	if other.TagRetention.KeepNightlies != nil {
+		c.TagRetention.KeepNightlies = other.TagRetention.KeepNightlies
+	}
+	if other.TagRetention.NightlyPattern != nil {
+		c.TagRetention.NightlyPattern = other.TagRetention.NightlyPattern
+	}
+}
+
+// matchingRepos returns the "repos" keys matching repoRoot, from the least to
+// the most specific, so that the most specific entry wins: glob patterns come
+// before exact paths, shorter keys before longer ones, and keys of equal
+// length are in lexical order.
+func matchingRepos(repos map[string]Config, repoRoot string) []string {
+	if repoRoot == "" {
+		return nil
+	}
+	var matched []string
+	for pattern := range repos {
+		if matchRepo(pattern, repoRoot) {
+			matched = append(matched, pattern)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		a, b := matched[i], matched[j]
+		if globA, globB := isGlob(a), isGlob(b); globA != globB {
+			return globA
+		}
+		if len(a) != len(b) {
+			return len(a) < len(b)
+		}
+		return a < b
+	})
+	return matched
+}
+
+// isGlob reports whether a "repos" key is a glob pattern rather than a path
+func isGlob(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?[")
+}
+
+// matchRepo reports whether a "repos" key matches the repository root. Keys
+// may be absolute paths, paths starting with ~/ or glob patterns.
+func matchRepo(pattern, repoRoot string) bool {
+	if strings.HasPrefix(pattern, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			pattern = filepath.Join(home, pattern[2:])
+		}
+	}
+	pattern = filepath.Clean(pattern)
+	repoRoot = filepath.Clean(repoRoot)
+	if pattern == repoRoot {
+		return true
+	}
+	matched, err := filepath.Match(pattern, repoRoot)
+	return err == nil && matched
+}
@@ -0,0 +1,193 @@
+// Package events defines the machine-readable event stream written with
+// --output ndjson: one JSON object per line, in the order things happen.
+//
+// Every event has a "type" naming the struct below that describes its fields
+// and a "time". Fields are only ever added to these structs, never renamed or
+// removed, so consumers can rely on them.
+package events
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// Event types, the value of the "type" field of each event.
+const (
+	TypeFetchStarted  = "fetch_started"
+	TypeFetchFinished = "fetch_finished"
+	TypeCandidate     = "candidate"
+	TypeSelection     = "selection"
+	TypeDeletion      = "deletion"
+	TypeRestore       = "restore"
+	TypeDryRun        = "dry_run"
+	TypeSummary       = "summary"
+	TypeError         = "error"
+)
+
+// Kinds of items, the value of the "kind" field.
+const (
+	KindBranch       = "branch"
+	KindTag          = "tag"
+	KindRemoteBranch = "remote_branch"
+	KindWorktree     = "worktree"
+	KindStash        = "stash"
+	KindBackup       = "backup"
+)
+
+// Header holds the fields common to every event.
+type Header struct {
+	Type string    `json:"type"`
+	Time time.Time `json:"time"`
+}
+
+func (h *Header) header() *Header { return h }
+
+// Event is one of the event structs of this package.
+type Event interface {
+	header() *Header
+	eventType() string
+}
+
+// FetchStarted is emitted before remote references are updated.
+type FetchStarted struct {
+	Header
+	// Remotes being fetched; empty means every remote.
+	Remotes []string `json:"remotes,omitempty"`
+}
+
+// FetchFinished is emitted once remote references are updated. A failed
+// update is not fatal: the command goes on with the references it has.
+type FetchFinished struct {
+	Header
+	Error string `json:"error,omitempty"`
+}
+
+// Candidate is emitted for every item a command offers to delete or restore,
+// before anything is selected.
+type Candidate struct {
+	Header
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+	// Reason the item is offered, e.g. merged, gone_remote, squash_merged,
+	// unmerged, stale_tag or divergent_tag for branches and tags.
+	Reason string `json:"reason,omitempty"`
+	// Risk is "safe" or "dangerous"; dangerous items need typing DELETE.
+	// Stashes whose branch is neither merged nor deleted are "unmerged".
+	Risk string `json:"risk,omitempty"`
+	// SHA is the commit the item points to, when known.
+	SHA string `json:"sha,omitempty"`
+	// Repository is set by scan, which reports several repositories.
+	Repository string `json:"repository,omitempty"`
+}
+
+// Selection is emitted once the items to act on have been chosen, in the
+// selector or with --all. Scan emits one per repository.
+type Selection struct {
+	Header
+	Kind       string   `json:"kind"`
+	Selected   []string `json:"selected"`
+	Cancelled  bool     `json:"cancelled,omitempty"`
+	Repository string   `json:"repository,omitempty"`
+}
+
+// Deletion is emitted for every item a command tried to delete, drop or
+// remove. Error is empty if it succeeded.
+type Deletion struct {
+	Header
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	Repository string `json:"repository,omitempty"`
+	// Remote the item is deleted from; empty for local deletions.
+	Remote string `json:"remote,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Restore is emitted for every item a command tried to restore. Error is
+// empty if it succeeded.
+type Restore struct {
+	Header
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+	// Remote is set when the item was also pushed back to this remote.
+	Remote string `json:"remote,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// DryRun is emitted with --dry-run instead of deletions, listing the git
+// operations that would have run.
+type DryRun struct {
+	Header
+	Operations []string `json:"operations"`
+}
+
+// Summary is the last event of a command that completed.
+type Summary struct {
+	Header
+	Command    string `json:"command"`
+	Candidates int    `json:"candidates"`
+	Selected   int    `json:"selected"`
+	Succeeded  int    `json:"succeeded"`
+	Failed     int    `json:"failed"`
+	// Cancelled is set when the selection or a confirmation was declined.
+	Cancelled bool `json:"cancelled,omitempty"`
+	DryRun    bool `json:"dry_run,omitempty"`
+}
+
+// Error is emitted when a command stops because of an error; no summary
+// follows. Scan also emits one, with the repository, for every repository it
+// could not analyze, and goes on with the others.
+type Error struct {
+	Header
+	Message    string `json:"message"`
+	Repository string `json:"repository,omitempty"`
+}
+
+func (*FetchStarted) eventType() string  { return TypeFetchStarted }
+func (*FetchFinished) eventType() string { return TypeFetchFinished }
+func (*Candidate) eventType() string     { return TypeCandidate }
+func (*Selection) eventType() string     { return TypeSelection }
+func (*Deletion) eventType() string      { return TypeDeletion }
+func (*Restore) eventType() string       { return TypeRestore }
+func (*DryRun) eventType() string        { return TypeDryRun }
+func (*Summary) eventType() string       { return TypeSummary }
+func (*Error) eventType() string         { return TypeError }
+
+var (
+	mu  sync.Mutex
+	out io.Writer
+)
+
+// Enable writes every event emitted from now on to w. A nil w disables the
+// stream again.
+func Enable(w io.Writer) {
+	mu.Lock()
+	defer mu.Unlock()
+	out = w
+}
+
+// Enabled reports whether events are being written.
+func Enabled() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return out != nil
+}
+
+// Emit writes e as one line, setting its type and time. It does nothing
+// unless the stream is enabled.
+func Emit(e Event) {
+	mu.Lock()
+	defer mu.Unlock()
+	if out == nil {
+		return
+	}
+	h := e.header()
+	h.Type = e.eventType()
+	h.Time = time.Now().UTC()
+	line, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	_, _ = out.Write(append(line, '\n'))
+}
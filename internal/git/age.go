@@ -0,0 +1,58 @@
+package git
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GetBranchCommitDates returns the committer date of the tip commit of every
+// local branch, keyed by branch name.
+func (repo *Repository) GetBranchCommitDates() (map[string]time.Time, error) {
+	if n := repo.nativeBackend(); n != nil {
+		if dates, err := n.branchCommitDates(); err == nil {
+			return dates, nil
+		}
+	}
+
+	output, err := repo.gitOutput("for-each-ref", "--format=%(refname:lstrip=2)%09%(committerdate:unix)", "refs/heads/")
+	if err != nil {
+		return nil, err
+	}
+
+	dates := make(map[string]time.Time)
+	for _, line := range strings.Split(string(output), "\n") {
+		name, timestamp, ok := strings.Cut(strings.TrimSpace(line), "\t")
+		if !ok {
+			continue
+		}
+		seconds, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			continue
+		}
+		dates[name] = time.Unix(seconds, 0)
+	}
+	return dates, nil
+}
+
+// IsOlderThan reports whether date lies more than age before now. A zero age
+// or an unknown (zero) date never matches.
+func IsOlderThan(date time.Time, age time.Duration, now time.Time) bool {
+	if age <= 0 || date.IsZero() {
+		return false
+	}
+	return date.Before(now.Add(-age))
+}
+
+// SortByCommitDate sorts branches by the date of their last commit, oldest
+// first. Branches with an unknown date come first; ties are sorted by name.
+func SortByCommitDate(branches []string, dates map[string]time.Time) {
+	sort.SliceStable(branches, func(i, j int) bool {
+		di, dj := dates[branches[i]], dates[branches[j]]
+		if !di.Equal(dj) {
+			return di.Before(dj)
+		}
+		return branches[i] < branches[j]
+	})
+}
@@ -2,9 +2,9 @@ package git
 
 import (
 	"fmt"
-	"os"
-	"os/exec"
+	"path"
 	"strings"
+	"time"
 )
 
 // RemoteStatus represents the tracking status of a branch.
@@ -27,11 +27,27 @@ type Branch struct {
 	IsMerged     bool
 	RemoteStatus RemoteStatus
 	RemoteName   string
+	// ProtectedPattern is the configured pattern protecting the branch, if any.
+	ProtectedPattern string
+	// WorktreePath is the path of another worktree that has the branch checked out.
+	WorktreePath string
 }
 
 // IsProtected returns true if the branch cannot be deleted.
 func (b *Branch) IsProtected() bool {
-	return b.IsCurrent || b.IsDefault
+	return b.IsCurrent || b.IsDefault || b.ProtectedPattern != "" || b.WorktreePath != ""
+}
+
+// MatchProtectedPattern returns the first pattern matching name. Patterns use
+// path.Match syntax, so "release/*" matches "release/1.0" but not
+// "release/1.0/hotfix".
+func MatchProtectedPattern(name string, patterns []string) (string, bool) {
+	for _, pattern := range patterns {
+		if matched, err := path.Match(pattern, name); err == nil && matched {
+			return pattern, true
+		}
+	}
+	return "", false
 }
 
 // IsSafeToDelete returns true if the branch can be safely deleted.
@@ -40,10 +56,16 @@ func (b *Branch) IsSafeToDelete() bool {
 }
 
 // GetMergedBranches returns branches that have been merged into the default branch.
-func GetMergedBranches(defaultBranch string) ([]string, error) {
-	cmd := exec.Command("git", "branch", "--merged", defaultBranch)
-	cmd.Env = append(os.Environ(), "LC_ALL=C")
-	output, err := cmd.Output()
+func (repo *Repository) GetMergedBranches(defaultBranch string) ([]string, error) {
+	if n := repo.nativeBackend(); n != nil {
+		if branches, err := n.mergedBranches(defaultBranch); err == nil {
+			return branches, nil
+		}
+	}
+
+	// Use --format so branch names are not decorated with "*" (current
+	// branch) or "+" (checked out in another worktree)
+	output, err := repo.gitOutput("branch", "--merged", defaultBranch, "--format", "%(refname:short)")
 	if err != nil {
 		return nil, err
 	}
@@ -51,8 +73,7 @@ func GetMergedBranches(defaultBranch string) ([]string, error) {
 	lines := strings.Split(string(output), "\n")
 	var branches []string
 	for _, line := range lines {
-		branch := strings.TrimSpace(strings.TrimPrefix(line, "*"))
-		branch = strings.TrimSpace(branch)
+		branch := strings.TrimSpace(line)
 		if branch != "" {
 			branches = append(branches, branch)
 		}
@@ -61,10 +82,14 @@ func GetMergedBranches(defaultBranch string) ([]string, error) {
 }
 
 // GetGoneBranches returns branches whose remote tracking branch has been deleted.
-func GetGoneBranches() ([]string, error) {
-	cmd := exec.Command("git", "branch", "--format", "%(refname:short) %(upstream:track)")
-	cmd.Env = append(os.Environ(), "LC_ALL=C")
-	output, err := cmd.Output()
+func (repo *Repository) GetGoneBranches() ([]string, error) {
+	if n := repo.nativeBackend(); n != nil {
+		if branches, err := n.goneBranches(); err == nil {
+			return branches, nil
+		}
+	}
+
+	output, err := repo.gitOutput("branch", "--format", "%(refname:short) %(upstream:track)")
 	if err != nil {
 		return nil, err
 	}
@@ -82,11 +107,38 @@ func GetGoneBranches() ([]string, error) {
 	return branches, nil
 }
 
+// GetRemoteStatus returns the tracking status of a branch: NoTracking if it
+// has no remote configured, TrackingGone if its remote tracking branch was
+// deleted, TrackingActive otherwise.
+func (repo *Repository) GetRemoteStatus(name string) RemoteStatus {
+	if n := repo.nativeBackend(); n != nil {
+		return n.remoteStatus(name)
+	}
+
+	output, err := repo.gitOutput("config", "--get", "branch."+name+".remote")
+	if err != nil || strings.TrimSpace(string(output)) == "" {
+		return NoTracking
+	}
+
+	output, err = repo.gitOutput("branch", "--format", "%(upstream:track)", "--list", name)
+	if err != nil {
+		return NoTracking
+	}
+	if strings.Contains(string(output), "[gone]") {
+		return TrackingGone
+	}
+	return TrackingActive
+}
+
 // GetAllLocalBranches returns all local branch names.
-func GetAllLocalBranches() ([]string, error) {
-	cmd := exec.Command("git", "branch", "--format", "%(refname:short)")
-	cmd.Env = append(os.Environ(), "LC_ALL=C")
-	output, err := cmd.Output()
+func (repo *Repository) GetAllLocalBranches() ([]string, error) {
+	if n := repo.nativeBackend(); n != nil {
+		if branches, err := n.localBranches(); err == nil {
+			return branches, nil
+		}
+	}
+
+	output, err := repo.gitOutput("branch", "--format", "%(refname:short)")
 	if err != nil {
 		return nil, err
 	}
@@ -102,62 +154,238 @@ func GetAllLocalBranches() ([]string, error) {
 	return branches, nil
 }
 
-// FilterProtectedBranches removes the default and current branches from the list.
-func FilterProtectedBranches(branches []string, defaultBranch, currentBranch string) []string {
+// FilterProtectedBranches removes the default and current branches, and any
+// branch matching one of the protected patterns, from the list.
+func FilterProtectedBranches(branches []string, defaultBranch, currentBranch string, patterns ...string) []string {
 	var filtered []string
 	for _, branch := range branches {
-		if branch != defaultBranch && branch != currentBranch && branch != "" {
-			filtered = append(filtered, branch)
+		if branch == defaultBranch || branch == currentBranch || branch == "" {
+			continue
+		}
+		if _, protected := MatchProtectedPattern(branch, patterns); protected {
+			continue
 		}
+		filtered = append(filtered, branch)
 	}
 	return filtered
 }
 
+// IsFullyMerged reports whether a safe delete (git branch -d) would accept the
+// branch: it must be merged into its upstream, or into HEAD if it has none.
+func (repo *Repository) IsFullyMerged(name string) bool {
+	base := "HEAD"
+	if err := repo.gitRun("rev-parse", "--verify", "--quiet", name+"@{upstream}"); err == nil {
+		base = name + "@{upstream}"
+	}
+
+	return repo.gitRun("merge-base", "--is-ancestor", "refs/heads/"+name, base) == nil
+}
+
+// BranchMovedError is returned when a branch no longer points to the commit
+// it pointed to when it was chosen for deletion.
+type BranchMovedError struct {
+	Branch   string
+	Expected string
+	Actual   string
+}
+
+func (e *BranchMovedError) Error() string {
+	return fmt.Sprintf("branch %s moved from %s to %s since it was checked", e.Branch, ShortSHA(e.Expected), ShortSHA(e.Actual))
+}
+
+// IncompleteDeletionError is returned when branches were deleted but a step
+// that goes with their deletion failed: removing their configuration,
+// deleting them from their remote or recording them in the deletion journal.
+// Refs lists every failed step; the local branches are deleted.
+type IncompleteDeletionError struct {
+	Refs []RefError
+}
+
+func (e *IncompleteDeletionError) Error() string {
+	steps := make([]string, len(e.Refs))
+	for i, ref := range e.Refs {
+		steps[i] = ref.Branch + ": " + ref.Reason
+	}
+	return "deleted, but " + strings.Join(steps, "; ")
+}
+
+// For returns the failed steps of branch as an *IncompleteDeletionError, or
+// nil if none of its steps failed.
+func (e *IncompleteDeletionError) For(branch string) error {
+	var refs []RefError
+	for _, ref := range e.Refs {
+		if ref.Branch == branch {
+			refs = append(refs, ref)
+		}
+	}
+	return incompleteDeletion(refs)
+}
+
+// incompleteDeletion returns an *IncompleteDeletionError for refs, or nil if
+// there are none.
+func incompleteDeletion(refs []RefError) error {
+	if len(refs) == 0 {
+		return nil
+	}
+	return &IncompleteDeletionError{Refs: refs}
+}
+
 // DeleteBranch deletes a local branch.
 //
-// When force is false it performs a safe delete (git branch -d) and returns the
-// underlying error if the branch has unmerged commits — it never escalates to a
-// force delete on its own. The caller is responsible for deciding whether to
-// force-delete based on the branch risk level (RiskSafe vs RiskDangerous).
+// When force is false it performs a safe delete, refusing like git branch -d
+// a branch that is not merged into its upstream (or HEAD if it has none). It
+// never escalates to a force delete on its own. The caller is responsible for
+// deciding whether to force-delete based on the branch risk level (RiskSafe
+// vs RiskDangerous).
 //
-// When force is true it performs a force delete (git branch -D).
-func DeleteBranch(name string, force bool) error {
-	flag := "-d"
-	if force {
-		flag = "-D"
-	}
-	cmd := exec.Command("git", "branch", flag, name)
-	cmd.Env = append(os.Environ(), "LC_ALL=C")
-	output, err := cmd.CombinedOutput()
+// The branch ref is deleted with update-ref only if it still points to the
+// commit checked before, and backed up in the trash namespace in the same
+// transaction. Its configuration is then removed as git branch -d would, and
+// the deletion is recorded in the deletion journal so it can be restored
+// with "git-gone restore". If one of these steps fails after the branch was
+// deleted, an *IncompleteDeletionError is returned.
+func (repo *Repository) DeleteBranch(name string, force bool) error {
+	_, err := repo.deleteBranch(name, "", force)
+	return err
+}
+
+// DeleteBranchWithRemote deletes both local and remote branch. The remote
+// branch is deleted from the remote the branch tracks, or from fallbackRemote
+// if it has no upstream, only if it still points to what its remote-tracking
+// ref shows. A branch without a remote-tracking ref is deleted locally only.
+// If the remote kept the branch, an *IncompleteDeletionError is returned.
+func (repo *Repository) DeleteBranchWithRemote(name, fallbackRemote string) error {
+	_, err := repo.deleteBranchWithRemote(name, "", fallbackRemote)
+	return err
+}
+
+// DeleteCandidate deletes a branch candidate: a safe candidate locally, with
+// a force delete only if RequiresForceDelete says so, a dangerous one locally
+// and from its remote like DeleteBranchWithRemote. It returns the journal
+// record of the deletion, whose DeletedFromRemote tells whether the branch
+// was deleted from a remote.
+//
+// If the candidate captured a SHA and the branch has moved since, nothing is
+// deleted and a *BranchMovedError is returned, so commits made after the
+// branch was classified are never lost. Git enforces the SHA atomically.
+func (repo *Repository) DeleteCandidate(c DeletionCandidate, fallbackRemote string) (DeletionRecord, error) {
+	if c.RiskLevel == RiskDangerous {
+		return repo.deleteBranchWithRemote(c.Name, c.SHA, fallbackRemote)
+	}
+	return repo.deleteBranch(c.Name, c.SHA, c.RequiresForceDelete(repo))
+}
+
+// snapshotBranchAt captures the state of a branch before it is deleted and
+// checks that it still points to expectedSHA, unless that is empty.
+func (repo *Repository) snapshotBranchAt(name, expectedSHA string) (DeletionRecord, error) {
+	record, err := repo.SnapshotBranch(name)
+	if err == nil && expectedSHA != "" && record.SHA != expectedSHA {
+		return record, &BranchMovedError{Branch: name, Expected: expectedSHA, Actual: record.SHA}
+	}
+	return record, err
+}
+
+// checkDeletable returns an error if git branch would refuse to delete the
+// branch because it is checked out in a worktree
+func (repo *Repository) checkDeletable(name string) error {
+	worktrees, err := repo.ListWorktrees()
 	if err != nil {
-		return fmt.Errorf("%s", string(output))
+		return err
+	}
+	for _, wt := range worktrees {
+		if wt.Branch == name {
+			return fmt.Errorf("cannot delete branch '%s' checked out at '%s'", name, wt.Path)
+		}
 	}
 	return nil
 }
 
-// DeleteBranchWithRemote deletes both local and remote branch.
-func DeleteBranchWithRemote(name string) error {
-	// Try to delete remote branch first
-	cmd := exec.Command("git", "push", "origin", "--delete", name)
-	cmd.Env = append(os.Environ(), "LC_ALL=C")
-	output, err := cmd.CombinedOutput()
+// deleteBranchRef deletes refs/heads/<name> only if it still points to sha,
+// backing it up in the trash namespace in the same update-ref transaction.
+// It returns the trash ref.
+func (repo *Repository) deleteBranchRef(name, sha string) (string, error) {
+	trashRef := fmt.Sprintf("%s%d/heads/%s", TrashNamespace, time.Now().Unix(), name)
+	stdin := fmt.Sprintf("update %s %s\ndelete refs/heads/%s %s\n", trashRef, sha, name, sha)
+	result, err := repo.runInput([]byte(stdin), "update-ref", "-m", "git-gone: delete branch", "--stdin")
 	if err != nil {
-		outputStr := string(output)
-		if !strings.Contains(outputStr, "remote ref does not exist") {
-			// Library code does not format user-facing output: emit a plain
-			// warning to stderr and continue with the local delete. The cmd
-			// layer owns emoji/styling for anything user-visible.
-			fmt.Fprintf(os.Stderr, "warning: failed to delete remote branch %s: %s\n", name, outputStr)
+		if current, snapErr := repo.SnapshotBranch(name); snapErr == nil && current.SHA != sha {
+			return "", &BranchMovedError{Branch: name, Expected: sha, Actual: current.SHA}
 		}
+		return "", fmt.Errorf("%s", strings.TrimSpace(string(result.Stderr)))
+	}
+	return trashRef, nil
+}
+
+// removeBranchConfig drops the configuration of a deleted branch, as git
+// branch -d does. A branch without configuration is not an error.
+func (repo *Repository) removeBranchConfig(name string) error {
+	output, err := repo.gitCombinedOutput("config", "--remove-section", "branch."+name)
+	if err != nil && !strings.Contains(string(output), "no such section") {
+		return fmt.Errorf("failed to remove its configuration: %s", strings.TrimSpace(string(output)))
 	}
+	return nil
+}
 
-	// Force delete local branch
-	cmd = exec.Command("git", "branch", "-D", name)
-	cmd.Env = append(os.Environ(), "LC_ALL=C")
-	output, err = cmd.CombinedOutput()
+func (repo *Repository) deleteBranch(name, expectedSHA string, force bool) (DeletionRecord, error) {
+	record, err := repo.snapshotBranchAt(name, expectedSHA)
 	if err != nil {
-		return fmt.Errorf("%s", string(output))
+		return record, err
+	}
+	if err := repo.checkDeletable(name); err != nil {
+		return record, err
+	}
+	if !force && !repo.IsFullyMerged(name) {
+		return record, fmt.Errorf("the branch '%s' is not fully merged", name)
 	}
 
-	return nil
+	trashRef, err := repo.deleteBranchRef(name, record.SHA)
+	if err != nil {
+		return record, err
+	}
+	record.TrashRef = trashRef
+
+	var failed []RefError
+	if err := repo.removeBranchConfig(name); err != nil {
+		failed = append(failed, RefError{Branch: name, Reason: err.Error()})
+	}
+	if err := repo.recordDeletion(record); err != nil {
+		failed = append(failed, RefError{Branch: name, Reason: err.Error()})
+	}
+	return record, incompleteDeletion(failed)
+}
+
+// deleteBranchWithRemote force-deletes the local branch, then deletes the
+// remote branch only if it still points to what the remote-tracking ref shows
+func (repo *Repository) deleteBranchWithRemote(name, expectedSHA, fallbackRemote string) (DeletionRecord, error) {
+	record, err := repo.snapshotBranchAt(name, expectedSHA)
+	if err != nil {
+		return record, err
+	}
+	if err := repo.checkDeletable(name); err != nil {
+		return record, err
+	}
+	remote := repo.GetBranchRemote(name, fallbackRemote)
+
+	trashRef, err := repo.deleteBranchRef(name, record.SHA)
+	if err != nil {
+		return record, err
+	}
+	record.TrashRef = trashRef
+
+	var failed []RefError
+	if err := repo.removeBranchConfig(name); err != nil {
+		failed = append(failed, RefError{Branch: name, Reason: err.Error()})
+	}
+	if remote != "" {
+		leases, _ := repo.trackedLeases(remote, []string{name})
+		kept := repo.deleteFromRemote(remote, leases)
+		if len(leases) > 0 && len(kept) == 0 {
+			record.DeletedFromRemote = remote
+		}
+		failed = append(failed, kept...)
+	}
+	if err := repo.recordDeletion(record); err != nil {
+		failed = append(failed, RefError{Branch: name, Reason: err.Error()})
+	}
+	return record, incompleteDeletion(failed)
 }
@@ -22,8 +22,40 @@ const (
 	ReasonStaleTag
 	// ReasonUnmerged means the branch is not merged (dangerous).
 	ReasonUnmerged
+	// ReasonSquashMerged means the branch changes were squash-merged into default.
+	ReasonSquashMerged
+	// ReasonRebaseMerged means every branch commit has a patch-equivalent on default.
+	ReasonRebaseMerged
+	// ReasonDivergentTag means the tag exists on the remote but points to a different object.
+	ReasonDivergentTag
+	// ReasonRetention means the tag is dropped by a tag retention policy.
+	ReasonRetention
 )
 
+// String returns the identifier used for the reason in reports.
+func (r DeletionReason) String() string {
+	switch r {
+	case ReasonMerged:
+		return "merged"
+	case ReasonGoneRemote:
+		return "gone_remote"
+	case ReasonStaleTag:
+		return "stale_tag"
+	case ReasonUnmerged:
+		return "unmerged"
+	case ReasonSquashMerged:
+		return "squash_merged"
+	case ReasonRebaseMerged:
+		return "rebase_merged"
+	case ReasonDivergentTag:
+		return "divergent_tag"
+	case ReasonRetention:
+		return "retention"
+	default:
+		return "unknown"
+	}
+}
+
 // RiskLevel represents how dangerous a deletion is.
 type RiskLevel int
 
@@ -41,18 +73,39 @@ type DeletionCandidate struct {
 	Reason       DeletionReason
 	RiskLevel    RiskLevel
 	DisplayLabel string
+	// SHA is the commit a branch pointed to when it was classified, or "" if
+	// unknown. Deleting the candidate is refused once the branch has moved.
+	SHA string
+	// MergedInto is the integration target a branch was found merged into, by
+	// ancestry, rebase or squash. It is also set for branches whose remote is
+	// gone, if they were merged.
+	MergedInto string
 }
 
-// UnmergedPrefix is the prefix used to mark unmerged branches.
-const UnmergedPrefix = "(!) "
+// Prefixes used to mark branches in the interactive selector.
+const (
+	// UnmergedPrefix is the prefix used to mark unmerged branches.
+	UnmergedPrefix = "(!) "
+	// SquashMergedPrefix is the prefix used to mark squash-merged branches.
+	SquashMergedPrefix = "(s) "
+	// RebaseMergedPrefix is the prefix used to mark rebase-merged branches.
+	RebaseMergedPrefix = "(r) "
+	// DivergentPrefix is the prefix used to mark tags that differ from the remote.
+	DivergentPrefix = "(d) "
+)
 
 // NewBranchCandidate creates a DeletionCandidate for a branch.
 func NewBranchCandidate(name string, reason DeletionReason) DeletionCandidate {
 	risk := RiskSafe
 	displayLabel := name
-	if reason == ReasonUnmerged {
+	switch reason {
+	case ReasonUnmerged:
 		risk = RiskDangerous
 		displayLabel = UnmergedPrefix + name
+	case ReasonSquashMerged:
+		displayLabel = SquashMergedPrefix + name
+	case ReasonRebaseMerged:
+		displayLabel = RebaseMergedPrefix + name
 	}
 	return DeletionCandidate{
 		Type:         CandidateBranch,
@@ -73,3 +126,46 @@ func NewTagCandidate(name string) DeletionCandidate {
 		DisplayLabel: name,
 	}
 }
+
+// NewPrunedTagCandidate creates a DeletionCandidate for a tag dropped by a
+// retention policy.
+func NewPrunedTagCandidate(name string) DeletionCandidate {
+	c := NewTagCandidate(name)
+	c.Reason = ReasonRetention
+	return c
+}
+
+// NewDivergentTagCandidate creates a DeletionCandidate for a tag that points
+// to a different object on the remote. Such tags are dangerous to delete, since
+// the local tag may be the only copy of a release.
+func NewDivergentTagCandidate(name string) DeletionCandidate {
+	return DeletionCandidate{
+		Type:         CandidateTag,
+		Name:         name,
+		Reason:       ReasonDivergentTag,
+		RiskLevel:    RiskDangerous,
+		DisplayLabel: DivergentPrefix + name,
+	}
+}
+
+// ProvenMerged reports whether the branch candidate was found merged into an
+// integration target. A branch whose remote is gone is not, unless it was
+// also merged.
+func (c DeletionCandidate) ProvenMerged() bool {
+	switch c.Reason {
+	case ReasonMerged, ReasonRebaseMerged, ReasonSquashMerged:
+		return true
+	}
+	return c.MergedInto != ""
+}
+
+// RequiresForceDelete reports whether deleting the branch candidate needs
+// git branch -D. Git only accepts -d for branches merged into their upstream
+// or HEAD, while a safe candidate may instead be merged into another
+// integration target, or rebase- or squash-merged. Only such proven merges
+// override git's check: a branch whose remote is gone but that was never
+// merged keeps the safe delete, which git refuses. Dangerous candidates are
+// always force-deleted. The branch is looked up in repo.
+func (c DeletionCandidate) RequiresForceDelete(repo *Repository) bool {
+	return c.RiskLevel == RiskDangerous || (c.ProvenMerged() && !repo.IsFullyMerged(c.Name))
+}
@@ -0,0 +1,72 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// CherryResult summarizes how much of a branch is already present in a target
+// branch, following "git cherry" semantics.
+type CherryResult struct {
+	// Unique is the number of commits with no patch-equivalent in target.
+	Unique int
+	// Equivalent is the number of commits already applied to target.
+	Equivalent int
+}
+
+// Total returns the number of commits on the branch that are not reachable
+// from target.
+func (c CherryResult) Total() int {
+	return c.Unique + c.Equivalent
+}
+
+// FullyAbsorbed returns true if every commit on the branch has a
+// patch-equivalent commit on target, as happens after a rebase merge.
+func (c CherryResult) FullyAbsorbed() bool {
+	return c.Unique == 0 && c.Equivalent > 0
+}
+
+// PartiallyAbsorbed returns true if some, but not all, commits on the branch
+// have a patch-equivalent commit on target.
+func (c CherryResult) PartiallyAbsorbed() bool {
+	return c.Unique > 0 && c.Equivalent > 0
+}
+
+// GetCherryStatus compares the commits of branch against target by patch-id.
+func (repo *Repository) GetCherryStatus(branch, target string) (CherryResult, error) {
+	output, err := repo.gitOutput("cherry", target, branch)
+	if err != nil {
+		return CherryResult{}, fmt.Errorf("git cherry failed for %s: %w", branch, err)
+	}
+
+	var result CherryResult
+	for _, line := range strings.Split(string(output), "\n") {
+		switch {
+		case strings.HasPrefix(line, "+"):
+			result.Unique++
+		case strings.HasPrefix(line, "-"):
+			result.Equivalent++
+		}
+	}
+	return result, nil
+}
+
+// GetRebaseMergedBranches returns the branches whose commits all have a
+// patch-equivalent commit on target. Branches that cannot be compared are
+// left out and their errors are joined into the returned error.
+func (repo *Repository) GetRebaseMergedBranches(target string, branches []string) ([]string, error) {
+	var errs []error
+	var rebased []string
+	for _, branch := range branches {
+		result, err := repo.GetCherryStatus(branch, target)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if result.FullyAbsorbed() {
+			rebased = append(rebased, branch)
+		}
+	}
+	return rebased, errors.Join(errs...)
+}
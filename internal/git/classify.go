@@ -0,0 +1,109 @@
+package git
+
+// BranchContext holds what branch classification depends on besides the
+// branches themselves.
+type BranchContext struct {
+	DefaultBranch string
+	CurrentBranch string
+	// Targets are the integration targets, default branch first (see ResolveTargets).
+	Targets []string
+	// Protected lists glob patterns of branches that are never deleted.
+	Protected []string
+	// Worktrees maps branches checked out in other worktrees to their path
+	// (see GetWorktreeBranches).
+	Worktrees map[string]string
+}
+
+// BranchClassification is the outcome of classifying a single local branch.
+type BranchClassification struct {
+	Name string
+	// ProtectedBy names the rule protecting the branch: default_branch,
+	// integration_target, current_branch, worktree:<path> or pattern:<glob>.
+	// It is empty for branches that can be deleted.
+	ProtectedBy string
+	// Candidate describes how an unprotected branch can be deleted.
+	Candidate DeletionCandidate
+	// MergedInto is the integration target that absorbed the branch, if any.
+	MergedInto string
+}
+
+// IsProtected returns true if the branch cannot be deleted.
+func (c BranchClassification) IsProtected() bool {
+	return c.ProtectedBy != ""
+}
+
+// ProtectionRule returns the rule protecting branch in ctx, or "" if the
+// branch may be deleted.
+func ProtectionRule(branch string, ctx BranchContext) string {
+	if branch == ctx.DefaultBranch {
+		return "default_branch"
+	}
+	for _, target := range ctx.Targets {
+		if branch == target {
+			return "integration_target"
+		}
+	}
+	if branch == ctx.CurrentBranch {
+		return "current_branch"
+	}
+	if path, checkedOut := ctx.Worktrees[branch]; checkedOut {
+		return "worktree:" + path
+	}
+	if pattern, protected := MatchProtectedPattern(branch, ctx.Protected); protected {
+		return "pattern:" + pattern
+	}
+	return ""
+}
+
+// ClassifyBranches decides for every branch whether it is protected and, if
+// not, why it can be deleted. Checks run in order of confidence: ancestry
+// merge into a target, rebase merge, squash merge; a deleted remote tracking
+// branch is reported first, and branches matching none of them are unmerged.
+//
+// The branches are read with LoadBranchSnapshot, so the number of git
+// commands does not grow with the number of branches. Failures to read a
+// target do not stop the classification: the affected checks are skipped
+// and the errors are returned together with the classifications.
+func (repo *Repository) ClassifyBranches(branches []string, ctx BranchContext) ([]BranchClassification, error) {
+	snapshot, err := repo.LoadBranchSnapshot(ctx)
+	if snapshot == nil {
+		return nil, err
+	}
+	return snapshot.classify(branches), err
+}
+
+// classify applies the protection rules of the snapshot context and then the
+// merge checks, in order of confidence, to every branch. Candidates capture
+// the SHA of the branch.
+func (s *BranchSnapshot) classify(branches []string) []BranchClassification {
+	var classifications []BranchClassification
+	for _, branch := range branches {
+		if branch == "" {
+			continue
+		}
+		c := BranchClassification{Name: branch, ProtectedBy: ProtectionRule(branch, s.ctx)}
+		if c.IsProtected() {
+			classifications = append(classifications, c)
+			continue
+		}
+
+		reason := ReasonUnmerged
+		if target, ok := s.mergedInto(branch); ok {
+			reason, c.MergedInto = ReasonMerged, target
+		} else if target, ok := s.rebaseMergedInto(branch); ok {
+			reason, c.MergedInto = ReasonRebaseMerged, target
+		} else if target, ok := s.squashMergedInto(branch); ok {
+			reason, c.MergedInto = ReasonSquashMerged, target
+		}
+		// A gone remote is reported first; the merge, if any, is kept as the
+		// proof that lets the branch be force-deleted
+		if s.isGone(branch) {
+			reason = ReasonGoneRemote
+		}
+		c.Candidate = NewBranchCandidate(branch, reason)
+		c.Candidate.SHA = s.refs[branch].SHA
+		c.Candidate.MergedInto = c.MergedInto
+		classifications = append(classifications, c)
+	}
+	return classifications
+}
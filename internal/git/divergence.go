@@ -0,0 +1,68 @@
+package git
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Divergence counts the commits a branch has that a base lacks (Ahead) and
+// the commits the base has that the branch lacks (Behind).
+type Divergence struct {
+	Ahead  int
+	Behind int
+}
+
+// String renders the counts compactly, e.g. "↑25 ↓340".
+func (d Divergence) String() string {
+	return fmt.Sprintf("↑%d ↓%d", d.Ahead, d.Behind)
+}
+
+// GetDivergence returns how far rev is ahead of and behind base.
+func (repo *Repository) GetDivergence(rev, base string) (Divergence, error) {
+	if n := repo.nativeBackend(); n != nil {
+		if divergence, err := n.divergence(rev, base); err == nil {
+			return divergence, nil
+		}
+	}
+
+	output, err := repo.gitOutput("rev-list", "--left-right", "--count", rev+"..."+base)
+	if err != nil {
+		return Divergence{}, fmt.Errorf("failed to compare %s with %s", rev, base)
+	}
+
+	fields := strings.Fields(string(output))
+	if len(fields) != 2 {
+		return Divergence{}, fmt.Errorf("unexpected rev-list output: %q", string(output))
+	}
+	ahead, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return Divergence{}, err
+	}
+	behind, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return Divergence{}, err
+	}
+	return Divergence{Ahead: ahead, Behind: behind}, nil
+}
+
+// GetUpstreamDivergence returns the upstream of a branch (e.g. origin/feature)
+// and how far the branch is ahead of and behind it. It returns false if the
+// branch has no upstream or the upstream branch is gone.
+func (repo *Repository) GetUpstreamDivergence(branch string) (string, Divergence, bool) {
+	if n := repo.nativeBackend(); n != nil {
+		return n.upstreamDivergence(branch)
+	}
+
+	output, err := repo.gitOutput("rev-parse", "--abbrev-ref", branch+"@{upstream}")
+	if err != nil {
+		return "", Divergence{}, false
+	}
+	upstream := strings.TrimSpace(string(output))
+
+	divergence, err := repo.GetDivergence("refs/heads/"+branch, branch+"@{upstream}")
+	if err != nil {
+		return "", Divergence{}, false
+	}
+	return upstream, divergence, true
+}
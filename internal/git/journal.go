@@ -0,0 +1,215 @@
+package git
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// journalFile is the name of the deletion journal inside the git-gone state directory.
+const journalFile = "journal.jsonl"
+
+// DeletionRecord describes a deleted branch with enough detail to restore it.
+type DeletionRecord struct {
+	Branch            string    `json:"branch"`
+	Ref               string    `json:"ref"`
+	SHA               string    `json:"sha"`
+	Remote            string    `json:"remote,omitempty"` // branch.<name>.remote
+	Merge             string    `json:"merge,omitempty"`  // branch.<name>.merge
+	DeletedFromRemote string    `json:"deleted_from_remote,omitempty"`
+	TrashRef          string    `json:"trash_ref,omitempty"` // Backup ref keeping the commits reachable
+	DeletedAt         time.Time `json:"deleted_at"`
+	// RestoredAt is only set on the marker RestoreBranch appends once the
+	// deletion is undone.
+	RestoredAt *time.Time `json:"restored_at,omitempty"`
+}
+
+// journalKey identifies a deletion in the journal and its restored marker.
+func (record DeletionRecord) journalKey() string {
+	return fmt.Sprintf("%s %s %d", record.Branch, record.SHA, record.DeletedAt.UnixNano())
+}
+
+// GetStateDir returns the directory where git-gone keeps its state
+// (.git/git-gone). Linked worktrees share the state of the main repository.
+func (repo *Repository) GetStateDir() (string, error) {
+	output, err := repo.gitOutput("rev-parse", "--git-common-dir")
+	if err != nil {
+		return "", fmt.Errorf("not a git repository")
+	}
+	gitDir := strings.TrimSpace(string(output))
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(repo.Dir, gitDir)
+	}
+	gitDir, err = filepath.Abs(gitDir)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(gitDir, "git-gone"), nil
+}
+
+// getConfigValue returns a git config value, or an empty string if unset.
+func (repo *Repository) getConfigValue(key string) string {
+	output, err := repo.gitOutput("config", "--get", key)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// SnapshotBranch captures the state of a branch before it is deleted.
+func (repo *Repository) SnapshotBranch(name string) (DeletionRecord, error) {
+	ref := "refs/heads/" + name
+	output, err := repo.gitOutput("rev-parse", "--verify", "--quiet", ref)
+	if err != nil {
+		return DeletionRecord{}, fmt.Errorf("branch %s does not exist", name)
+	}
+
+	return DeletionRecord{
+		Branch: name,
+		Ref:    ref,
+		SHA:    strings.TrimSpace(string(output)),
+		Remote: repo.getConfigValue(fmt.Sprintf("branch.%s.remote", name)),
+		Merge:  repo.getConfigValue(fmt.Sprintf("branch.%s.merge", name)),
+	}, nil
+}
+
+// RecordDeletion appends a deletion to the journal in .git/git-gone/.
+func (repo *Repository) RecordDeletion(record DeletionRecord) error {
+	if record.DeletedAt.IsZero() {
+		record.DeletedAt = time.Now()
+	}
+
+	return repo.appendJournal(record)
+}
+
+// appendJournal appends a record to the journal in .git/git-gone/.
+func (repo *Repository) appendJournal(record DeletionRecord) error {
+	stateDir, err := repo.GetStateDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(stateDir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", stateDir, err)
+	}
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(filepath.Join(stateDir, journalFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open deletion journal: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write deletion journal: %w", err)
+	}
+	return nil
+}
+
+// recordDeletion journals a deletion captured by SnapshotBranch. The branch
+// is already deleted when it is recorded, so callers report the error rather
+// than fail the deletion.
+func (repo *Repository) recordDeletion(record DeletionRecord) error {
+	if err := repo.RecordDeletion(record); err != nil {
+		return fmt.Errorf("failed to record its deletion: %w", err)
+	}
+	return nil
+}
+
+// ReadJournal returns all recorded deletions that were not restored since,
+// most recent first.
+func (repo *Repository) ReadJournal() ([]DeletionRecord, error) {
+	stateDir, err := repo.GetStateDir()
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(filepath.Join(stateDir, journalFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []DeletionRecord{}, nil
+		}
+		return nil, fmt.Errorf("failed to open deletion journal: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var records []DeletionRecord
+	restored := make(map[string]bool)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var record DeletionRecord
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			// Skip corrupt lines rather than losing the whole journal
+			continue
+		}
+		if record.RestoredAt != nil {
+			restored[record.journalKey()] = true
+			continue
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read deletion journal: %w", err)
+	}
+
+	pending := make([]DeletionRecord, 0, len(records))
+	for i := len(records) - 1; i >= 0; i-- {
+		if !restored[records[i].journalKey()] {
+			pending = append(pending, records[i])
+		}
+	}
+	return pending, nil
+}
+
+// RestoreBranch recreates a deleted branch at its recorded SHA and restores
+// its upstream configuration. It fails if the branch already exists. The
+// trash backup of the branch, if any, is no longer needed and is removed, and
+// the deletion is marked restored in the journal so ReadJournal no longer
+// lists it.
+func (repo *Repository) RestoreBranch(record DeletionRecord) error {
+	output, err := repo.gitCombinedOutput("branch", record.Branch, record.SHA)
+	if err != nil {
+		return fmt.Errorf("%s", string(output))
+	}
+
+	upstream := map[string]string{
+		fmt.Sprintf("branch.%s.remote", record.Branch): record.Remote,
+		fmt.Sprintf("branch.%s.merge", record.Branch):  record.Merge,
+	}
+	for key, value := range upstream {
+		if value == "" {
+			continue
+		}
+		if output, err := repo.gitCombinedOutput("config", key, value); err != nil {
+			return fmt.Errorf("failed to restore %s: %s", key, string(output))
+		}
+	}
+
+	now := time.Now()
+	marker := record
+	marker.RestoredAt = &now
+	if err := repo.appendJournal(marker); err != nil {
+		return fmt.Errorf("branch restored, but failed to mark it restored: %w", err)
+	}
+	return repo.DropTrashRef(record.TrashRef)
+}
+
+// PushBranch pushes a local branch to a remote under the same name.
+func (repo *Repository) PushBranch(name, remote string) error {
+	output, err := repo.gitCombinedOutput("push", remote, fmt.Sprintf("refs/heads/%s:refs/heads/%s", name, name))
+	if err != nil {
+		return fmt.Errorf("%s", string(output))
+	}
+	return nil
+}
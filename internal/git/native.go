@@ -0,0 +1,474 @@
+package git
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	gogit "github.com/go-git/go-git/v5"
+	gogitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+)
+
+// Backends accepted by SetBackend.
+const (
+	// BackendExec runs a git process for every query.
+	BackendExec = "exec"
+	// BackendNative reads refs, config and commit history in process.
+	BackendNative = "native"
+)
+
+// SetBackend selects how branches, upstreams, commit dates, merge status and
+// divergence are read. BackendExec runs git for every query through the
+// Runner; BackendNative opens the repository with go-git and reads it in
+// process, which avoids one or more git processes per branch. Operations that
+// modify the repository always run git.
+func (repo *Repository) SetBackend(name string) error {
+	repo.backendMu.Lock()
+	defer repo.backendMu.Unlock()
+
+	switch name {
+	case "", BackendExec:
+		repo.native = nil
+		return nil
+	case BackendNative:
+		dir := repo.Dir
+		if dir == "" {
+			dir = "."
+		}
+		native, err := openNativeRepo(dir)
+		if err != nil {
+			return err
+		}
+		repo.native = native
+		return nil
+	default:
+		return fmt.Errorf("unknown backend %q (use %s or %s)", name, BackendExec, BackendNative)
+	}
+}
+
+// nativeBackend returns the in-process repository, or nil when git is run
+// as a subprocess.
+func (repo *Repository) nativeBackend() *nativeRepo {
+	repo.backendMu.RLock()
+	defer repo.backendMu.RUnlock()
+	return repo.native
+}
+
+// nativeRepo answers read-only queries with go-git. Refs and config are read
+// on every call; facts about commits, which never change, are cached.
+type nativeRepo struct {
+	repo *gogit.Repository
+
+	mu        sync.Mutex
+	config    *gogitconfig.Config
+	configMod time.Time
+	parents   map[plumbing.Hash][]plumbing.Hash
+	times     map[plumbing.Hash]time.Time
+	ancestors map[plumbing.Hash]map[plumbing.Hash]struct{}
+	counts    map[plumbing.Hash]int
+}
+
+func openNativeRepo(path string) (*nativeRepo, error) {
+	repo, err := gogit.PlainOpenWithOptions(path, &gogit.PlainOpenOptions{
+		DetectDotGit:          true,
+		EnableDotGitCommonDir: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("native backend: %w", err)
+	}
+	return &nativeRepo{
+		repo:      repo,
+		parents:   make(map[plumbing.Hash][]plumbing.Hash),
+		times:     make(map[plumbing.Hash]time.Time),
+		ancestors: make(map[plumbing.Hash]map[plumbing.Hash]struct{}),
+		counts:    make(map[plumbing.Hash]int),
+	}, nil
+}
+
+// branchTips returns the commit every local branch points to, keyed by name.
+func (n *nativeRepo) branchTips() (map[string]plumbing.Hash, error) {
+	refs, err := n.repo.References()
+	if err != nil {
+		return nil, err
+	}
+	tips := make(map[string]plumbing.Hash)
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		if !ref.Name().IsBranch() {
+			return nil
+		}
+		if ref.Type() == plumbing.SymbolicReference {
+			resolved, err := n.repo.Reference(ref.Name(), true)
+			if err != nil {
+				return nil
+			}
+			ref = resolved
+		}
+		tips[ref.Name().Short()] = ref.Hash()
+		return nil
+	})
+	return tips, err
+}
+
+func sortedKeys(m map[string]plumbing.Hash) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// localBranches returns all local branch names in refname order, like git branch.
+func (n *nativeRepo) localBranches() ([]string, error) {
+	tips, err := n.branchTips()
+	if err != nil {
+		return nil, err
+	}
+	return sortedKeys(tips), nil
+}
+
+// repoConfig returns the repository config. It is parsed again only when the
+// config file changes, since parsing it for every branch dominates the cost
+// on repositories with many tracking branches.
+func (n *nativeRepo) repoConfig() (*gogitconfig.Config, error) {
+	var modTime time.Time
+	if storage, ok := n.repo.Storer.(*filesystem.Storage); ok {
+		if info, err := storage.Filesystem().Stat("config"); err == nil {
+			modTime = info.ModTime()
+		}
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.config != nil && !modTime.IsZero() && modTime.Equal(n.configMod) {
+		return n.config, nil
+	}
+	cfg, err := n.repo.Config()
+	if err != nil {
+		return nil, err
+	}
+	n.config, n.configMod = cfg, modTime
+	return cfg, nil
+}
+
+// upstream returns the remote tracking ref of a branch, as git resolves
+// branch@{upstream}: branch.<name>.merge mapped through the fetch refspecs of
+// branch.<name>.remote. ok is false if the branch has no upstream.
+func (n *nativeRepo) upstream(branch string) (ref plumbing.ReferenceName, remote string, ok bool) {
+	cfg, err := n.repoConfig()
+	if err != nil {
+		return "", "", false
+	}
+	b, exists := cfg.Branches[branch]
+	if !exists || b.Remote == "" {
+		return "", "", false
+	}
+	if b.Merge == "" {
+		return "", b.Remote, false
+	}
+	if b.Remote == "." {
+		return b.Merge, b.Remote, true
+	}
+	rc, exists := cfg.Remotes[b.Remote]
+	if !exists {
+		return "", b.Remote, false
+	}
+	for _, spec := range rc.Fetch {
+		if spec.Match(b.Merge) {
+			return spec.Dst(b.Merge), b.Remote, true
+		}
+	}
+	return "", b.Remote, false
+}
+
+// remoteStatus mirrors GetRemoteStatus.
+func (n *nativeRepo) remoteStatus(branch string) RemoteStatus {
+	ref, remote, ok := n.upstream(branch)
+	if remote == "" {
+		return NoTracking
+	}
+	if ok {
+		if _, err := n.repo.Reference(ref, true); err != nil {
+			return TrackingGone
+		}
+	}
+	return TrackingActive
+}
+
+// goneBranches returns the branches whose upstream no longer exists.
+func (n *nativeRepo) goneBranches() ([]string, error) {
+	branches, err := n.localBranches()
+	if err != nil {
+		return nil, err
+	}
+	var gone []string
+	for _, branch := range branches {
+		if ref, _, ok := n.upstream(branch); ok {
+			if _, err := n.repo.Reference(ref, true); err != nil {
+				gone = append(gone, branch)
+			}
+		}
+	}
+	return gone, nil
+}
+
+// branchCommitDates mirrors GetBranchCommitDates.
+func (n *nativeRepo) branchCommitDates() (map[string]time.Time, error) {
+	tips, err := n.branchTips()
+	if err != nil {
+		return nil, err
+	}
+	dates := make(map[string]time.Time)
+	for name, tip := range tips {
+		if _, err := n.commitParents(tip); err == nil {
+			n.mu.Lock()
+			dates[name] = time.Unix(n.times[tip].Unix(), 0)
+			n.mu.Unlock()
+		}
+	}
+	return dates, nil
+}
+
+// branchRefs mirrors listBranchRefs.
+func (n *nativeRepo) branchRefs() ([]BranchRef, error) {
+	tips, err := n.branchTips()
+	if err != nil {
+		return nil, err
+	}
+	refs := make([]BranchRef, 0, len(tips))
+	for _, name := range sortedKeys(tips) {
+		tip := tips[name]
+		ref := BranchRef{Name: name, SHA: tip.String()}
+		upstream, remote, ok := n.upstream(name)
+		if ok {
+			ref.Remote, ref.Upstream = remote, upstream.Short()
+			if resolved, err := n.repo.Reference(upstream, true); err != nil {
+				ref.Gone = true
+			} else if divergence, err := n.divergenceOf(tip, resolved.Hash(), false); err == nil {
+				ref.UpstreamDivergence = divergence
+			}
+		}
+		if _, err := n.commitParents(tip); err == nil {
+			n.mu.Lock()
+			ref.CommitDate = time.Unix(n.times[tip].Unix(), 0)
+			n.mu.Unlock()
+		}
+		refs = append(refs, ref)
+	}
+	return refs, nil
+}
+
+// mergedBranches returns the branches whose tip is reachable from target,
+// like git branch --merged.
+func (n *nativeRepo) mergedBranches(target string) ([]string, error) {
+	base, err := n.resolve(target)
+	if err != nil {
+		return nil, err
+	}
+	reachable, err := n.ancestorSet(base)
+	if err != nil {
+		return nil, err
+	}
+	tips, err := n.branchTips()
+	if err != nil {
+		return nil, err
+	}
+	var merged []string
+	for _, name := range sortedKeys(tips) {
+		if _, ok := reachable[tips[name]]; ok {
+			merged = append(merged, name)
+		}
+	}
+	return merged, nil
+}
+
+// divergence mirrors GetDivergence: the commits rev has that base lacks and
+// the commits base has that rev lacks.
+func (n *nativeRepo) divergence(rev, base string) (Divergence, error) {
+	revHash, err := n.resolve(rev)
+	if err != nil {
+		return Divergence{}, err
+	}
+	baseHash, err := n.resolve(base)
+	if err != nil {
+		return Divergence{}, err
+	}
+	return n.divergenceOf(revHash, baseHash, true)
+}
+
+// upstreamDivergence mirrors GetUpstreamDivergence.
+func (n *nativeRepo) upstreamDivergence(branch string) (string, Divergence, bool) {
+	ref, _, ok := n.upstream(branch)
+	if !ok {
+		return "", Divergence{}, false
+	}
+	upstream, err := n.repo.Reference(ref, true)
+	if err != nil {
+		return "", Divergence{}, false
+	}
+	tip, err := n.resolve("refs/heads/" + branch)
+	if err != nil {
+		return "", Divergence{}, false
+	}
+	// Every branch has its own upstream, so its history is not cached
+	divergence, err := n.divergenceOf(tip, upstream.Hash(), false)
+	if err != nil {
+		return "", Divergence{}, false
+	}
+	return ref.Short(), divergence, true
+}
+
+// divergenceOf compares two commits. cacheBase keeps the history of base for
+// later comparisons against the same base.
+func (n *nativeRepo) divergenceOf(rev, base plumbing.Hash, cacheBase bool) (Divergence, error) {
+	var baseSet map[plumbing.Hash]struct{}
+	var err error
+	if cacheBase {
+		baseSet, err = n.ancestorSet(base)
+	} else {
+		baseSet, err = n.walk([]plumbing.Hash{base})
+	}
+	if err != nil {
+		return Divergence{}, err
+	}
+
+	// Walk from rev down to the commits base already has: everything on the
+	// way is ahead, the commits where the walk stops are the merge bases
+	ahead := 0
+	var boundary []plumbing.Hash
+	seen := map[plumbing.Hash]bool{rev: true}
+	queue := []plumbing.Hash{rev}
+	for len(queue) > 0 {
+		commit := queue[0]
+		queue = queue[1:]
+		if _, common := baseSet[commit]; common {
+			boundary = append(boundary, commit)
+			continue
+		}
+		ahead++
+		parents, err := n.commitParents(commit)
+		if err != nil {
+			return Divergence{}, err
+		}
+		for _, parent := range parents {
+			if !seen[parent] {
+				seen[parent] = true
+				queue = append(queue, parent)
+			}
+		}
+	}
+
+	// Base is behind by everything it has beyond the ancestors of the merge bases
+	common, err := n.ancestorCount(boundary)
+	if err != nil {
+		return Divergence{}, err
+	}
+	return Divergence{Ahead: ahead, Behind: len(baseSet) - common}, nil
+}
+
+// ancestorCount returns how many commits are reachable from the given
+// commits. Counts of single commits are cached.
+func (n *nativeRepo) ancestorCount(commits []plumbing.Hash) (int, error) {
+	if len(commits) == 0 {
+		return 0, nil
+	}
+	if len(commits) == 1 {
+		n.mu.Lock()
+		count, ok := n.counts[commits[0]]
+		n.mu.Unlock()
+		if ok {
+			return count, nil
+		}
+	}
+
+	set, err := n.walk(commits)
+	if err != nil {
+		return 0, err
+	}
+	if len(commits) == 1 {
+		n.mu.Lock()
+		n.counts[commits[0]] = len(set)
+		n.mu.Unlock()
+	}
+	return len(set), nil
+}
+
+// ancestorSet returns every commit reachable from tip, including tip. Sets
+// are cached, since only a few bases (the integration targets) are queried.
+func (n *nativeRepo) ancestorSet(tip plumbing.Hash) (map[plumbing.Hash]struct{}, error) {
+	n.mu.Lock()
+	set, ok := n.ancestors[tip]
+	n.mu.Unlock()
+	if ok {
+		return set, nil
+	}
+
+	set, err := n.walk([]plumbing.Hash{tip})
+	if err != nil {
+		return nil, err
+	}
+	n.mu.Lock()
+	n.ancestors[tip] = set
+	n.counts[tip] = len(set)
+	n.mu.Unlock()
+	return set, nil
+}
+
+func (n *nativeRepo) walk(tips []plumbing.Hash) (map[plumbing.Hash]struct{}, error) {
+	set := make(map[plumbing.Hash]struct{})
+	stack := append([]plumbing.Hash(nil), tips...)
+	for len(stack) > 0 {
+		commit := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if _, ok := set[commit]; ok {
+			continue
+		}
+		set[commit] = struct{}{}
+		parents, err := n.commitParents(commit)
+		if err != nil {
+			return nil, err
+		}
+		stack = append(stack, parents...)
+	}
+	return set, nil
+}
+
+// commitParents returns the parents of a commit, reading it only once.
+func (n *nativeRepo) commitParents(h plumbing.Hash) ([]plumbing.Hash, error) {
+	n.mu.Lock()
+	parents, ok := n.parents[h]
+	n.mu.Unlock()
+	if ok {
+		return parents, nil
+	}
+
+	commit, err := n.repo.CommitObject(h)
+	if err != nil {
+		return nil, err
+	}
+	n.mu.Lock()
+	n.parents[h] = commit.ParentHashes
+	n.times[h] = commit.Committer.When
+	n.mu.Unlock()
+	return commit.ParentHashes, nil
+}
+
+// resolve returns the commit a revision (branch, ref or hash) points to.
+func (n *nativeRepo) resolve(rev string) (plumbing.Hash, error) {
+	if strings.HasPrefix(rev, "refs/") {
+		ref, err := n.repo.Reference(plumbing.ReferenceName(rev), true)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		return ref.Hash(), nil
+	}
+	hash, err := n.repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return *hash, nil
+}
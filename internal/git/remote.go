@@ -2,15 +2,15 @@ package git
 
 import (
 	"fmt"
-	"os"
-	"os/exec"
+	"strings"
 )
 
+// DefaultRemote is the remote preferred when none is configured.
+const DefaultRemote = "origin"
+
 // UpdateRemoteRefs fetches all remotes and prunes deleted references.
-func UpdateRemoteRefs() error {
-	cmd := exec.Command("git", "fetch", "--all", "--prune")
-	cmd.Env = append(os.Environ(), "LC_ALL=C")
-	output, err := cmd.CombinedOutput()
+func (repo *Repository) UpdateRemoteRefs() error {
+	output, err := repo.gitCombinedOutput("fetch", "--all", "--prune")
 	if err != nil {
 		return fmt.Errorf("fetch failed: %s", string(output))
 	}
@@ -19,17 +19,13 @@ func UpdateRemoteRefs() error {
 
 // UpdateRemoteRefsSync fetches all remotes and prunes, then runs an additional
 // "git remote update --prune" pass to fully reconcile tracking refs.
-func UpdateRemoteRefsSync() error {
-	cmd := exec.Command("git", "fetch", "--all", "--prune")
-	cmd.Env = append(os.Environ(), "LC_ALL=C")
-	output, err := cmd.CombinedOutput()
+func (repo *Repository) UpdateRemoteRefsSync() error {
+	output, err := repo.gitCombinedOutput("fetch", "--all", "--prune")
 	if err != nil {
 		return fmt.Errorf("fetch failed: %s", string(output))
 	}
 
-	cmd = exec.Command("git", "remote", "update", "--prune")
-	cmd.Env = append(os.Environ(), "LC_ALL=C")
-	output, err = cmd.CombinedOutput()
+	output, err = repo.gitCombinedOutput("remote", "update", "--prune")
 	if err != nil {
 		return fmt.Errorf("remote update failed: %s", string(output))
 	}
@@ -37,9 +33,58 @@ func UpdateRemoteRefsSync() error {
 	return nil
 }
 
-// HasRemote checks if the repository has an origin remote.
-func HasRemote() bool {
-	cmd := exec.Command("git", "remote", "get-url", "origin")
-	cmd.Env = append(os.Environ(), "LC_ALL=C")
-	return cmd.Run() == nil
+// HasRemote checks if the repository has a remote with the given name.
+func (repo *Repository) HasRemote(remote string) bool {
+	if remote == "" {
+		return false
+	}
+	return repo.gitRun("remote", "get-url", remote) == nil
+}
+
+// GetRemotes returns the names of all configured remotes.
+func (repo *Repository) GetRemotes() ([]string, error) {
+	output, err := repo.gitOutput("remote")
+	if err != nil {
+		return nil, err
+	}
+
+	var remotes []string
+	for _, line := range strings.Split(string(output), "\n") {
+		remote := strings.TrimSpace(line)
+		if remote != "" {
+			remotes = append(remotes, remote)
+		}
+	}
+	return remotes, nil
+}
+
+// ResolveRemote returns the remote to work against. A non-empty preferred
+// remote must exist. Otherwise origin is used if present, then the first
+// configured remote. It returns an empty string if there are no remotes.
+func (repo *Repository) ResolveRemote(preferred string) (string, error) {
+	if preferred != "" {
+		if !repo.HasRemote(preferred) {
+			return "", fmt.Errorf("remote '%s' does not exist", preferred)
+		}
+		return preferred, nil
+	}
+
+	if repo.HasRemote(DefaultRemote) {
+		return DefaultRemote, nil
+	}
+	remotes, err := repo.GetRemotes()
+	if err != nil || len(remotes) == 0 {
+		return "", nil
+	}
+	return remotes[0], nil
+}
+
+// GetBranchRemote returns the remote a branch tracks (branch.<name>.remote),
+// or fallback if the branch has no upstream or tracks a local branch.
+func (repo *Repository) GetBranchRemote(name, fallback string) string {
+	remote := repo.getConfigValue(fmt.Sprintf("branch.%s.remote", name))
+	if remote == "" || remote == "." {
+		return fallback
+	}
+	return remote
 }
@@ -0,0 +1,283 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RemoteBranch is a branch on a remote, as seen through its remote-tracking ref.
+type RemoteBranch struct {
+	Remote      string
+	Name        string // Branch name on the remote, e.g. feature/login
+	SHA         string
+	AuthorName  string
+	AuthorEmail string
+	CommitDate  time.Time
+}
+
+// Ref returns the remote-tracking ref, e.g. refs/remotes/origin/feature/login.
+func (b RemoteBranch) Ref() string {
+	return "refs/remotes/" + b.Remote + "/" + b.Name
+}
+
+// ShortName returns the remote-tracking branch name, e.g. origin/feature/login.
+func (b RemoteBranch) ShortName() string {
+	return b.Remote + "/" + b.Name
+}
+
+// ListRemoteBranches returns the branches of a remote from its
+// remote-tracking refs, without the remote's symbolic HEAD.
+func (repo *Repository) ListRemoteBranches(remote string) ([]RemoteBranch, error) {
+	prefix := "refs/remotes/" + remote + "/"
+	output, err := repo.gitOutput("for-each-ref",
+		"--format=%(refname)%09%(symref)%09%(objectname)%09%(authorname)%09%(authoremail)%09%(committerdate:unix)",
+		prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	var branches []RemoteBranch
+	for _, line := range strings.Split(string(output), "\n") {
+		parts := strings.Split(line, "\t")
+		if len(parts) != 6 || parts[1] != "" {
+			continue
+		}
+		seconds, _ := strconv.ParseInt(parts[5], 10, 64)
+		branches = append(branches, RemoteBranch{
+			Remote:      remote,
+			Name:        strings.TrimPrefix(parts[0], prefix),
+			SHA:         parts[2],
+			AuthorName:  parts[3],
+			AuthorEmail: strings.Trim(parts[4], "<>"),
+			CommitDate:  time.Unix(seconds, 0),
+		})
+	}
+	return branches, nil
+}
+
+// RemoteTargetRef returns the ref to compare remote branches against for an
+// integration target: the remote's copy of the target if it exists, otherwise
+// the local branch.
+func (repo *Repository) RemoteTargetRef(remote, target string) string {
+	ref := "refs/remotes/" + remote + "/" + target
+	if err := repo.gitRun("rev-parse", "--verify", "--quiet", ref); err == nil {
+		return ref
+	}
+	return target
+}
+
+// GetMergedRemoteBranches returns the names of the remote's branches that are
+// merged into targetRef.
+func (repo *Repository) GetMergedRemoteBranches(remote, targetRef string) ([]string, error) {
+	prefix := "refs/remotes/" + remote + "/"
+	output, err := repo.gitOutput("for-each-ref", "--merged", targetRef, "--format=%(refname)%09%(symref)", prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	var branches []string
+	for _, line := range strings.Split(string(output), "\n") {
+		ref, symref, ok := strings.Cut(line, "\t")
+		if !ok || symref != "" {
+			continue
+		}
+		branches = append(branches, strings.TrimPrefix(ref, prefix))
+	}
+	return branches, nil
+}
+
+// MatchAuthor reports whether the branch's last commit was authored by someone
+// matching one of the patterns. Patterns are matched case-insensitively
+// against the author name and email and may use path.Match globs such as
+// *@example.com. An empty pattern list matches every author.
+func MatchAuthor(b RemoteBranch, patterns []string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	name := strings.ToLower(b.AuthorName)
+	email := strings.ToLower(b.AuthorEmail)
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(pattern)
+		for _, value := range []string{name, email} {
+			if matched, err := path.Match(pattern, value); err == nil && matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// RemoteDeletionError is returned when branches could not be deleted from a
+// remote. Refs lists every branch kept on the remote and why; the other
+// branches were deleted.
+type RemoteDeletionError struct {
+	Remote string
+	Refs   []RefError
+}
+
+func (e *RemoteDeletionError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "kept %d branch(es) on %s", len(e.Refs), e.Remote)
+	for _, ref := range e.Refs {
+		fmt.Fprintf(&b, "\n  %s: %s", ref.Branch, ref.Reason)
+	}
+	return b.String()
+}
+
+// Kept returns why branch was kept on the remote, or nil if it was deleted.
+func (e *RemoteDeletionError) Kept(branch string) error {
+	for _, ref := range e.Refs {
+		if ref.Branch == branch {
+			return fmt.Errorf("kept on %s: %s", e.Remote, ref.Reason)
+		}
+	}
+	return nil
+}
+
+// DeleteRemoteBranches deletes branches from a remote with a single push.
+// Each deletion is leased on the commit the branch pointed to when it was
+// listed, so a branch someone pushed to since then is kept on the remote.
+// The remote-tracking refs are backed up in the trash namespace first, so the
+// commits stay reachable locally and can be pushed back, and nothing is
+// pushed if a branch cannot be backed up. The push is not atomic: a
+// *RemoteDeletionError lists the branches kept, whose backups are dropped,
+// and the other branches were deleted.
+func (repo *Repository) DeleteRemoteBranches(remote string, branches []RemoteBranch) error {
+	if len(branches) == 0 {
+		return nil
+	}
+
+	leases := make(map[string]string)
+	backups := make(map[string]string)
+	for _, branch := range branches {
+		trashRef, err := repo.MoveToTrash(branch.Ref())
+		if err != nil {
+			for _, backup := range backups {
+				_ = repo.DropTrashRef(backup)
+			}
+			return err
+		}
+		backups[branch.Name] = trashRef
+		leases[branch.Name] = branch.SHA
+	}
+
+	err := repo.deleteRemoteBranches(remote, leases)
+	var kept *RemoteDeletionError
+	if errors.As(err, &kept) {
+		for _, ref := range kept.Refs {
+			_ = repo.DropTrashRef(backups[ref.Branch])
+		}
+	}
+	return err
+}
+
+// trackedLeases returns the commit of the remote-tracking ref of each of
+// names, keyed by name, to lease their deletion on. Branches without a
+// remote-tracking ref are not on the remote as far as git-gone knows and are
+// returned separately.
+func (repo *Repository) trackedLeases(remote string, names []string) (map[string]string, []string) {
+	prefix := "refs/remotes/" + remote + "/"
+	tracking := make(map[string]string)
+	if output, err := repo.gitOutput("for-each-ref", "--format=%(objectname) %(refname)", prefix); err == nil {
+		for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+			if sha, ref, ok := strings.Cut(line, " "); ok {
+				tracking[strings.TrimPrefix(ref, prefix)] = sha
+			}
+		}
+	}
+
+	leases := make(map[string]string)
+	var untracked []string
+	for _, name := range names {
+		if sha, ok := tracking[name]; ok {
+			leases[name] = sha
+		} else {
+			untracked = append(untracked, name)
+		}
+	}
+	return leases, untracked
+}
+
+// deleteFromRemote deletes the leased branches from remote like
+// deleteRemoteBranches and returns why each branch kept on the remote was
+// kept.
+func (repo *Repository) deleteFromRemote(remote string, leases map[string]string) []RefError {
+	var kept *RemoteDeletionError
+	if !errors.As(repo.deleteRemoteBranches(remote, leases), &kept) {
+		return nil
+	}
+	refs := make([]RefError, len(kept.Refs))
+	for i, ref := range kept.Refs {
+		refs[i] = RefError{Branch: ref.Branch, Reason: "kept on " + remote + ": " + ref.Reason}
+	}
+	return refs
+}
+
+// deleteRemoteBranches deletes branches from remote with a single porcelain
+// push. leases maps every branch to the commit it must still point to on the
+// remote, so a branch someone advanced since it was checked is kept. A
+// *RemoteDeletionError lists the branches kept, with the reason git gave for
+// each; the other branches were deleted.
+func (repo *Repository) deleteRemoteBranches(remote string, leases map[string]string) error {
+	if len(leases) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(leases))
+	for name := range leases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	args := []string{"push", "--porcelain"}
+	for _, name := range names {
+		args = append(args, "--force-with-lease=refs/heads/"+name+":"+leases[name])
+	}
+	args = append(append(args, remote, "--delete"), names...)
+	result, err := repo.run(nil, args...)
+
+	deleted := make(map[string]bool)
+	reasons := make(map[string]string)
+	for _, line := range strings.Split(string(result.Stdout), "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 3 {
+			continue
+		}
+		// Deleted refs read ":refs/heads/<name>", rejected ones
+		// "(delete):refs/heads/<name>"
+		_, ref, _ := strings.Cut(fields[1], ":")
+		name := strings.TrimPrefix(ref, "refs/heads/")
+		switch fields[0] {
+		case "-":
+			deleted[name] = true
+		case "!":
+			reasons[name] = fields[2]
+		}
+	}
+
+	// Refs git did not report on failed with the push as a whole
+	fallback := "not deleted"
+	if stderr := strings.TrimSpace(string(result.Stderr)); err != nil && stderr != "" {
+		fallback = stderr
+	}
+	var kept []RefError
+	for _, name := range names {
+		if deleted[name] {
+			continue
+		}
+		reason, ok := reasons[name]
+		if !ok {
+			reason = fallback
+		}
+		kept = append(kept, RefError{Branch: name, Reason: reason})
+	}
+	if len(kept) > 0 {
+		return &RemoteDeletionError{Remote: remote, Refs: kept}
+	}
+	return nil
+}
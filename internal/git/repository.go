@@ -3,49 +3,68 @@ package git
 
 import (
 	"fmt"
+	"io/fs"
 	"os"
-	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
+	"sync"
 )
 
-// Repository represents a git repository context.
+// Repository represents a git repository context. Every git command its
+// methods run goes through Runner in Dir, so several repositories can be used
+// side by side, each with its own runner.
 type Repository struct {
-	Path          string
+	// Dir is the directory git runs in, "" for the current directory.
+	Dir string
+	// Runner executes the git commands; nil runs them with ExecRunner.
+	Runner Runner
+
 	DefaultBranch string
 	CurrentBranch string
-	HasRemote     bool
-	RemoteURL     string
+	Remote        string
+	// RemoteURL is the URL of Remote, "" if it has none.
+	RemoteURL string
+
+	backendMu sync.RWMutex
+	native    *nativeRepo
 }
 
-// CheckGitRepository verifies that the current directory is a git repository.
-func CheckGitRepository() error {
-	cmd := exec.Command("git", "rev-parse", "--git-dir")
-	cmd.Env = append(os.Environ(), "LC_ALL=C")
-	if err := cmd.Run(); err != nil {
+// OpenRepository returns the repository in dir whose git commands run through
+// runner. It does not check that dir is a repository: see CheckGitRepository,
+// and NewRepository to also resolve the context.
+func OpenRepository(dir string, runner Runner) *Repository {
+	return &Repository{Dir: dir, Runner: runner}
+}
+
+// CheckGitRepository verifies that the directory is a git repository.
+func (repo *Repository) CheckGitRepository() error {
+	if err := repo.gitRun("rev-parse", "--git-dir"); err != nil {
 		return fmt.Errorf("not a git repository")
 	}
 	return nil
 }
 
-// GetDefaultBranch returns the default branch name (main, master, etc.).
-func GetDefaultBranch() (string, error) {
+// GetDefaultBranch returns the default branch name (main, master, etc.),
+// as advertised by the HEAD of the given remote when available.
+func (repo *Repository) GetDefaultBranch(remote string) (string, error) {
 	// Try to get the default branch from remote
-	cmd := exec.Command("git", "symbolic-ref", "refs/remotes/origin/HEAD")
-	cmd.Env = append(os.Environ(), "LC_ALL=C")
-	output, err := cmd.Output()
-	if err == nil {
-		parts := strings.Split(strings.TrimSpace(string(output)), "/")
-		if len(parts) > 0 {
-			return parts[len(parts)-1], nil
+	if remote != "" {
+		prefix := "refs/remotes/" + remote + "/"
+		output, err := repo.gitOutput("symbolic-ref", prefix+"HEAD")
+		if err == nil {
+			ref := strings.TrimSpace(string(output))
+			if strings.HasPrefix(ref, prefix) {
+				return strings.TrimPrefix(ref, prefix), nil
+			}
 		}
 	}
 
 	// Fallback: try common default branch names
 	commonDefaults := []string{"main", "master", "develop"}
 	for _, branch := range commonDefaults {
-		cmd := exec.Command("git", "rev-parse", "--verify", branch)
-		cmd.Env = append(os.Environ(), "LC_ALL=C")
-		if err := cmd.Run(); err == nil {
+		if err := repo.gitRun("rev-parse", "--verify", branch); err == nil {
 			return branch, nil
 		}
 	}
@@ -54,45 +73,117 @@ func GetDefaultBranch() (string, error) {
 }
 
 // GetCurrentBranch returns the currently checked out branch name.
-func GetCurrentBranch() (string, error) {
-	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
-	cmd.Env = append(os.Environ(), "LC_ALL=C")
-	output, err := cmd.Output()
+func (repo *Repository) GetCurrentBranch() (string, error) {
+	output, err := repo.gitOutput("rev-parse", "--abbrev-ref", "HEAD")
 	if err != nil {
 		return "", err
 	}
 	return strings.TrimSpace(string(output)), nil
 }
 
-// NewRepository creates a Repository instance for the current directory.
-func NewRepository() (*Repository, error) {
-	if err := CheckGitRepository(); err != nil {
+// NewRepository creates a Repository instance for dir and resolves its
+// context. The preferred remote is resolved with ResolveRemote.
+func NewRepository(dir string, runner Runner, preferredRemote string) (*Repository, error) {
+	repo := OpenRepository(dir, runner)
+	if err := repo.CheckGitRepository(); err != nil {
 		return nil, err
 	}
 
-	defaultBranch, err := GetDefaultBranch()
+	remote, err := repo.ResolveRemote(preferredRemote)
+	if err != nil {
+		return nil, err
+	}
+
+	defaultBranch, err := repo.GetDefaultBranch(remote)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get default branch: %w", err)
 	}
 
-	currentBranch, err := GetCurrentBranch()
+	currentBranch, err := repo.GetCurrentBranch()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get current branch: %w", err)
 	}
 
-	repo := &Repository{
-		DefaultBranch: defaultBranch,
-		CurrentBranch: currentBranch,
-	}
+	repo.DefaultBranch = defaultBranch
+	repo.CurrentBranch = currentBranch
+	repo.Remote = remote
 
 	// Check for remote
-	cmd := exec.Command("git", "remote", "get-url", "origin")
-	cmd.Env = append(os.Environ(), "LC_ALL=C")
-	output, err := cmd.Output()
-	if err == nil {
-		repo.HasRemote = true
-		repo.RemoteURL = strings.TrimSpace(string(output))
+	if remote != "" {
+		output, err := repo.gitOutput("remote", "get-url", remote)
+		if err == nil {
+			repo.RemoteURL = strings.TrimSpace(string(output))
+		}
 	}
 
 	return repo, nil
 }
+
+// GetRepositoryRoot returns the top-level directory of the repository.
+func (repo *Repository) GetRepositoryRoot() (string, error) {
+	output, err := repo.gitOutput("rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", fmt.Errorf("not a git repository")
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// FindRepositories returns the working trees of the git repositories found
+// under root, sorted by path. A directory holding a .git directory or file is a
+// repository and is not searched further, so submodules and repositories
+// nested in another one are skipped. Directories that cannot be read are
+// ignored.
+func FindRepositories(root string) ([]string, error) {
+	if _, err := os.Stat(root); err != nil {
+		return nil, err
+	}
+
+	var repos []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			return nil
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if d.Name() == ".git" {
+			return fs.SkipDir
+		}
+		if _, err := os.Lstat(filepath.Join(path, ".git")); err == nil {
+			repos = append(repos, path)
+			return fs.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(repos)
+	return repos, nil
+}
+
+// GetConfigEntries returns all git config entries whose key starts with
+// prefix, keyed by the (lowercased) variable name. Multi-valued keys keep
+// every value in order.
+func (repo *Repository) GetConfigEntries(prefix string) map[string][]string {
+	entries := make(map[string][]string)
+
+	output, err := repo.gitOutput("config", "--null", "--get-regexp", "^"+regexp.QuoteMeta(prefix))
+	if err != nil {
+		// Exit status 1 means no matching keys
+		return entries
+	}
+
+	// With --null each entry is "key\nvalue\x00"
+	for _, entry := range strings.Split(string(output), "\x00") {
+		if entry == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(entry, "\n")
+		entries[key] = append(entries[key], value)
+	}
+	return entries
+}
@@ -0,0 +1,172 @@
+package git
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultNightlyPattern matches nightly build tags when no pattern is configured.
+const DefaultNightlyPattern = "nightly*"
+
+// RetentionPolicy describes which tags "git-gone tags prune" keeps. Zero
+// values disable a rule.
+type RetentionPolicy struct {
+	// KeepPatches keeps the last N patch releases of every major.minor line.
+	KeepPatches int
+	// DropPrereleases drops pre-release tags (-rc, -beta, ...) once the final
+	// release they lead up to is tagged.
+	DropPrereleases bool
+	// KeepNightlies keeps only the latest N tags matching NightlyPattern.
+	KeepNightlies int
+	// NightlyPattern is a glob matching nightly tags, DefaultNightlyPattern if empty.
+	NightlyPattern string
+	// Protected lists glob patterns of tags that are always kept.
+	Protected []string
+}
+
+// IsEmpty returns true if the policy has no rule that could drop a tag.
+func (p RetentionPolicy) IsEmpty() bool {
+	return p.KeepPatches <= 0 && !p.DropPrereleases && p.KeepNightlies <= 0
+}
+
+// TagDecision is the outcome of a retention policy for a single tag.
+type TagDecision struct {
+	Tag    string
+	Keep   bool
+	Reason string
+}
+
+// GetTagDates returns the creation date of every local tag: the tagger date
+// for annotated tags and the commit date for lightweight tags.
+func (repo *Repository) GetTagDates() (map[string]time.Time, error) {
+	output, err := repo.gitOutput("for-each-ref", "--format=%(refname:lstrip=2)%09%(creatordate:unix)", "refs/tags/")
+	if err != nil {
+		return nil, err
+	}
+
+	dates := make(map[string]time.Time)
+	for _, line := range strings.Split(string(output), "\n") {
+		name, timestamp, ok := strings.Cut(strings.TrimSpace(line), "\t")
+		if !ok {
+			continue
+		}
+		seconds, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			continue
+		}
+		dates[name] = time.Unix(seconds, 0)
+	}
+	return dates, nil
+}
+
+// ApplyRetention decides for every tag whether the policy keeps or drops it.
+// Tags that no rule applies to are kept. Decisions are returned in version
+// order, followed by the tags that are not semantic versions.
+func ApplyRetention(tags []string, dates map[string]time.Time, policy RetentionPolicy) []TagDecision {
+	nightlyPattern := policy.NightlyPattern
+	if nightlyPattern == "" {
+		nightlyPattern = DefaultNightlyPattern
+	}
+
+	decisions := make(map[string]TagDecision)
+	versions := make(map[string]Version)
+	finals := make(map[Version]string)
+	var nightlies []string
+	for _, tag := range tags {
+		if pattern, protected := MatchProtectedPattern(tag, policy.Protected); protected {
+			decisions[tag] = TagDecision{Tag: tag, Keep: true, Reason: "protected by " + pattern}
+			continue
+		}
+		if matched, err := path.Match(nightlyPattern, tag); err == nil && matched {
+			nightlies = append(nightlies, tag)
+			continue
+		}
+		if v, ok := ParseVersion(tag); ok {
+			versions[tag] = v
+			if !v.IsPrerelease() {
+				finals[v] = tag
+			}
+			continue
+		}
+		decisions[tag] = TagDecision{Tag: tag, Keep: true, Reason: "not a semantic version"}
+	}
+
+	// Nightlies: keep the most recent ones
+	sort.SliceStable(nightlies, func(i, j int) bool {
+		di, dj := dates[nightlies[i]], dates[nightlies[j]]
+		if !di.Equal(dj) {
+			return di.After(dj)
+		}
+		return nightlies[i] > nightlies[j]
+	})
+	for i, tag := range nightlies {
+		switch {
+		case policy.KeepNightlies <= 0:
+			decisions[tag] = TagDecision{Tag: tag, Keep: true, Reason: "nightly"}
+		case i < policy.KeepNightlies:
+			decisions[tag] = TagDecision{Tag: tag, Keep: true, Reason: fmt.Sprintf("one of the latest %d nightlies", policy.KeepNightlies)}
+		default:
+			decisions[tag] = TagDecision{Tag: tag, Reason: fmt.Sprintf("older than the latest %d nightlies", policy.KeepNightlies)}
+		}
+	}
+
+	// Final releases: keep the last patch releases of every minor line
+	lines := make(map[string][]string)
+	for tag, v := range versions {
+		if !v.IsPrerelease() {
+			line := fmt.Sprintf("%d.%d", v.Major, v.Minor)
+			lines[line] = append(lines[line], tag)
+		}
+	}
+	for line, releases := range lines {
+		sort.Slice(releases, func(i, j int) bool {
+			return CompareVersions(versions[releases[i]], versions[releases[j]]) > 0
+		})
+		for i, tag := range releases {
+			switch {
+			case policy.KeepPatches <= 0:
+				decisions[tag] = TagDecision{Tag: tag, Keep: true, Reason: "release"}
+			case i < policy.KeepPatches:
+				decisions[tag] = TagDecision{Tag: tag, Keep: true, Reason: fmt.Sprintf("one of the last %d patch releases of %s", policy.KeepPatches, line)}
+			default:
+				decisions[tag] = TagDecision{Tag: tag, Reason: fmt.Sprintf("older than the last %d patch releases of %s", policy.KeepPatches, line)}
+			}
+		}
+	}
+
+	// Pre-releases: drop those whose final release is tagged
+	for tag, v := range versions {
+		if !v.IsPrerelease() {
+			continue
+		}
+		final, released := finals[v.Final()]
+		if policy.DropPrereleases && released {
+			decisions[tag] = TagDecision{Tag: tag, Reason: "pre-release of " + final}
+		} else {
+			decisions[tag] = TagDecision{Tag: tag, Keep: true, Reason: "pre-release"}
+		}
+	}
+
+	ordered := make([]TagDecision, 0, len(decisions))
+	for _, d := range decisions {
+		ordered = append(ordered, d)
+	}
+	sort.Slice(ordered, func(i, j int) bool {
+		vi, iok := ParseVersion(ordered[i].Tag)
+		vj, jok := ParseVersion(ordered[j].Tag)
+		switch {
+		case iok && jok:
+			if c := CompareVersions(vi, vj); c != 0 {
+				return c < 0
+			}
+		case iok != jok:
+			return iok
+		}
+		return ordered[i].Tag < ordered[j].Tag
+	})
+	return ordered
+}
@@ -0,0 +1,275 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Runner executes git commands. Every operation in this package goes through
+// the Runner of its Repository, so commands can be logged or scripted.
+type Runner interface {
+	// Run executes git with args. env holds extra environment variables on
+	// top of the process environment. The error is non-nil if git could not
+	// be started or exited with a non-zero status.
+	Run(env []string, args ...string) (Result, error)
+}
+
+// InputRunner is implemented by runners that can also write to the standard
+// input of git, for commands reading from --stdin.
+type InputRunner interface {
+	// RunInput executes git with args and stdin as its standard input.
+	RunInput(stdin []byte, args ...string) (Result, error)
+}
+
+// Result holds the output of a git command.
+type Result struct {
+	Stdout []byte
+	Stderr []byte
+}
+
+// runner returns the Runner of the repository.
+func (repo *Repository) runner() Runner {
+	if repo.Runner == nil {
+		return ExecRunner{}
+	}
+	return repo.Runner
+}
+
+// args prefixes git arguments with the directory of the repository.
+func (repo *Repository) args(args []string) []string {
+	if repo.Dir == "" {
+		return args
+	}
+	return append([]string{"-C", repo.Dir}, args...)
+}
+
+// run runs git in the repository through its Runner.
+func (repo *Repository) run(env []string, args ...string) (Result, error) {
+	return repo.runner().Run(env, repo.args(args)...)
+}
+
+// gitOutput runs git and returns its standard output, like exec.Cmd.Output.
+func (repo *Repository) gitOutput(args ...string) ([]byte, error) {
+	result, err := repo.run(nil, args...)
+	return result.Stdout, err
+}
+
+// gitCombinedOutput runs git and returns its standard output followed by its
+// standard error, for commands whose messages end up in errors.
+func (repo *Repository) gitCombinedOutput(args ...string) ([]byte, error) {
+	result, err := repo.run(nil, args...)
+	return append(result.Stdout, result.Stderr...), err
+}
+
+// gitRun runs git and only reports whether it succeeded.
+func (repo *Repository) gitRun(args ...string) error {
+	_, err := repo.run(nil, args...)
+	return err
+}
+
+// gitInput runs git with stdin as its standard input and returns its standard
+// output. It fails if the runner of the repository is not an InputRunner.
+func (repo *Repository) gitInput(stdin []byte, args ...string) ([]byte, error) {
+	result, err := repo.runInput(stdin, args...)
+	return result.Stdout, err
+}
+
+// runInput runs git with stdin as its standard input through the runner of
+// the repository, which must be an InputRunner.
+func (repo *Repository) runInput(stdin []byte, args ...string) (Result, error) {
+	r, ok := repo.runner().(InputRunner)
+	if !ok {
+		return Result{}, fmt.Errorf("runner cannot feed standard input to git %s", strings.Join(args, " "))
+	}
+	return r.RunInput(stdin, repo.args(args)...)
+}
+
+// ExecRunner runs git as a subprocess. LC_ALL=C is always set so that git
+// output is in English and can be parsed on every platform.
+type ExecRunner struct{}
+
+// Run executes git with args.
+func (r ExecRunner) Run(env []string, args ...string) (Result, error) {
+	return r.run(nil, env, args)
+}
+
+// RunInput executes git with args and stdin as its standard input.
+func (r ExecRunner) RunInput(stdin []byte, args ...string) (Result, error) {
+	return r.run(stdin, nil, args)
+}
+
+func (ExecRunner) run(stdin []byte, env []string, args []string) (Result, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Env = append(append(os.Environ(), "LC_ALL=C"), env...)
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	return Result{Stdout: stdout.Bytes(), Stderr: stderr.Bytes()}, err
+}
+
+// RecordingRunner wraps another Runner and logs every command it runs, for
+// --verbose and --trace.
+type RecordingRunner struct {
+	// Runner executes the commands.
+	Runner Runner
+	// Out receives one line per command; nil disables logging.
+	Out io.Writer
+	// Trace also logs the duration, exit status and output of every command.
+	Trace bool
+
+	mu       sync.Mutex
+	commands [][]string
+}
+
+// NewRecordingRunner returns a RecordingRunner that logs the commands run by
+// r to out.
+func NewRecordingRunner(r Runner, out io.Writer, trace bool) *RecordingRunner {
+	return &RecordingRunner{Runner: r, Out: out, Trace: trace}
+}
+
+// Run executes git with args through the wrapped Runner and logs it.
+func (r *RecordingRunner) Run(env []string, args ...string) (Result, error) {
+	start := time.Now()
+	result, err := r.Runner.Run(env, args...)
+	r.record(start, args, result, err)
+	return result, err
+}
+
+// RunInput executes git with args and stdin through the wrapped Runner, which
+// must be an InputRunner, and logs it.
+func (r *RecordingRunner) RunInput(stdin []byte, args ...string) (Result, error) {
+	inner, ok := r.Runner.(InputRunner)
+	if !ok {
+		return Result{}, fmt.Errorf("runner cannot feed standard input to git %s", strings.Join(args, " "))
+	}
+	start := time.Now()
+	result, err := inner.RunInput(stdin, args...)
+	r.record(start, args, result, err)
+	return result, err
+}
+
+func (r *RecordingRunner) record(start time.Time, args []string, result Result, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.commands = append(r.commands, append([]string(nil), args...))
+	if r.Out == nil {
+		return
+	}
+
+	fmt.Fprintf(r.Out, "+ git %s\n", strings.Join(args, " "))
+	if r.Trace {
+		status := "ok"
+		if err != nil {
+			status = err.Error()
+		}
+		fmt.Fprintf(r.Out, "  (%s, %s)\n", time.Since(start).Round(time.Microsecond), status)
+		for _, stream := range [][]byte{result.Stdout, result.Stderr} {
+			for _, line := range strings.Split(strings.TrimRight(string(stream), "\n"), "\n") {
+				if line != "" {
+					fmt.Fprintf(r.Out, "  | %s\n", line)
+				}
+			}
+		}
+	}
+}
+
+// Commands returns the arguments of every command run so far, in order.
+func (r *RecordingRunner) Commands() [][]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([][]string(nil), r.commands...)
+}
+
+// FakeRunner is a scripted Runner for tests. Commands are matched by their
+// exact arguments; commands without a scripted response fail.
+type FakeRunner struct {
+	mu        sync.Mutex
+	responses map[string]fakeResponse
+	calls     [][]string
+}
+
+type fakeResponse struct {
+	result Result
+	err    error
+}
+
+// NewFakeRunner returns a FakeRunner with no scripted commands.
+func NewFakeRunner() *FakeRunner {
+	return &FakeRunner{responses: make(map[string]fakeResponse)}
+}
+
+// Stub makes the command with the given arguments succeed with stdout.
+func (f *FakeRunner) Stub(stdout string, args ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.responses[fakeKey(args)] = fakeResponse{result: Result{Stdout: []byte(stdout)}}
+}
+
+// StubError makes the command with the given arguments fail with stderr.
+func (f *FakeRunner) StubError(stderr string, args ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.responses[fakeKey(args)] = fakeResponse{
+		result: Result{Stderr: []byte(stderr)},
+		err:    fmt.Errorf("exit status 1"),
+	}
+}
+
+// StubInput makes the command with the given arguments succeed with stdout
+// when it reads stdin. It takes precedence over a Stub of the same command.
+func (f *FakeRunner) StubInput(stdout, stdin string, args ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.responses[fakeInputKey(args, []byte(stdin))] = fakeResponse{result: Result{Stdout: []byte(stdout)}}
+}
+
+// Run returns the scripted response for args.
+func (f *FakeRunner) Run(env []string, args ...string) (Result, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, append([]string(nil), args...))
+	response, ok := f.responses[fakeKey(args)]
+	if !ok {
+		return Result{}, fmt.Errorf("unscripted command: git %s", strings.Join(args, " "))
+	}
+	return response.result, response.err
+}
+
+// RunInput returns the scripted response for args and stdin, or for args
+// alone if none was scripted for stdin.
+func (f *FakeRunner) RunInput(stdin []byte, args ...string) (Result, error) {
+	f.mu.Lock()
+	response, ok := f.responses[fakeInputKey(args, stdin)]
+	if ok {
+		f.calls = append(f.calls, append([]string(nil), args...))
+		f.mu.Unlock()
+		return response.result, response.err
+	}
+	f.mu.Unlock()
+	return f.Run(nil, args...)
+}
+
+// Calls returns the arguments of every command run so far, in order.
+func (f *FakeRunner) Calls() [][]string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([][]string(nil), f.calls...)
+}
+
+func fakeKey(args []string) string {
+	return strings.Join(args, "\x00")
+}
+
+func fakeInputKey(args []string, stdin []byte) string {
+	return fakeKey(args) + "\x00<stdin>\x00" + string(stdin)
+}
@@ -0,0 +1,111 @@
+package git
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Version is a semantic version parsed from a tag name.
+type Version struct {
+	Major      int
+	Minor      int
+	Patch      int
+	Prerelease string // e.g. "rc.1", empty for final releases
+}
+
+// IsPrerelease returns true for versions such as 1.2.0-rc.1.
+func (v Version) IsPrerelease() bool {
+	return v.Prerelease != ""
+}
+
+// Final returns the final release the version belongs to, without its pre-release.
+func (v Version) Final() Version {
+	return Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch}
+}
+
+// String returns the version as MAJOR.MINOR.PATCH[-PRERELEASE].
+func (v Version) String() string {
+	s := strconv.Itoa(v.Major) + "." + strconv.Itoa(v.Minor) + "." + strconv.Itoa(v.Patch)
+	if v.Prerelease != "" {
+		s += "-" + v.Prerelease
+	}
+	return s
+}
+
+// ParseVersion parses a tag name such as v1.2.3, 1.2.3-rc.1 or v1.2.3+build.5
+// as a semantic version. Build metadata is ignored.
+func ParseVersion(tag string) (Version, bool) {
+	s := strings.TrimPrefix(tag, "v")
+	s, _, _ = strings.Cut(s, "+")
+	s, prerelease, hasPrerelease := strings.Cut(s, "-")
+	if hasPrerelease && prerelease == "" {
+		return Version{}, false
+	}
+
+	parts := strings.Split(s, ".")
+	if len(parts) != 3 {
+		return Version{}, false
+	}
+	numbers := make([]int, 3)
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 || (len(part) > 1 && part[0] == '0') {
+			return Version{}, false
+		}
+		numbers[i] = n
+	}
+	return Version{Major: numbers[0], Minor: numbers[1], Patch: numbers[2], Prerelease: prerelease}, true
+}
+
+// CompareVersions compares two versions by semantic version precedence and
+// returns -1, 0 or 1. A pre-release sorts before its final release.
+func CompareVersions(a, b Version) int {
+	for _, d := range []int{a.Major - b.Major, a.Minor - b.Minor, a.Patch - b.Patch} {
+		if d != 0 {
+			return sign(d)
+		}
+	}
+
+	switch {
+	case a.Prerelease == b.Prerelease:
+		return 0
+	case a.Prerelease == "":
+		return 1
+	case b.Prerelease == "":
+		return -1
+	}
+
+	// Compare dot-separated identifiers: numeric ones numerically and before
+	// alphanumeric ones; a shorter list of equal identifiers sorts first
+	ai, bi := strings.Split(a.Prerelease, "."), strings.Split(b.Prerelease, ".")
+	for i := 0; i < len(ai) && i < len(bi); i++ {
+		an, aErr := strconv.Atoi(ai[i])
+		bn, bErr := strconv.Atoi(bi[i])
+		switch {
+		case aErr == nil && bErr == nil:
+			if an != bn {
+				return sign(an - bn)
+			}
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		default:
+			if c := strings.Compare(ai[i], bi[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	return sign(len(ai) - len(bi))
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	default:
+		return 0
+	}
+}
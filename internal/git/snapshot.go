@@ -0,0 +1,618 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BranchRef describes a local branch as listed by git for-each-ref.
+type BranchRef struct {
+	Name string
+	// SHA is the commit the branch points to.
+	SHA string
+	// Remote is the remote configured for the branch, "" if it has none.
+	Remote string
+	// Upstream is the short name of the upstream branch, e.g. origin/feature.
+	Upstream string
+	// Gone is true if the upstream branch no longer exists.
+	Gone bool
+	// UpstreamDivergence compares the branch with its upstream.
+	UpstreamDivergence Divergence
+	// CommitDate is the committer date of the tip commit.
+	CommitDate time.Time
+}
+
+// RemoteStatus returns the tracking status of the branch, like GetRemoteStatus.
+func (r BranchRef) RemoteStatus() RemoteStatus {
+	switch {
+	case r.Remote == "":
+		return NoTracking
+	case r.Gone:
+		return TrackingGone
+	default:
+		return TrackingActive
+	}
+}
+
+// HasUpstream reports whether the branch has an upstream branch that still
+// exists, like GetUpstreamDivergence.
+func (r BranchRef) HasUpstream() bool {
+	return r.Upstream != "" && !r.Gone
+}
+
+// BranchSnapshot holds what branch analysis needs to know about every local
+// branch and the integration targets of a BranchContext.
+//
+// LoadBranchSnapshot reads it with a fixed number of git commands, however
+// many branches the repository has: one for-each-ref for the branches and, per
+// target, one for-each-ref --merged, two rev-list and a few commands for the
+// merge base and patch-ids. Ancestry, divergence and merge checks are then
+// answered from memory.
+//
+// Only the commits the branches have and a target lacks, and the target
+// commits since the merge base of all branches, are read: memory grows with
+// the work on the branches, not with the history of the repository.
+//
+// With the native backend, branches, merge status and divergence are read in
+// process like the per-branch queries; the patch-ids of the rebase and squash
+// checks are still computed by git.
+type BranchSnapshot struct {
+	repo    *Repository
+	ctx     BranchContext
+	native  *nativeRepo
+	names   []string
+	refs    map[string]BranchRef
+	targets map[string]*targetState
+}
+
+// targetState holds what a snapshot knows about one integration target.
+type targetState struct {
+	tip string
+	// merged holds the branches whose tip the target contains.
+	merged map[string]bool
+	// graph holds the parents of the commits in ahead and landed.
+	graph *commitGraph
+	// ahead holds the commits of the unmerged branches the target lacks.
+	ahead map[string]struct{}
+	// landed holds the target commits since the merge base of every branch,
+	// newest first in landedOrder. Older commits are shared by every branch.
+	landed      map[string]struct{}
+	landedOrder []string
+
+	// The fields below are only filled for the branches that need rebase and
+	// squash checks, and only if they share history with the target.
+
+	// unique lists the non-merge commits of each branch the target lacks.
+	unique map[string][]string
+	// commitIDs maps branch and target commits to their patch-id.
+	commitIDs map[string]string
+	// targetIDs holds the patch-ids of the target commits.
+	targetIDs map[string]bool
+	// squashIDs maps branch tips to the patch-id of all their changes since
+	// the merge base, as a squash merge would apply them.
+	squashIDs map[string]string
+}
+
+// LoadBranchSnapshot reads the local branches and their relation to the
+// targets of ctx.
+//
+// Targets that cannot be resolved or read, or whose patch-ids cannot be read,
+// are skipped: the snapshot is returned together with the errors, as with
+// ClassifyBranches. Failing to list the branches is fatal.
+func (repo *Repository) LoadBranchSnapshot(ctx BranchContext) (*BranchSnapshot, error) {
+	s := &BranchSnapshot{
+		repo:    repo,
+		ctx:     ctx,
+		native:  repo.nativeBackend(),
+		refs:    make(map[string]BranchRef),
+		targets: make(map[string]*targetState),
+	}
+	refs, err := repo.listBranchRefs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+	for _, ref := range refs {
+		s.names = append(s.names, ref.Name)
+		s.refs[ref.Name] = ref
+	}
+
+	var errs []error
+	for _, target := range ctx.Targets {
+		output, err := repo.gitOutput("rev-parse", "--verify", "--quiet", target+"^{commit}")
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to resolve target %s", target))
+			continue
+		}
+		t := &targetState{tip: strings.TrimSpace(string(output))}
+		if err := s.readTarget(t); err != nil {
+			errs = append(errs, fmt.Errorf("failed to compare branches with %s: %w", target, err))
+			continue
+		}
+		s.targets[target] = t
+	}
+
+	// Only branches that survive the cheaper checks are compared by patch-id.
+	// Gone branches are too: a merge proves they may be force-deleted.
+	var pending []string
+	for _, name := range s.names {
+		if ProtectionRule(name, ctx) != "" {
+			continue
+		}
+		if _, merged := s.mergedInto(name); !merged {
+			pending = append(pending, name)
+		}
+	}
+	if len(pending) > 0 {
+		for _, target := range ctx.Targets {
+			t, ok := s.targets[target]
+			if !ok {
+				continue
+			}
+			if err := s.readPatchIDs(t, pending); err != nil {
+				errs = append(errs, fmt.Errorf("failed to compare branches with %s: %w", target, err))
+			}
+		}
+	}
+	return s, errors.Join(errs...)
+}
+
+// readTarget reads which branches target t contains and the part of the
+// commit graph that tells the others apart from it.
+func (s *BranchSnapshot) readTarget(t *targetState) error {
+	merged, err := s.mergedBranches(t.tip)
+	if err != nil {
+		return err
+	}
+	t.merged = make(map[string]bool)
+	for _, name := range merged {
+		t.merged[name] = true
+	}
+
+	// The commits the unmerged branches add on top of the target
+	revisions := []string{"^" + t.tip}
+	for _, name := range s.names {
+		if !t.merged[name] {
+			revisions = append(revisions, s.refs[name].SHA)
+		}
+	}
+	t.graph = &commitGraph{parents: make(map[string][]string), counts: make(map[string]int)}
+	if t.ahead, _, err = t.graph.read(s.repo, revisions); err != nil {
+		return err
+	}
+
+	// Every branch shares the history before the merge base of all the
+	// commits where it meets the target, so only newer target commits matter.
+	// Without a common merge base the whole target history is read.
+	var bases []string
+	seen := make(map[string]bool)
+	for _, name := range s.names {
+		boundary := []string{s.refs[name].SHA}
+		if !t.merged[name] {
+			_, boundary = t.graph.walk(s.refs[name].SHA, t.ahead)
+		}
+		for _, base := range boundary {
+			if !seen[base] {
+				seen[base] = true
+				bases = append(bases, base)
+			}
+		}
+	}
+	revisions = []string{t.tip}
+	if base := s.repo.mergeBase(bases); base != "" {
+		revisions = append(revisions, "^"+base)
+	}
+	t.landed, t.landedOrder, err = t.graph.read(s.repo, revisions)
+	return err
+}
+
+// mergedBranches returns the branches whose tip is reachable from tip.
+func (s *BranchSnapshot) mergedBranches(tip string) ([]string, error) {
+	if s.native != nil {
+		if merged, err := s.native.mergedBranches(tip); err == nil {
+			return merged, nil
+		}
+	}
+	output, err := s.repo.gitOutput("for-each-ref", "--merged="+tip, "--format=%(refname:lstrip=2)", "refs/heads/")
+	if err != nil {
+		return nil, err
+	}
+	var merged []string
+	for _, name := range strings.Split(string(output), "\n") {
+		if name != "" {
+			merged = append(merged, name)
+		}
+	}
+	return merged, nil
+}
+
+// mergeBase returns the best common ancestor of the commits, "" if they have
+// none.
+func (repo *Repository) mergeBase(commits []string) string {
+	switch len(commits) {
+	case 0:
+		return ""
+	case 1:
+		return commits[0]
+	}
+	output, err := repo.gitOutput(append([]string{"merge-base", "--octopus"}, commits...)...)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// listBranchRefs lists every local branch with a single for-each-ref.
+func (repo *Repository) listBranchRefs() ([]BranchRef, error) {
+	if n := repo.nativeBackend(); n != nil {
+		if refs, err := n.branchRefs(); err == nil {
+			return refs, nil
+		}
+	}
+
+	output, err := repo.gitOutput("for-each-ref",
+		"--format=%(refname:lstrip=2)%09%(objectname)%09%(upstream:remotename)%09%(upstream:short)%09%(upstream:track)%09%(committerdate:unix)",
+		"refs/heads/")
+	if err != nil {
+		return nil, err
+	}
+
+	var refs []BranchRef
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 6 || fields[0] == "" {
+			continue
+		}
+		ref := BranchRef{
+			Name:     fields[0],
+			SHA:      fields[1],
+			Remote:   fields[2],
+			Upstream: fields[3],
+		}
+		ref.UpstreamDivergence, ref.Gone = parseTrack(fields[4])
+		if seconds, err := strconv.ParseInt(fields[5], 10, 64); err == nil {
+			ref.CommitDate = time.Unix(seconds, 0)
+		}
+		refs = append(refs, ref)
+	}
+	return refs, nil
+}
+
+// parseTrack parses %(upstream:track), e.g. "[ahead 1, behind 2]" or "[gone]".
+func parseTrack(track string) (Divergence, bool) {
+	track = strings.TrimSuffix(strings.TrimPrefix(track, "["), "]")
+	if track == "gone" {
+		return Divergence{}, true
+	}
+
+	var divergence Divergence
+	for _, part := range strings.Split(track, ", ") {
+		fields := strings.Fields(part)
+		if len(fields) != 2 {
+			continue
+		}
+		n, err := strconv.Atoi(fields[1])
+		if err != nil {
+			continue
+		}
+		switch fields[0] {
+		case "ahead":
+			divergence.Ahead = n
+		case "behind":
+			divergence.Behind = n
+		}
+	}
+	return divergence, false
+}
+
+// readPatchIDs computes the patch-ids the rebase and squash checks of the
+// pending branches need against target t.
+//
+// Every branch commit the target lacks and every target commit since the
+// merge bases is diffed in one git diff-tree --stdin, and the squash diff of
+// every branch (merge base to tip) in another; git patch-id hashes each.
+func (s *BranchSnapshot) readPatchIDs(t *targetState, pending []string) error {
+	t.unique = make(map[string][]string)
+	var commits, squashes, bases []string
+	seenBase := make(map[string]bool)
+	seenTip := make(map[string]bool)
+	for _, name := range pending {
+		tip := s.refs[name].SHA
+		ahead, boundary := t.graph.walk(tip, t.ahead)
+		if len(boundary) == 0 {
+			// No common history: nothing to compare
+			continue
+		}
+
+		unique := []string{}
+		for _, commit := range ahead {
+			if len(t.graph.parents[commit]) == 1 {
+				unique = append(unique, commit)
+			}
+		}
+		t.unique[name] = unique
+		if seenTip[tip] {
+			continue
+		}
+		seenTip[tip] = true
+		commits = append(commits, unique...)
+
+		base := t.bestBase(boundary)
+		squashes = append(squashes, tip+" "+base)
+		if !seenBase[base] {
+			seenBase[base] = true
+			bases = append(bases, base)
+		}
+	}
+	if len(bases) == 0 {
+		return nil
+	}
+
+	// The target commits since the common ancestor of all merge bases cover
+	// every commit a branch could have been rebased or squashed into
+	shared := t.graph.reach([]string{s.repo.mergeBase(bases)}, t.landed)
+	var targetCommits []string
+	for _, commit := range t.landedOrder {
+		if _, old := shared[commit]; !old && len(t.graph.parents[commit]) == 1 {
+			targetCommits = append(targetCommits, commit)
+		}
+	}
+
+	ids, err := s.repo.patchIDs(append(targetCommits, commits...))
+	if err != nil {
+		return err
+	}
+	t.commitIDs = ids
+	t.targetIDs = make(map[string]bool)
+	for _, commit := range targetCommits {
+		if id, ok := ids[commit]; ok {
+			t.targetIDs[id] = true
+		}
+	}
+
+	t.squashIDs, err = s.repo.patchIDs(squashes)
+	return err
+}
+
+// patchIDs returns the patch-id of every revision, keyed by commit. Each
+// revision is either a commit, compared with its parent, or "<commit> <base>",
+// compared with base. Revisions without changes have no patch-id.
+func (repo *Repository) patchIDs(revisions []string) (map[string]string, error) {
+	ids := make(map[string]string)
+	if len(revisions) == 0 {
+		return ids, nil
+	}
+
+	diff, err := repo.gitInput([]byte(strings.Join(revisions, "\n")+"\n"), "diff-tree", "-r", "-p", "--stdin")
+	if err != nil {
+		return nil, fmt.Errorf("git diff-tree failed: %w", err)
+	}
+	if len(diff) == 0 {
+		return ids, nil
+	}
+	output, err := repo.gitInput(diff, "patch-id", "--stable")
+	if err != nil {
+		return nil, fmt.Errorf("git patch-id failed: %w", err)
+	}
+
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 {
+			ids[fields[1]] = fields[0]
+		}
+	}
+	return ids, nil
+}
+
+// Branches returns the names of the local branches, sorted.
+func (s *BranchSnapshot) Branches() []string {
+	return s.names
+}
+
+// Ref returns the branch with the given name.
+func (s *BranchSnapshot) Ref(name string) (BranchRef, bool) {
+	ref, ok := s.refs[name]
+	return ref, ok
+}
+
+// Classify classifies every local branch like ClassifyBranches, without
+// running git.
+func (s *BranchSnapshot) Classify() []BranchClassification {
+	return s.classify(s.names)
+}
+
+// Divergence returns how far a branch is ahead of and behind a target, like
+// GetDivergence. It returns false if either is unknown, or if the branch has
+// no history in common with the target.
+func (s *BranchSnapshot) Divergence(branch, target string) (Divergence, bool) {
+	ref, ok := s.refs[branch]
+	t, known := s.targets[target]
+	if !ok || !known {
+		return Divergence{}, false
+	}
+	if s.native != nil {
+		if divergence, err := s.native.divergence(ref.SHA, t.tip); err == nil {
+			return divergence, true
+		}
+	}
+	var ahead, boundary []string
+	if t.merged[branch] {
+		boundary = []string{ref.SHA}
+	} else {
+		ahead, boundary = t.graph.walk(ref.SHA, t.ahead)
+	}
+	if len(boundary) == 0 {
+		return Divergence{}, false
+	}
+	// The target commits before landed are shared with every branch
+	return Divergence{Ahead: len(ahead), Behind: len(t.landed) - len(t.graph.reach(boundary, t.landed))}, true
+}
+
+// Cherry compares the commits of a branch with a target by patch-id, like
+// GetCherryStatus. It returns false for branches that were not compared:
+// protected, gone and merged branches, and branches without history in
+// common with the target.
+func (s *BranchSnapshot) Cherry(branch, target string) (CherryResult, bool) {
+	t, ok := s.targets[target]
+	if !ok || t.unique == nil {
+		return CherryResult{}, false
+	}
+	unique, ok := t.unique[branch]
+	if !ok {
+		return CherryResult{}, false
+	}
+
+	var result CherryResult
+	for _, commit := range unique {
+		if id, ok := t.commitIDs[commit]; ok && t.targetIDs[id] {
+			result.Equivalent++
+		} else {
+			result.Unique++
+		}
+	}
+	return result, true
+}
+
+func (s *BranchSnapshot) isGone(branch string) bool {
+	return s.refs[branch].Gone
+}
+
+func (s *BranchSnapshot) mergedInto(branch string) (string, bool) {
+	for _, target := range s.ctx.Targets {
+		t, ok := s.targets[target]
+		if !ok {
+			continue
+		}
+		if t.merged[branch] {
+			return target, true
+		}
+	}
+	return "", false
+}
+
+func (s *BranchSnapshot) rebaseMergedInto(branch string) (string, bool) {
+	for _, target := range s.ctx.Targets {
+		if result, ok := s.Cherry(branch, target); ok && result.FullyAbsorbed() {
+			return target, true
+		}
+	}
+	return "", false
+}
+
+func (s *BranchSnapshot) squashMergedInto(branch string) (string, bool) {
+	tip := s.refs[branch].SHA
+	for _, target := range s.ctx.Targets {
+		t, ok := s.targets[target]
+		if !ok || t.squashIDs == nil {
+			continue
+		}
+		if id, ok := t.squashIDs[tip]; ok && t.targetIDs[id] {
+			return target, true
+		}
+	}
+	return "", false
+}
+
+// commitGraph is the parent graph of part of the history. Parents outside
+// the part that was read are listed, but their own parents are not.
+type commitGraph struct {
+	parents map[string][]string
+	// counts caches the number of commits reach finds from single commits.
+	counts map[string]int
+}
+
+// read adds the commits git rev-list lists for the revisions in repo to the graph and
+// returns them, as a set and in rev-list order.
+func (g *commitGraph) read(repo *Repository, revisions []string) (map[string]struct{}, []string, error) {
+	set := make(map[string]struct{})
+	output, err := repo.gitInput([]byte(strings.Join(revisions, "\n")+"\n"), "rev-list", "--parents", "--stdin")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var order []string
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) > 0 {
+			g.parents[fields[0]] = fields[1:]
+			set[fields[0]] = struct{}{}
+			order = append(order, fields[0])
+		}
+	}
+	return set, order, nil
+}
+
+// walk returns the commits of set reachable from rev without leaving set, and
+// the commits outside set where the walk stopped (the merge bases and their
+// neighbours, when set holds what a target lacks).
+func (g *commitGraph) walk(rev string, set map[string]struct{}) (inside, boundary []string) {
+	seen := map[string]bool{rev: true}
+	queue := []string{rev}
+	for len(queue) > 0 {
+		commit := queue[0]
+		queue = queue[1:]
+		if _, ok := set[commit]; !ok {
+			boundary = append(boundary, commit)
+			continue
+		}
+		inside = append(inside, commit)
+		for _, parent := range g.parents[commit] {
+			if !seen[parent] {
+				seen[parent] = true
+				queue = append(queue, parent)
+			}
+		}
+	}
+	return inside, boundary
+}
+
+// reach returns the commits of set reachable from the given commits without
+// leaving set.
+func (g *commitGraph) reach(commits []string, set map[string]struct{}) map[string]struct{} {
+	found := make(map[string]struct{})
+	var queue []string
+	for _, commit := range commits {
+		if _, ok := set[commit]; ok {
+			found[commit] = struct{}{}
+			queue = append(queue, commit)
+		}
+	}
+	for len(queue) > 0 {
+		commit := queue[0]
+		queue = queue[1:]
+		for _, parent := range g.parents[commit] {
+			if _, ok := set[parent]; !ok {
+				continue
+			}
+			if _, ok := found[parent]; !ok {
+				found[parent] = struct{}{}
+				queue = append(queue, parent)
+			}
+		}
+	}
+	return found
+}
+
+// bestBase picks the merge base among the boundary commits of a walk: the one
+// with the most ancestors, which no other boundary commit descends from. The
+// history before landed is shared by all of them, so only landed commits are
+// counted.
+func (t *targetState) bestBase(boundary []string) string {
+	if len(boundary) == 1 {
+		return boundary[0]
+	}
+	best, bestCount := boundary[0], -1
+	for _, commit := range boundary {
+		count, ok := t.graph.counts[commit]
+		if !ok {
+			count = len(t.graph.reach([]string{commit}, t.landed))
+			t.graph.counts[commit] = count
+		}
+		if count > bestCount {
+			best, bestCount = commit, count
+		}
+	}
+	return best
+}
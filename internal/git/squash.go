@@ -0,0 +1,78 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// IsSquashMerged reports whether the changes of a branch were squash-merged
+// into target.
+//
+// The diff of the branch since its merge base with target, as a squash merge
+// would apply it, is compared by patch-id with every target commit the branch
+// lacks. Nothing is written to the repository.
+func (repo *Repository) IsSquashMerged(branch, target string) (bool, error) {
+	output, err := repo.gitOutput("rev-parse", "--verify", "--quiet", branch+"^{commit}")
+	if err != nil {
+		return false, fmt.Errorf("branch %s does not exist", branch)
+	}
+	tip := strings.TrimSpace(string(output))
+
+	output, err = repo.gitOutput("merge-base", target, tip)
+	if err != nil {
+		return false, fmt.Errorf("no merge base between %s and %s", branch, target)
+	}
+	mergeBase := strings.TrimSpace(string(output))
+
+	output, err = repo.gitOutput("rev-list", "--no-merges", target, "^"+tip)
+	if err != nil {
+		return false, fmt.Errorf("failed to list commits of %s: %w", target, err)
+	}
+	commits := strings.Fields(string(output))
+
+	// The branch tip is not among the target commits, so the squash diff keyed
+	// by it cannot be mistaken for one of them
+	ids, err := repo.patchIDs(append(commits, tip+" "+mergeBase))
+	if err != nil {
+		return false, err
+	}
+	squash, ok := ids[tip]
+	if !ok {
+		// The branch changes nothing
+		return false, nil
+	}
+	for _, commit := range commits {
+		if ids[commit] == squash {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// GetSquashMergedBranches returns the branches whose changes were squash-merged
+// into target. It reads a BranchSnapshot of target, so the number of git
+// commands does not depend on the number of branches. Branches merged into
+// target by ancestry, or sharing no history with it, are not squash-merged.
+//
+// Branches that do not exist are skipped, and the squash-merged branches found
+// are returned together with every error, as with ClassifyBranches.
+func (repo *Repository) GetSquashMergedBranches(target string, branches []string) ([]string, error) {
+	snapshot, err := repo.LoadBranchSnapshot(BranchContext{Targets: []string{target}})
+	if snapshot == nil {
+		return nil, err
+	}
+
+	errs := []error{err}
+	var squashed []string
+	for _, branch := range branches {
+		if _, ok := snapshot.Ref(branch); !ok {
+			errs = append(errs, fmt.Errorf("branch %s does not exist", branch))
+			continue
+		}
+		if _, ok := snapshot.squashMergedInto(branch); ok {
+			squashed = append(squashed, branch)
+		}
+	}
+	return squashed, errors.Join(errs...)
+}
@@ -0,0 +1,101 @@
+package git
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Stash is an entry of the stash list.
+type Stash struct {
+	Index     int    // n in stash@{n}
+	SHA       string // Commit holding the stashed changes
+	Branch    string // Branch the stash was created on, empty if HEAD was detached
+	Message   string
+	CreatedAt time.Time
+}
+
+// Ref returns the reflog name of the stash, e.g. stash@{2}.
+func (s Stash) Ref() string {
+	return fmt.Sprintf("stash@{%d}", s.Index)
+}
+
+// ListStashes returns all stash entries, most recent first.
+func (repo *Repository) ListStashes() ([]Stash, error) {
+	output, err := repo.gitOutput("stash", "list", "--format=%gd%x09%H%x09%ct%x09%gs")
+	if err != nil {
+		return nil, err
+	}
+
+	stashes := []Stash{}
+	for _, line := range strings.Split(string(output), "\n") {
+		parts := strings.SplitN(strings.TrimSpace(line), "\t", 4)
+		if len(parts) != 4 {
+			continue
+		}
+		index, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(parts[0], "stash@{"), "}"))
+		if err != nil {
+			continue
+		}
+		seconds, _ := strconv.ParseInt(parts[2], 10, 64)
+		branch, message := parseStashSubject(parts[3])
+		stashes = append(stashes, Stash{
+			Index:     index,
+			SHA:       parts[1],
+			Branch:    branch,
+			Message:   message,
+			CreatedAt: time.Unix(seconds, 0),
+		})
+	}
+	return stashes, nil
+}
+
+// parseStashSubject splits a stash subject such as "WIP on feature: abc123 msg"
+// or "On feature: msg" into the branch and the message.
+func parseStashSubject(subject string) (string, string) {
+	rest := subject
+	switch {
+	case strings.HasPrefix(rest, "WIP on "):
+		rest = strings.TrimPrefix(rest, "WIP on ")
+	case strings.HasPrefix(rest, "On "):
+		rest = strings.TrimPrefix(rest, "On ")
+	default:
+		return "", subject
+	}
+
+	branch, message, ok := strings.Cut(rest, ": ")
+	if !ok {
+		return "", subject
+	}
+	if branch == "(no branch)" {
+		branch = ""
+	}
+	return branch, message
+}
+
+// DropStashes drops the given stash entries. Entries are dropped from the
+// highest index down so earlier drops do not shift the remaining indices, and
+// each entry is checked to still point at its recorded commit first. The
+// dropped commits stay recoverable with "git stash store <sha>" until gc.
+func (repo *Repository) DropStashes(stashes []Stash) ([]Stash, error) {
+	sorted := append([]Stash(nil), stashes...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Index > sorted[j].Index
+	})
+
+	var dropped []Stash
+	for _, stash := range sorted {
+		output, err := repo.gitOutput("rev-parse", "--verify", "--quiet", stash.Ref())
+		if err != nil || strings.TrimSpace(string(output)) != stash.SHA {
+			return dropped, fmt.Errorf("%s no longer points at %s, stash list changed", stash.Ref(), stash.SHA)
+		}
+
+		if output, err := repo.gitCombinedOutput("stash", "drop", "--quiet", stash.Ref()); err != nil {
+			return dropped, fmt.Errorf("failed to drop %s: %s", stash.Ref(), string(output))
+		}
+		dropped = append(dropped, stash)
+	}
+	return dropped, nil
+}
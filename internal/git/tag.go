@@ -2,9 +2,10 @@ package git
 
 import (
 	"fmt"
-	"os"
-	"os/exec"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // Tag represents a local git tag.
@@ -12,6 +13,15 @@ type Tag struct {
 	Name           string
 	ExistsOnRemote bool
 	IsAnnotated    bool
+	// SHA is the object the tag ref points to: the tag object for annotated
+	// tags, the commit for lightweight tags.
+	SHA string
+	// Target is the commit (or other object) the tag ultimately points to.
+	Target string
+	// Tagger is "Name <email>" of the tagger, empty for lightweight tags.
+	Tagger string
+	// CreatedAt is the tagger date, or the commit date for lightweight tags.
+	CreatedAt time.Time
 }
 
 // IsStale returns true if the tag doesn't exist on the remote.
@@ -19,11 +29,43 @@ func (t *Tag) IsStale() bool {
 	return !t.ExistsOnRemote
 }
 
+// ListTags returns all local tags with their metadata. ExistsOnRemote is
+// not populated.
+func (repo *Repository) ListTags() ([]Tag, error) {
+	output, err := repo.gitOutput("for-each-ref",
+		"--format=%(refname:lstrip=2)%09%(objecttype)%09%(objectname)%09%(*objectname)%09%(taggername)%09%(taggeremail)%09%(creatordate:unix)",
+		"refs/tags/")
+	if err != nil {
+		return nil, err
+	}
+
+	tags := []Tag{}
+	for _, line := range strings.Split(string(output), "\n") {
+		parts := strings.Split(line, "\t")
+		if len(parts) != 7 {
+			continue
+		}
+		tag := Tag{
+			Name:        parts[0],
+			IsAnnotated: parts[1] == "tag",
+			SHA:         parts[2],
+			Target:      parts[2],
+		}
+		if tag.IsAnnotated {
+			tag.Target = parts[3]
+			tag.Tagger = strings.TrimSpace(parts[4] + " " + parts[5])
+		}
+		if seconds, err := strconv.ParseInt(parts[6], 10, 64); err == nil {
+			tag.CreatedAt = time.Unix(seconds, 0)
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
 // GetLocalTags returns all local tag names.
-func GetLocalTags() ([]string, error) {
-	cmd := exec.Command("git", "tag", "-l")
-	cmd.Env = append(os.Environ(), "LC_ALL=C")
-	output, err := cmd.Output()
+func (repo *Repository) GetLocalTags() ([]string, error) {
+	output, err := repo.gitOutput("tag", "-l")
 	if err != nil {
 		return nil, err
 	}
@@ -39,11 +81,9 @@ func GetLocalTags() ([]string, error) {
 	return tags, nil
 }
 
-// GetRemoteTags returns all tag names from the remote.
-func GetRemoteTags() ([]string, error) {
-	cmd := exec.Command("git", "ls-remote", "--tags", "origin")
-	cmd.Env = append(os.Environ(), "LC_ALL=C")
-	output, err := cmd.Output()
+// GetRemoteTags returns all tag names from the given remote.
+func (repo *Repository) GetRemoteTags(remote string) ([]string, error) {
+	output, err := repo.gitOutput("ls-remote", "--tags", remote)
 	if err != nil {
 		return nil, err
 	}
@@ -70,22 +110,28 @@ func GetRemoteTags() ([]string, error) {
 	return tags, nil
 }
 
-// GetStaleTags returns local tags that don't exist on the remote.
-func GetStaleTags() ([]string, error) {
-	localTags, err := GetLocalTags()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get local tags: %w", err)
+// GetStaleTags returns local tags that don't exist on any of the given
+// remotes. Passing several remotes compares against the union of their tags.
+func (repo *Repository) GetStaleTags(remotes ...string) ([]string, error) {
+	if len(remotes) == 0 {
+		return nil, fmt.Errorf("no remote to compare tags against")
 	}
 
-	remoteTags, err := GetRemoteTags()
+	localTags, err := repo.GetLocalTags()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get remote tags: %w", err)
+		return nil, fmt.Errorf("failed to get local tags: %w", err)
 	}
 
 	// Create a set of remote tags for quick lookup
 	remoteTagSet := make(map[string]bool)
-	for _, tag := range remoteTags {
-		remoteTagSet[tag] = true
+	for _, remote := range remotes {
+		remoteTags, err := repo.GetRemoteTags(remote)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get tags from %s: %w", remote, err)
+		}
+		for _, tag := range remoteTags {
+			remoteTagSet[tag] = true
+		}
 	}
 
 	// Find local tags not on remote
@@ -99,13 +145,164 @@ func GetStaleTags() ([]string, error) {
 	return staleTags, nil
 }
 
-// DeleteTag deletes a local tag.
-func DeleteTag(name string) error {
-	cmd := exec.Command("git", "tag", "-d", name)
-	cmd.Env = append(os.Environ(), "LC_ALL=C")
-	output, err := cmd.CombinedOutput()
+// DeleteTag deletes a local tag after backing it up in the trash namespace.
+// The tag is kept if it cannot be backed up.
+func (repo *Repository) DeleteTag(name string) error {
+	trashRef, err := repo.MoveToTrash("refs/tags/" + name)
+	if err != nil {
+		return err
+	}
+
+	output, err := repo.gitCombinedOutput("tag", "-d", name)
 	if err != nil {
+		_ = repo.DropTrashRef(trashRef)
 		return fmt.Errorf("%s", string(output))
 	}
 	return nil
 }
+
+// DivergentTag is a tag that exists locally and on a remote but points to
+// different objects, because it was retagged locally or moved on the remote.
+type DivergentTag struct {
+	Name      string
+	Remote    string
+	LocalSHA  string // Object the local tag ref points to
+	RemoteSHA string // Object the remote tag ref points to
+}
+
+// GetLocalTagObjects maps every local tag to the object its ref points to
+// (the tag object for annotated tags, the commit for lightweight tags).
+func (repo *Repository) GetLocalTagObjects() (map[string]string, error) {
+	output, err := repo.gitOutput("for-each-ref", "--format=%(refname:lstrip=2)%09%(objectname)", "refs/tags/")
+	if err != nil {
+		return nil, err
+	}
+
+	objects := make(map[string]string)
+	for _, line := range strings.Split(string(output), "\n") {
+		name, sha, ok := strings.Cut(strings.TrimSpace(line), "\t")
+		if ok {
+			objects[name] = sha
+		}
+	}
+	return objects, nil
+}
+
+// GetRemoteTagObjects maps every tag on the given remote to the object its
+// ref points to. Peeled entries (tag^{}) are ignored.
+func (repo *Repository) GetRemoteTagObjects(remote string) (map[string]string, error) {
+	output, err := repo.gitOutput("ls-remote", "--tags", remote)
+	if err != nil {
+		return nil, err
+	}
+
+	objects := make(map[string]string)
+	for _, line := range strings.Split(string(output), "\n") {
+		parts := strings.Fields(line)
+		if len(parts) < 2 || !strings.HasPrefix(parts[1], "refs/tags/") || strings.HasSuffix(parts[1], "^{}") {
+			continue
+		}
+		objects[strings.TrimPrefix(parts[1], "refs/tags/")] = parts[0]
+	}
+	return objects, nil
+}
+
+// GetDivergentTags returns the local tags that also exist on the remote but
+// point to a different object there.
+func (repo *Repository) GetDivergentTags(remote string) ([]DivergentTag, error) {
+	local, err := repo.GetLocalTagObjects()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get local tags: %w", err)
+	}
+	remoteObjects, err := repo.GetRemoteTagObjects(remote)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tags from %s: %w", remote, err)
+	}
+
+	var divergent []DivergentTag
+	for name, localSHA := range local {
+		remoteSHA, ok := remoteObjects[name]
+		if !ok || remoteSHA == localSHA {
+			continue
+		}
+		divergent = append(divergent, DivergentTag{
+			Name:      name,
+			Remote:    remote,
+			LocalSHA:  localSHA,
+			RemoteSHA: remoteSHA,
+		})
+	}
+	sort.Slice(divergent, func(i, j int) bool {
+		return divergent[i].Name < divergent[j].Name
+	})
+	return divergent, nil
+}
+
+// ResetTagToRemote moves a local tag to the object the remote tag points to.
+// The local tag is backed up in the trash namespace first, and left untouched
+// if it cannot be backed up.
+func (repo *Repository) ResetTagToRemote(name, remote string) error {
+	trashRef, err := repo.MoveToTrash("refs/tags/" + name)
+	if err != nil {
+		return err
+	}
+
+	refspec := fmt.Sprintf("+refs/tags/%s:refs/tags/%s", name, name)
+	output, err := repo.gitCombinedOutput("fetch", "--no-tags", remote, refspec)
+	if err != nil {
+		_ = repo.DropTrashRef(trashRef)
+		return fmt.Errorf("%s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// DeleteRemoteTags deletes tags from a remote with a single push and returns
+// the ones deleted. expected maps every tag to the object it must still point
+// to on the remote: each deletion is leased on it, so a tag that was moved on
+// the remote since it was reviewed is kept. Local tags are left untouched.
+func (repo *Repository) DeleteRemoteTags(remote string, expected map[string]string) ([]string, error) {
+	if len(expected) == 0 {
+		return nil, nil
+	}
+
+	names := make([]string, 0, len(expected))
+	for name := range expected {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	args := []string{"push", "--porcelain"}
+	for _, name := range names {
+		args = append(args, fmt.Sprintf("--force-with-lease=refs/tags/%s:%s", name, expected[name]))
+	}
+	args = append(args, remote, "--delete")
+	for _, name := range names {
+		args = append(args, "refs/tags/"+name)
+	}
+	result, err := repo.run(nil, args...)
+
+	var deleted, rejected []string
+	for _, line := range strings.Split(string(result.Stdout), "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 3 {
+			continue
+		}
+		// Deleted refs read ":refs/tags/<name>", rejected ones
+		// "(delete):refs/tags/<name>"
+		_, ref, _ := strings.Cut(fields[1], ":")
+		name := strings.TrimPrefix(ref, "refs/tags/")
+		switch fields[0] {
+		case "-":
+			deleted = append(deleted, name)
+		case "!":
+			rejected = append(rejected, fmt.Sprintf("%s (%s)", name, fields[2]))
+		}
+	}
+	if len(rejected) > 0 {
+		return deleted, fmt.Errorf("kept %s", strings.Join(rejected, ", "))
+	}
+	if err != nil {
+		return deleted, fmt.Errorf("%s", strings.TrimSpace(string(result.Stderr)))
+	}
+	return deleted, nil
+}
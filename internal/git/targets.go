@@ -0,0 +1,40 @@
+package git
+
+// ResolveTargets returns the integration targets for merge detection: the
+// default branch first, followed by every local branch matching one of the
+// patterns (exact names or globs such as release/*), without duplicates.
+func ResolveTargets(defaultBranch string, patterns []string, branches []string) []string {
+	targets := []string{defaultBranch}
+	seen := map[string]bool{defaultBranch: true}
+
+	for _, pattern := range patterns {
+		for _, branch := range branches {
+			if seen[branch] {
+				continue
+			}
+			if _, matched := MatchProtectedPattern(branch, []string{pattern}); matched {
+				targets = append(targets, branch)
+				seen[branch] = true
+			}
+		}
+	}
+	return targets
+}
+
+// GetMergedTargets maps every branch merged into at least one target to the
+// first target (in order) that contains it.
+func (repo *Repository) GetMergedTargets(targets []string) (map[string]string, error) {
+	mergedInto := make(map[string]string)
+	for _, target := range targets {
+		merged, err := repo.GetMergedBranches(target)
+		if err != nil {
+			return mergedInto, err
+		}
+		for _, branch := range merged {
+			if _, ok := mergedInto[branch]; !ok {
+				mergedInto[branch] = target
+			}
+		}
+	}
+	return mergedInto, nil
+}
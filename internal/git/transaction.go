@@ -0,0 +1,251 @@
+package git
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// BranchDeletion is a branch to delete with DeleteBranchesAtomic.
+type BranchDeletion struct {
+	Name string
+	// SHA is the commit the branch must still point to; empty accepts its
+	// current tip.
+	SHA string
+	// Force deletes the branch even if git branch -d would refuse it because
+	// it is not merged into its upstream, or into HEAD if it has none.
+	Force bool
+	// DeleteRemote also deletes the branch from the remote it tracks once the
+	// local deletion is committed, like DeleteBranchWithRemote.
+	DeleteRemote bool
+}
+
+// RefError explains why a branch made a deletion transaction fail.
+type RefError struct {
+	Branch string
+	Reason string
+}
+
+// TransactionError is returned by DeleteBranchesAtomic when the transaction is
+// rejected. No branch was deleted.
+type TransactionError struct {
+	Refs []RefError
+}
+
+func (e *TransactionError) Error() string {
+	var b strings.Builder
+	b.WriteString("transaction rejected, no branch was deleted")
+	for _, ref := range e.Refs {
+		if ref.Branch == "" {
+			fmt.Fprintf(&b, "\n  %s", ref.Reason)
+		} else {
+			fmt.Fprintf(&b, "\n  %s: %s", ref.Branch, ref.Reason)
+		}
+	}
+	return b.String()
+}
+
+// FullyMergedBranches returns the local branches a safe delete (git branch -d)
+// would accept, like IsFullyMerged, with two git commands for all branches.
+func (repo *Repository) FullyMergedBranches() (map[string]bool, error) {
+	refs, err := repo.listBranchRefs()
+	if err != nil {
+		return nil, err
+	}
+	return repo.fullyMerged(refs), nil
+}
+
+// fullyMerged applies the check of git branch -d to refs: a branch must be
+// merged into its upstream, or into HEAD if it has none.
+func (repo *Repository) fullyMerged(refs []BranchRef) map[string]bool {
+	mergedIntoHead := make(map[string]bool)
+	if output, err := repo.gitOutput("for-each-ref", "--merged", "HEAD", "--format=%(refname:lstrip=2)", "refs/heads/"); err == nil {
+		for _, name := range strings.Split(string(output), "\n") {
+			if name != "" {
+				mergedIntoHead[name] = true
+			}
+		}
+	}
+
+	merged := make(map[string]bool)
+	for _, ref := range refs {
+		if ref.HasUpstream() {
+			merged[ref.Name] = ref.UpstreamDivergence.Ahead == 0
+		} else {
+			merged[ref.Name] = mergedIntoHead[ref.Name]
+		}
+	}
+	return merged
+}
+
+// DeleteBranchesAtomic deletes every branch in a single "git update-ref
+// --stdin" transaction: either all of them are deleted or none is.
+//
+// Every branch is checked first (it must exist, still point to its expected
+// SHA and, unless forced, be fully merged), so a rejection lists every
+// offending branch. The transaction deletes each branch only if it still
+// points to the checked commit and backs it up in the trash namespace at the
+// same time. Once it is committed, the branch configuration is removed as
+// git branch -d would, branches with DeleteRemote are deleted from their
+// remote (or fallbackRemote if they track none) and every deletion is
+// recorded in the deletion journal. The journal records are returned; if one
+// of these steps fails, the error is an *IncompleteDeletionError.
+func (repo *Repository) DeleteBranchesAtomic(deletions []BranchDeletion, fallbackRemote string) ([]DeletionRecord, error) {
+	if len(deletions) == 0 {
+		return nil, nil
+	}
+
+	refs, err := repo.listBranchRefs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+	byName := make(map[string]BranchRef)
+	for _, ref := range refs {
+		byName[ref.Name] = ref
+	}
+	merged := repo.fullyMerged(refs)
+	config := repo.readBranchConfig()
+
+	var refErrs []RefError
+	for _, d := range deletions {
+		ref, ok := byName[d.Name]
+		switch {
+		case !ok:
+			refErrs = append(refErrs, RefError{Branch: d.Name, Reason: "branch does not exist"})
+		case d.SHA != "" && d.SHA != ref.SHA:
+			refErrs = append(refErrs, RefError{Branch: d.Name, Reason: fmt.Sprintf("branch moved from %s to %s", ShortSHA(d.SHA), ShortSHA(ref.SHA))})
+		case !d.Force && !merged[d.Name]:
+			base := "HEAD"
+			if ref.HasUpstream() {
+				base = ref.Upstream
+			}
+			refErrs = append(refErrs, RefError{Branch: d.Name, Reason: "not fully merged into " + base + ", requires force delete"})
+		}
+	}
+	if len(refErrs) > 0 {
+		return nil, &TransactionError{Refs: refErrs}
+	}
+
+	stamp := time.Now().Unix()
+	var stdin strings.Builder
+	records := make([]DeletionRecord, len(deletions))
+	for i, d := range deletions {
+		sha := byName[d.Name].SHA
+		trashRef := fmt.Sprintf("%s%d/heads/%s", TrashNamespace, stamp, d.Name)
+		fmt.Fprintf(&stdin, "update %s %s\n", trashRef, sha)
+		fmt.Fprintf(&stdin, "delete refs/heads/%s %s\n", d.Name, sha)
+		records[i] = DeletionRecord{
+			Branch:   d.Name,
+			Ref:      "refs/heads/" + d.Name,
+			SHA:      sha,
+			Remote:   config[d.Name]["remote"],
+			Merge:    config[d.Name]["merge"],
+			TrashRef: trashRef,
+		}
+	}
+	result, err := repo.runInput([]byte(stdin.String()), "update-ref", "-m", "git-gone: delete branches", "--stdin")
+	if err != nil {
+		return nil, rejectedTransaction(string(result.Stderr))
+	}
+
+	// git branch -d also drops the branch configuration
+	var failed []RefError
+	for _, d := range deletions {
+		if _, ok := config[d.Name]; !ok {
+			continue
+		}
+		if err := repo.removeBranchConfig(d.Name); err != nil {
+			failed = append(failed, RefError{Branch: d.Name, Reason: err.Error()})
+		}
+	}
+
+	byRemote := make(map[string][]string)
+	for _, d := range deletions {
+		if !d.DeleteRemote {
+			continue
+		}
+		remote := config[d.Name]["remote"]
+		if remote == "" || remote == "." {
+			remote = fallbackRemote
+		}
+		if remote != "" {
+			byRemote[remote] = append(byRemote[remote], d.Name)
+		}
+	}
+	remotes := make([]string, 0, len(byRemote))
+	for remote := range byRemote {
+		remotes = append(remotes, remote)
+	}
+	sort.Strings(remotes)
+	deletedFrom := make(map[string]string)
+	for _, remote := range remotes {
+		leases, _ := repo.trackedLeases(remote, byRemote[remote])
+		kept := repo.deleteFromRemote(remote, leases)
+		for name := range leases {
+			deletedFrom[name] = remote
+		}
+		for _, ref := range kept {
+			delete(deletedFrom, ref.Branch)
+		}
+		failed = append(failed, kept...)
+	}
+
+	for i := range records {
+		records[i].DeletedFromRemote = deletedFrom[records[i].Branch]
+		if err := repo.recordDeletion(records[i]); err != nil {
+			failed = append(failed, RefError{Branch: records[i].Branch, Reason: err.Error()})
+		}
+	}
+	return records, incompleteDeletion(failed)
+}
+
+// lockedRefPattern finds the branch named in an update-ref error such as
+// "fatal: cannot lock ref 'refs/heads/x': is at ... but expected ...".
+var lockedRefPattern = regexp.MustCompile(`'refs/heads/([^']+)': (.*)`)
+
+// rejectedTransaction turns the error output of a failed update-ref
+// transaction into a TransactionError.
+func rejectedTransaction(stderr string) error {
+	for _, line := range strings.Split(stderr, "\n") {
+		if match := lockedRefPattern.FindStringSubmatch(line); match != nil {
+			return &TransactionError{Refs: []RefError{{Branch: match[1], Reason: match[2]}}}
+		}
+	}
+	return &TransactionError{Refs: []RefError{{Reason: strings.TrimSpace(stderr)}}}
+}
+
+// readBranchConfig returns the branch.<name>.* settings of every branch,
+// keyed by branch name and variable.
+func (repo *Repository) readBranchConfig() map[string]map[string]string {
+	config := make(map[string]map[string]string)
+	output, err := repo.gitOutput("config", "--get-regexp", `^branch\.`)
+	if err != nil {
+		return config
+	}
+
+	for _, line := range strings.Split(string(output), "\n") {
+		key, value, _ := strings.Cut(line, " ")
+		rest := strings.TrimPrefix(key, "branch.")
+		dot := strings.LastIndex(rest, ".")
+		if dot <= 0 {
+			// Settings of all branches, such as branch.autoSetupMerge
+			continue
+		}
+		name, variable := rest[:dot], rest[dot+1:]
+		if config[name] == nil {
+			config[name] = make(map[string]string)
+		}
+		config[name][variable] = value
+	}
+	return config
+}
+
+// ShortSHA abbreviates a commit SHA for display.
+func ShortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
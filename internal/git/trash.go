@@ -0,0 +1,152 @@
+package git
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TrashNamespace is the hidden ref namespace where deleted refs are backed up.
+// Refs under it keep their objects reachable, so "git gc" does not collect them.
+const TrashNamespace = "refs/git-gone/trash/"
+
+// TrashEntry is a backup of a deleted branch or tag.
+type TrashEntry struct {
+	Ref       string // Backup ref, e.g. refs/git-gone/trash/<timestamp>/heads/<name>
+	Original  string // Original ref, e.g. refs/heads/<name>
+	SHA       string
+	TrashedAt time.Time
+}
+
+// Name returns the short name of the original branch or tag.
+func (e TrashEntry) Name() string {
+	name := strings.TrimPrefix(e.Original, "refs/heads/")
+	name = strings.TrimPrefix(name, "refs/remotes/")
+	return strings.TrimPrefix(name, "refs/tags/")
+}
+
+// IsTag returns true if the backup is of a tag.
+func (e TrashEntry) IsTag() bool {
+	return strings.HasPrefix(e.Original, "refs/tags/")
+}
+
+// IsRemoteBranch returns true if the backup is of a remote-tracking branch.
+func (e TrashEntry) IsRemoteBranch() bool {
+	return strings.HasPrefix(e.Original, "refs/remotes/")
+}
+
+// MoveToTrash copies ref into the trash namespace and returns the backup ref.
+// The original ref is left untouched; callers delete it afterwards.
+func (repo *Repository) MoveToTrash(ref string) (string, error) {
+	if !strings.HasPrefix(ref, "refs/") {
+		return "", fmt.Errorf("invalid ref %s", ref)
+	}
+
+	output, err := repo.gitOutput("rev-parse", "--verify", "--quiet", ref)
+	if err != nil {
+		return "", fmt.Errorf("ref %s does not exist", ref)
+	}
+	sha := strings.TrimSpace(string(output))
+
+	trashRef := fmt.Sprintf("%s%d/%s", TrashNamespace, time.Now().Unix(), strings.TrimPrefix(ref, "refs/"))
+	output, err = repo.gitCombinedOutput("update-ref", "-m", "git-gone: backup of "+ref, trashRef, sha)
+	if err != nil {
+		return "", fmt.Errorf("failed to back up %s: %s", ref, string(output))
+	}
+	return trashRef, nil
+}
+
+// DropTrashRef removes a single backup ref from the trash.
+func (repo *Repository) DropTrashRef(trashRef string) error {
+	if trashRef == "" {
+		return nil
+	}
+	if !strings.HasPrefix(trashRef, TrashNamespace) {
+		return fmt.Errorf("%s is not a trash ref", trashRef)
+	}
+	output, err := repo.gitCombinedOutput("update-ref", "-d", trashRef)
+	if err != nil {
+		return fmt.Errorf("%s", string(output))
+	}
+	return nil
+}
+
+// ListTrash returns all backups in the trash, most recent first.
+func (repo *Repository) ListTrash() ([]TrashEntry, error) {
+	output, err := repo.gitOutput("for-each-ref", "--format=%(refname)%09%(objectname)", TrashNamespace)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []TrashEntry{}
+	for _, line := range strings.Split(string(output), "\n") {
+		parts := strings.SplitN(strings.TrimSpace(line), "\t", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		entry, ok := parseTrashRef(parts[0])
+		if !ok {
+			continue
+		}
+		entry.SHA = parts[1]
+		entries = append(entries, entry)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].TrashedAt.After(entries[j].TrashedAt)
+	})
+	return entries, nil
+}
+
+// parseTrashRef splits refs/git-gone/trash/<timestamp>/<kind>/<name> into its parts.
+func parseTrashRef(ref string) (TrashEntry, bool) {
+	rest := strings.TrimPrefix(ref, TrashNamespace)
+	parts := strings.SplitN(rest, "/", 2)
+	if len(parts) != 2 {
+		return TrashEntry{}, false
+	}
+	timestamp, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return TrashEntry{}, false
+	}
+	return TrashEntry{
+		Ref:       ref,
+		Original:  "refs/" + parts[1],
+		TrashedAt: time.Unix(timestamp, 0),
+	}, true
+}
+
+// RestoreFromTrash recreates the original ref from a backup and removes the
+// backup. It fails if the original ref already exists.
+func (repo *Repository) RestoreFromTrash(entry TrashEntry) error {
+	// An empty old value makes update-ref refuse to overwrite an existing ref
+	output, err := repo.gitCombinedOutput("update-ref", "-m", "git-gone: restore from trash", entry.Original, entry.SHA, "")
+	if err != nil {
+		return fmt.Errorf("%s", string(output))
+	}
+	return repo.DropTrashRef(entry.Ref)
+}
+
+// EmptyTrash removes backups trashed more than olderThan ago and returns the
+// removed entries. A zero duration empties the whole trash.
+func (repo *Repository) EmptyTrash(olderThan time.Duration) ([]TrashEntry, error) {
+	entries, err := repo.ListTrash()
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := time.Now().Add(-olderThan)
+	var removed []TrashEntry
+	for _, entry := range entries {
+		if entry.TrashedAt.After(cutoff) {
+			continue
+		}
+		if err := repo.DropTrashRef(entry.Ref); err != nil {
+			return removed, fmt.Errorf("failed to remove %s: %w", entry.Ref, err)
+		}
+		removed = append(removed, entry)
+	}
+	return removed, nil
+}
@@ -0,0 +1,107 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Worktree is a working tree attached to the repository.
+type Worktree struct {
+	Path     string
+	HEAD     string
+	Branch   string // Short branch name, empty if detached or bare
+	IsMain   bool   // The main worktree, which cannot be removed
+	Bare     bool
+	Detached bool
+	Locked   bool
+	// Missing is true if the worktree directory no longer exists.
+	Missing bool
+}
+
+// ListWorktrees returns all worktrees of the repository, main worktree first.
+func (repo *Repository) ListWorktrees() ([]Worktree, error) {
+	output, err := repo.gitOutput("worktree", "list", "--porcelain")
+	if err != nil {
+		return nil, err
+	}
+
+	var worktrees []Worktree
+	for i, block := range strings.Split(strings.TrimSpace(string(output)), "\n\n") {
+		var wt Worktree
+		for _, line := range strings.Split(block, "\n") {
+			key, value, _ := strings.Cut(strings.TrimSpace(line), " ")
+			switch key {
+			case "worktree":
+				wt.Path = value
+			case "HEAD":
+				wt.HEAD = value
+			case "branch":
+				wt.Branch = strings.TrimPrefix(value, "refs/heads/")
+			case "bare":
+				wt.Bare = true
+			case "detached":
+				wt.Detached = true
+			case "locked":
+				wt.Locked = true
+			case "prunable":
+				wt.Missing = true
+			}
+		}
+		if wt.Path == "" {
+			continue
+		}
+		wt.IsMain = i == 0
+		if _, err := os.Stat(wt.Path); os.IsNotExist(err) {
+			wt.Missing = true
+		}
+		worktrees = append(worktrees, wt)
+	}
+	return worktrees, nil
+}
+
+// GetWorktreeBranches maps every branch checked out in a worktree other than
+// the current one to the path of that worktree.
+func (repo *Repository) GetWorktreeBranches() map[string]string {
+	branches := make(map[string]string)
+
+	worktrees, err := repo.ListWorktrees()
+	if err != nil {
+		return branches
+	}
+	current, _ := repo.GetRepositoryRoot()
+	for _, wt := range worktrees {
+		if wt.Branch == "" || samePath(wt.Path, current) {
+			continue
+		}
+		branches[wt.Branch] = wt.Path
+	}
+	return branches
+}
+
+// samePath reports whether two paths refer to the same directory.
+func samePath(a, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+	if resolved, err := filepath.EvalSymlinks(a); err == nil {
+		a = resolved
+	}
+	if resolved, err := filepath.EvalSymlinks(b); err == nil {
+		b = resolved
+	}
+	return filepath.Clean(a) == filepath.Clean(b)
+}
+
+// RemoveWorktree removes a worktree. For a worktree whose directory is
+// missing this prunes its administrative files, like "git worktree prune"
+// does for every missing worktree. Worktrees with uncommitted changes are
+// refused by git.
+func (repo *Repository) RemoveWorktree(path string) error {
+	output, err := repo.gitCombinedOutput("worktree", "remove", path)
+	if err != nil {
+		return fmt.Errorf("%s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
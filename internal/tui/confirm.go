@@ -34,3 +34,22 @@ func ConfirmDangerousOperation(items []string, itemType string) bool {
 	prompt := fmt.Sprintf("\n%s  This action cannot be undone! Type 'DELETE' to confirm: ", EmojiWarning)
 	return TypedConfirmation(prompt, "DELETE")
 }
+
+// PromptChoice asks the user to pick one of the choices by typing it or its
+// first letter. It returns the chosen option, or "" for any other answer.
+func PromptChoice(message string, choices ...string) string {
+	hints := make([]string, len(choices))
+	for i, choice := range choices {
+		hints[i] = "[" + choice[:1] + "]" + choice[1:]
+	}
+	fmt.Printf("\n%s (%s): ", message, strings.Join(hints, "/"))
+	reader := bufio.NewReader(os.Stdin)
+	response, _ := reader.ReadString('\n')
+	response = strings.TrimSpace(strings.ToLower(response))
+	for _, choice := range choices {
+		if response != "" && (response == choice || response == choice[:1]) {
+			return choice
+		}
+	}
+	return ""
+}
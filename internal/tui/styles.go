@@ -17,6 +17,8 @@ const (
 	EmojiCelebrate = "🎉"
 	EmojiDanger    = "🚨"
 	EmojiTag       = "🏷️"
+	EmojiDryRun    = "🧪"
+	EmojiRepo      = "📁"
 )
 
 // Colors for TUI elements.
@@ -1,15 +1,21 @@
 package tests
 
 import (
+	"errors"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"git-gone/internal/git"
 )
 
+// repo runs git in the current directory: every test changes to its own
+// repository first.
+var repo = git.OpenRepository("", nil)
+
 // TestHelper provides utilities for git tests.
 type TestHelper struct {
 	t       *testing.T
@@ -78,12 +84,29 @@ func (h *TestHelper) MergeBranch(name string) {
 	runGitCmd(h.t, "merge", name, "--no-ff", "-m", "Merge "+name)
 }
 
+// SquashMergeBranch squash-merges a branch into main as a single new commit.
+func (h *TestHelper) SquashMergeBranch(name string) {
+	h.t.Helper()
+	runGitCmd(h.t, "checkout", "main")
+	runGitCmd(h.t, "merge", "--squash", name)
+	runGitCmd(h.t, "commit", "-m", "Squash "+name)
+}
+
 // CheckoutMain switches to main branch.
 func (h *TestHelper) CheckoutMain() {
 	h.t.Helper()
 	runGitCmd(h.t, "checkout", "main")
 }
 
+// AddBareRemote creates an empty bare repository and adds it as a remote.
+func (h *TestHelper) AddBareRemote(name string) string {
+	h.t.Helper()
+	dir := filepath.Join(h.t.TempDir(), name+".git")
+	runGitCmd(h.t, "init", "--bare", dir)
+	runGitCmd(h.t, "remote", "add", name, dir)
+	return dir
+}
+
 func runGitCmd(t *testing.T, args ...string) string {
 	t.Helper()
 	cmd := exec.Command("git", args...)
@@ -118,7 +141,7 @@ func TestGetMergedBranches_ReturnsMergedBranches(t *testing.T) {
 	h.MergeBranch("feature-merged")
 
 	// Get merged branches
-	merged, err := git.GetMergedBranches("main")
+	merged, err := repo.GetMergedBranches("main")
 	if err != nil {
 		t.Fatalf("GetMergedBranches failed: %v", err)
 	}
@@ -146,7 +169,7 @@ func TestGetMergedBranches_ExcludesUnmergedBranches(t *testing.T) {
 	h.CheckoutMain()
 
 	// Get merged branches
-	merged, err := git.GetMergedBranches("main")
+	merged, err := repo.GetMergedBranches("main")
 	if err != nil {
 		t.Fatalf("GetMergedBranches failed: %v", err)
 	}
@@ -209,7 +232,7 @@ func TestGetAllLocalBranches_ReturnsAllBranches(t *testing.T) {
 	h.CreateBranch("branch-b")
 	h.CheckoutMain()
 
-	branches, err := git.GetAllLocalBranches()
+	branches, err := repo.GetAllLocalBranches()
 	if err != nil {
 		t.Fatalf("GetAllLocalBranches failed: %v", err)
 	}
@@ -229,3 +252,384 @@ func TestGetAllLocalBranches_ReturnsAllBranches(t *testing.T) {
 		}
 	}
 }
+
+func TestGetSquashMergedBranches_DetectsSquashMerge(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	h.CreateBranch("feature-squashed")
+	h.SquashMergeBranch("feature-squashed")
+
+	// A squash merge is invisible to git branch --merged
+	merged, err := repo.GetMergedBranches("main")
+	if err != nil {
+		t.Fatalf("GetMergedBranches failed: %v", err)
+	}
+	for _, branch := range merged {
+		if branch == "feature-squashed" {
+			t.Fatalf("Squash-merged branch should not be reported by GetMergedBranches")
+		}
+	}
+
+	squashed, err := repo.GetSquashMergedBranches("main", []string{"feature-squashed"})
+	if err != nil {
+		t.Fatalf("GetSquashMergedBranches failed: %v", err)
+	}
+	if len(squashed) != 1 || squashed[0] != "feature-squashed" {
+		t.Errorf("Expected [feature-squashed], got: %v", squashed)
+	}
+}
+
+func TestGetSquashMergedBranches_ExcludesUnmergedBranches(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	h.CreateBranch("feature-unmerged")
+	h.CheckoutMain()
+
+	squashed, err := repo.GetSquashMergedBranches("main", []string{"feature-unmerged"})
+	if err != nil {
+		t.Fatalf("GetSquashMergedBranches failed: %v", err)
+	}
+	if len(squashed) != 0 {
+		t.Errorf("Expected no squash-merged branches, got: %v", squashed)
+	}
+}
+
+func TestSquashDetection_WritesNothingAndReportsErrors(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	h.CreateBranch("feature-squashed")
+	h.SquashMergeBranch("feature-squashed")
+	objects := runGitCmd(t, "count-objects")
+
+	if squashed, err := repo.IsSquashMerged("feature-squashed", "main"); err != nil || !squashed {
+		t.Errorf("Expected IsSquashMerged to detect the squash merge, got: %v, %v", squashed, err)
+	}
+	squashed, err := repo.GetSquashMergedBranches("main", []string{"feature-squashed", "missing"})
+	if err == nil || !strings.Contains(err.Error(), "missing") {
+		t.Errorf("Expected an error for the missing branch, got: %v", err)
+	}
+	if len(squashed) != 1 || squashed[0] != "feature-squashed" {
+		t.Errorf("Expected [feature-squashed] despite the error, got: %v", squashed)
+	}
+
+	if after := runGitCmd(t, "count-objects"); after != objects {
+		t.Errorf("Expected squash detection to write no objects, before: %q, after: %q", objects, after)
+	}
+}
+
+func TestGetRebaseMergedBranches_DetectsRebasedCommits(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	h.CreateBranch("feature-rebased")
+	h.CheckoutMain()
+
+	// Diverge main so the rebased commit gets a different SHA
+	createFile(t, "main.txt", "main change")
+	runGitCmd(t, "add", ".")
+	runGitCmd(t, "commit", "-m", "Main change")
+	runGitCmd(t, "cherry-pick", "feature-rebased")
+
+	rebased, err := repo.GetRebaseMergedBranches("main", []string{"feature-rebased"})
+	if err != nil {
+		t.Fatalf("GetRebaseMergedBranches failed: %v", err)
+	}
+	if len(rebased) != 1 || rebased[0] != "feature-rebased" {
+		t.Errorf("Expected [feature-rebased], got: %v", rebased)
+	}
+
+	rebased, err = repo.GetRebaseMergedBranches("main", []string{"feature-rebased", "missing"})
+	if err == nil || !strings.Contains(err.Error(), "missing") {
+		t.Errorf("Expected an error for the missing branch, got: %v", err)
+	}
+	if len(rebased) != 1 || rebased[0] != "feature-rebased" {
+		t.Errorf("Expected [feature-rebased] despite the error, got: %v", rebased)
+	}
+}
+
+func TestGetCherryStatus_ReportsPartiallyAbsorbedBranch(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	h.CreateBranch("feature-partial")
+	createFile(t, "second.txt", "second commit")
+	runGitCmd(t, "add", ".")
+	runGitCmd(t, "commit", "-m", "Second commit")
+	h.CheckoutMain()
+
+	// Diverge main, then land only the first commit
+	createFile(t, "main.txt", "main change")
+	runGitCmd(t, "add", ".")
+	runGitCmd(t, "commit", "-m", "Main change")
+	runGitCmd(t, "cherry-pick", "feature-partial~1")
+
+	result, err := repo.GetCherryStatus("feature-partial", "main")
+	if err != nil {
+		t.Fatalf("GetCherryStatus failed: %v", err)
+	}
+	if result.Unique != 1 || result.Equivalent != 1 {
+		t.Errorf("Expected 1 unique and 1 equivalent commit, got: %+v", result)
+	}
+	if result.FullyAbsorbed() {
+		t.Error("Partially absorbed branch should not be reported as fully absorbed")
+	}
+	if !result.PartiallyAbsorbed() {
+		t.Error("Expected branch to be partially absorbed")
+	}
+}
+
+func TestResolveTargets_ExpandsPatternsAfterDefault(t *testing.T) {
+	branches := []string{"main", "develop", "release/1.0", "release/2.0", "feature-x"}
+
+	targets := git.ResolveTargets("main", []string{"develop", "release/*", "main"}, branches)
+
+	expected := []string{"main", "develop", "release/1.0", "release/2.0"}
+	if strings.Join(targets, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected %v, got: %v", expected, targets)
+	}
+}
+
+func TestGetMergedTargets_RecordsAbsorbingTarget(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	runGitCmd(t, "branch", "develop")
+	h.CreateBranch("feature-dev")
+	runGitCmd(t, "checkout", "develop")
+	runGitCmd(t, "merge", "feature-dev", "--no-ff", "-m", "Merge feature-dev")
+	h.CheckoutMain()
+
+	mergedInto, err := repo.GetMergedTargets([]string{"main", "develop"})
+	if err != nil {
+		t.Fatalf("GetMergedTargets failed: %v", err)
+	}
+	if mergedInto["feature-dev"] != "develop" {
+		t.Errorf("Expected feature-dev merged into develop, got: %q", mergedInto["feature-dev"])
+	}
+}
+
+func TestGetBranchCommitDates_ParsesCommitterDates(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	t.Setenv("GIT_COMMITTER_DATE", "2020-01-15T12:00:00Z")
+	h.CreateBranch("feature-old")
+	t.Setenv("GIT_COMMITTER_DATE", "")
+	h.CheckoutMain()
+	h.CreateBranch("feature-new")
+	h.CheckoutMain()
+
+	dates, err := repo.GetBranchCommitDates()
+	if err != nil {
+		t.Fatalf("GetBranchCommitDates failed: %v", err)
+	}
+
+	expected := time.Date(2020, 1, 15, 12, 0, 0, 0, time.UTC)
+	if !dates["feature-old"].Equal(expected) {
+		t.Errorf("Expected feature-old dated %v, got %v", expected, dates["feature-old"])
+	}
+
+	now := time.Now()
+	if !git.IsOlderThan(dates["feature-old"], 90*24*time.Hour, now) {
+		t.Error("Expected feature-old to be older than 90 days")
+	}
+	if git.IsOlderThan(dates["feature-new"], 90*24*time.Hour, now) {
+		t.Error("Expected feature-new not to be older than 90 days")
+	}
+	if git.IsOlderThan(dates["feature-old"], 0, now) {
+		t.Error("Expected a zero age to disable the filter")
+	}
+
+	branches := []string{"feature-new", "main", "feature-old"}
+	git.SortByCommitDate(branches, dates)
+	if branches[0] != "feature-old" {
+		t.Errorf("Expected feature-old first when sorting by age, got: %v", branches)
+	}
+}
+
+func TestClassifyBranches_UsesCandidateModel(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	h.CreateBranch("feature-merged")
+	h.MergeBranch("feature-merged")
+	// Two commits squashed into one are not patch-equivalent to either
+	h.CreateBranch("feature-squashed")
+	createFile(t, "second.txt", "Second commit")
+	runGitCmd(t, "add", ".")
+	runGitCmd(t, "commit", "-m", "Second commit on feature-squashed")
+	h.SquashMergeBranch("feature-squashed")
+	h.CreateBranch("feature-unmerged")
+	h.CheckoutMain()
+	h.CreateBranch("release/1.0")
+	h.CheckoutMain()
+
+	branches, err := repo.GetAllLocalBranches()
+	if err != nil {
+		t.Fatalf("GetAllLocalBranches failed: %v", err)
+	}
+	classifications, err := repo.ClassifyBranches(branches, git.BranchContext{
+		DefaultBranch: "main",
+		CurrentBranch: "main",
+		Targets:       []string{"main"},
+		Protected:     []string{"release/*"},
+	})
+	if err != nil {
+		t.Fatalf("ClassifyBranches failed: %v", err)
+	}
+
+	byName := make(map[string]git.BranchClassification)
+	for _, c := range classifications {
+		byName[c.Name] = c
+	}
+	if byName["main"].ProtectedBy != "default_branch" {
+		t.Errorf("Expected main to be protected as default branch, got: %+v", byName["main"])
+	}
+	if byName["release/1.0"].ProtectedBy != "pattern:release/*" {
+		t.Errorf("Expected release/1.0 to be protected by pattern, got: %+v", byName["release/1.0"])
+	}
+
+	expected := map[string]struct {
+		reason git.DeletionReason
+		risk   git.RiskLevel
+	}{
+		"feature-merged":   {git.ReasonMerged, git.RiskSafe},
+		"feature-squashed": {git.ReasonSquashMerged, git.RiskSafe},
+		"feature-unmerged": {git.ReasonUnmerged, git.RiskDangerous},
+	}
+	for name, want := range expected {
+		c := byName[name]
+		if c.IsProtected() || c.Candidate.Reason != want.reason || c.Candidate.RiskLevel != want.risk {
+			t.Errorf("Unexpected classification for %s: %+v", name, c)
+		}
+	}
+	if byName["feature-squashed"].MergedInto != "main" {
+		t.Errorf("Expected feature-squashed to be merged into main, got: %+v", byName["feature-squashed"])
+	}
+}
+
+func TestRequiresForceDelete_SquashMergedBranch(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	h.CreateBranch("feature-merged")
+	h.MergeBranch("feature-merged")
+	h.CreateBranch("feature-squashed")
+	h.SquashMergeBranch("feature-squashed")
+
+	if git.NewBranchCandidate("feature-merged", git.ReasonMerged).RequiresForceDelete(repo) {
+		t.Error("Branch merged into HEAD should be deleted with -d")
+	}
+	squashed := git.NewBranchCandidate("feature-squashed", git.ReasonSquashMerged)
+	if !squashed.RequiresForceDelete(repo) {
+		t.Error("Squash-merged branch should require -D")
+	}
+	if err := repo.DeleteBranch(squashed.Name, squashed.RequiresForceDelete(repo)); err != nil {
+		t.Errorf("Deleting squash-merged branch failed: %v", err)
+	}
+}
+
+func TestDeleteCandidate_ForcesGoneBranchOnlyIfMerged(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	h.AddBareRemote("origin")
+	h.CreateBranch("feature-gone-squashed")
+	h.CheckoutMain()
+	h.CreateBranch("feature-gone-unmerged")
+	h.SquashMergeBranch("feature-gone-squashed")
+	for _, branch := range []string{"feature-gone-squashed", "feature-gone-unmerged"} {
+		runGitCmd(t, "push", "-u", "origin", branch)
+		runGitCmd(t, "push", "origin", "--delete", branch)
+	}
+
+	classifications, err := repo.ClassifyBranches([]string{"feature-gone-squashed", "feature-gone-unmerged"},
+		git.BranchContext{DefaultBranch: "main", CurrentBranch: "main", Targets: []string{"main"}})
+	if err != nil {
+		t.Fatalf("ClassifyBranches failed: %v", err)
+	}
+	squashed, unmerged := classifications[0].Candidate, classifications[1].Candidate
+	if squashed.Reason != git.ReasonGoneRemote || unmerged.Reason != git.ReasonGoneRemote {
+		t.Fatalf("Expected both branches to be gone, got: %+v", classifications)
+	}
+
+	// The snapshot finds the same proof
+	snapshot, err := repo.LoadBranchSnapshot(git.BranchContext{DefaultBranch: "main", CurrentBranch: "main", Targets: []string{"main"}})
+	if err != nil {
+		t.Fatalf("LoadBranchSnapshot failed: %v", err)
+	}
+	for _, c := range snapshot.Classify() {
+		if c.Name == squashed.Name && c.Candidate.MergedInto != "main" {
+			t.Errorf("Expected the snapshot to find %s squash-merged, got: %+v", c.Name, c)
+		}
+	}
+
+	// The squash merge proves the first branch merged, so it is force-deleted
+	if squashed.MergedInto != "main" || !squashed.RequiresForceDelete(repo) {
+		t.Errorf("Expected the squash-merged gone branch to require -D, got: %+v", squashed)
+	}
+	if _, err := repo.DeleteCandidate(squashed, "origin"); err != nil {
+		t.Errorf("Deleting the squash-merged gone branch failed: %v", err)
+	}
+
+	// Nothing proves the second one merged: git's safe delete refuses it
+	if unmerged.RequiresForceDelete(repo) {
+		t.Errorf("Expected the unmerged gone branch to keep the safe delete, got: %+v", unmerged)
+	}
+	if _, err := repo.DeleteCandidate(unmerged, "origin"); err == nil {
+		t.Error("Expected deleting the unmerged gone branch to fail")
+	}
+	if branches := runGitCmd(t, "branch", "--list", "feature-gone-unmerged"); branches == "" {
+		t.Error("Expected the unmerged gone branch to be kept")
+	}
+}
+
+func TestDeleteCandidate_RefusesMovedBranch(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	h.CreateBranch("feature-moved")
+	h.CreateBranch("feature-unmerged")
+	h.CheckoutMain()
+
+	classifications, err := repo.ClassifyBranches([]string{"feature-moved", "feature-unmerged"},
+		git.BranchContext{DefaultBranch: "main", CurrentBranch: "main", Targets: []string{"main"}})
+	if err != nil {
+		t.Fatalf("ClassifyBranches failed: %v", err)
+	}
+	candidates := make(map[string]git.DeletionCandidate)
+	for _, c := range classifications {
+		if c.Candidate.SHA == "" {
+			t.Fatalf("Expected %s to capture its SHA", c.Name)
+		}
+		candidates[c.Name] = c.Candidate
+	}
+
+	// Someone commits to the branch while the selector is open
+	runGitCmd(t, "checkout", "feature-moved")
+	createFile(t, "late.txt", "late commit")
+	runGitCmd(t, "add", ".")
+	runGitCmd(t, "commit", "-m", "Late commit")
+	h.CheckoutMain()
+
+	var moved *git.BranchMovedError
+	if _, err := repo.DeleteCandidate(candidates["feature-moved"], ""); !errors.As(err, &moved) {
+		t.Fatalf("Expected a BranchMovedError, got: %v", err)
+	}
+	if moved.Expected != candidates["feature-moved"].SHA {
+		t.Errorf("Expected the captured SHA in the error, got: %+v", moved)
+	}
+	if branches := runGitCmd(t, "branch", "--list", "feature-moved"); !strings.Contains(branches, "feature-moved") {
+		t.Error("Moved branch should have been kept")
+	}
+	if trash, _ := repo.ListTrash(); len(trash) != 0 {
+		t.Errorf("Expected no backup for a refused deletion, got: %v", trash)
+	}
+
+	if _, err := repo.DeleteCandidate(candidates["feature-unmerged"], ""); err != nil {
+		t.Errorf("Deleting an unmoved branch failed: %v", err)
+	}
+}
@@ -6,8 +6,6 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
-
-	"git-gone/internal/git"
 )
 
 // getProjectRoot returns the project root directory.
@@ -35,7 +33,7 @@ func TestBranchesCommand_InNonGitRepo(t *testing.T) {
 	_ = os.Chdir(tempDir)
 
 	// Use the git package directly
-	err = git.CheckGitRepository()
+	err = repo.CheckGitRepository()
 	if err == nil {
 		t.Error("Expected error when not in git repository")
 	}
@@ -54,7 +52,7 @@ func TestTagsListCommand_InNonGitRepo(t *testing.T) {
 	_ = os.Chdir(tempDir)
 
 	// Use the git package directly
-	err = git.CheckGitRepository()
+	err = repo.CheckGitRepository()
 	if err == nil {
 		t.Error("Expected error when not in git repository")
 	}
@@ -150,3 +148,35 @@ func TestBranchesCommand_WithIncompatibleFlags(t *testing.T) {
 		t.Errorf("Expected incompatibility error, got: %s", string(output))
 	}
 }
+
+// TestBranchesCommand_DryRunKeepsRemoteTrackingRefs verifies that a dry run
+// does not fetch and prune remote-tracking refs.
+func TestBranchesCommand_DryRunKeepsRemoteTrackingRefs(t *testing.T) {
+	binaryPath := filepath.Join(t.TempDir(), "git-gone-test")
+	buildCmd := exec.Command("go", "build", "-o", binaryPath, ".")
+	buildCmd.Dir = getProjectRoot(t)
+	if output, err := buildCmd.CombinedOutput(); err != nil {
+		t.Fatalf("Failed to build: %v\nOutput: %s", err, string(output))
+	}
+
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	remoteDir := h.AddBareRemote("origin")
+	h.CreateBranch("feature-pushed")
+	runGitCmd(t, "push", "-u", "origin", "feature-pushed")
+	h.CheckoutMain()
+	runGitCmd(t, "--git-dir", remoteDir, "branch", "-D", "feature-pushed")
+
+	cmd := exec.Command(binaryPath, "branches", "--all", "--dry-run")
+	cmd.Env = append(os.Environ(), "LC_ALL=C")
+	cmd.Stdin = strings.NewReader("y\n")
+	output, _ := cmd.CombinedOutput()
+
+	if !strings.Contains(string(output), "not updating remote references") {
+		t.Errorf("Expected the dry run to skip the fetch, got: %s", output)
+	}
+	if refs := runGitCmd(t, "for-each-ref", "refs/remotes/origin/feature-pushed"); refs == "" {
+		t.Error("Expected the dry run to keep refs/remotes/origin/feature-pushed")
+	}
+}
@@ -0,0 +1,161 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"git-gone/internal/config"
+	"git-gone/internal/git"
+)
+
+func TestMatchProtectedPattern_MatchesGlobs(t *testing.T) {
+	patterns := []string{"release/*", "hotfix/*", "env/*"}
+
+	tests := []struct {
+		branch  string
+		pattern string
+		matched bool
+	}{
+		{"release/1.0", "release/*", true},
+		{"hotfix/login", "hotfix/*", true},
+		{"env/staging", "env/*", true},
+		{"feature/release", "", false},
+		{"release/1.0/fix", "", false},
+	}
+
+	for _, tt := range tests {
+		pattern, matched := git.MatchProtectedPattern(tt.branch, patterns)
+		if matched != tt.matched || pattern != tt.pattern {
+			t.Errorf("MatchProtectedPattern(%q) = (%q, %v), want (%q, %v)",
+				tt.branch, pattern, matched, tt.pattern, tt.matched)
+		}
+	}
+}
+
+func TestFilterProtectedBranches_ExcludesPatterns(t *testing.T) {
+	branches := []string{"main", "release/1.0", "feature-1", "hotfix/x"}
+
+	filtered := git.FilterProtectedBranches(branches, "main", "main", "release/*", "hotfix/*")
+
+	if len(filtered) != 1 || filtered[0] != "feature-1" {
+		t.Errorf("Expected [feature-1], got: %v", filtered)
+	}
+}
+
+func TestConfigLoad_MergesFilesAndGitConfig(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	repoRoot := strings.TrimSpace(runGitCmd(t, "rev-parse", "--show-toplevel"))
+
+	// Global config with a per-repo override
+	xdg := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+	globalDir := filepath.Join(xdg, "git-gone")
+	if err := os.MkdirAll(globalDir, 0755); err != nil {
+		t.Fatalf("Failed to create global config dir: %v", err)
+	}
+	global := "protected:\n  - env/*\ndefaults:\n  unmerged: true\nrepos:\n  " + repoRoot + ":\n    protected:\n      - hotfix/*\n"
+	if err := os.WriteFile(filepath.Join(globalDir, "config.yaml"), []byte(global), 0644); err != nil {
+		t.Fatalf("Failed to write global config: %v", err)
+	}
+
+	// Repository config file and git config keys
+	createFile(t, config.FileName, "protected:\n  - release/*\ndefaults:\n  output: json\n")
+	runGitCmd(t, "config", "--add", "gitgone.protected", "keep/*")
+	runGitCmd(t, "config", "gitgone.unmerged", "false")
+
+	cfg, err := config.Load(repo)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	for _, pattern := range []string{"env/*", "hotfix/*", "release/*", "keep/*"} {
+		if _, ok := git.MatchProtectedPattern(strings.TrimSuffix(pattern, "*")+"x", cfg.Protected); !ok {
+			t.Errorf("Expected pattern %s to be loaded, got: %v", pattern, cfg.Protected)
+		}
+	}
+	if cfg.Defaults.Output == nil || *cfg.Defaults.Output != "json" {
+		t.Errorf("Expected output default 'json' from %s", config.FileName)
+	}
+	// git config has the highest precedence
+	if cfg.Defaults.Unmerged == nil || *cfg.Defaults.Unmerged {
+		t.Errorf("Expected unmerged default overridden to false by git config")
+	}
+}
+
+func TestConfigLoad_InvalidFileReturnsError(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	createFile(t, config.FileName, "protected: [unterminated\n")
+
+	if _, err := config.Load(repo); err == nil {
+		t.Error("Expected an error for an invalid config file")
+	}
+}
+
+func TestConfigLoad_MostSpecificRepoEntryWins(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	repoRoot := strings.TrimSpace(runGitCmd(t, "rev-parse", "--show-toplevel"))
+	xdg := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+	globalDir := filepath.Join(xdg, "git-gone")
+	if err := os.MkdirAll(globalDir, 0755); err != nil {
+		t.Fatalf("Failed to create global config dir: %v", err)
+	}
+	global := "repos:\n" +
+		"  " + repoRoot + ":\n    remote: exact\n" +
+		"  " + filepath.Join(filepath.Dir(repoRoot), "*") + ":\n    remote: glob\n"
+	if err := os.WriteFile(filepath.Join(globalDir, "config.yaml"), []byte(global), 0644); err != nil {
+		t.Fatalf("Failed to write global config: %v", err)
+	}
+
+	// Map order must not decide: load several times
+	for range 10 {
+		cfg, err := config.Load(repo)
+		if err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+		if cfg.Remote != "exact" {
+			t.Fatalf("Expected the exact path entry to win, got remote %q", cfg.Remote)
+		}
+	}
+}
+
+func TestConfigLoad_IgnoresDestructiveDefaultsInRepositoryFile(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	createFile(t, config.FileName, "defaults:\n  force: true\n  all: true\n  unmerged: true\n  dry_run: true\n")
+
+	cfg, err := config.Load(repo)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Defaults.Force != nil || cfg.Defaults.All != nil || cfg.Defaults.Unmerged != nil {
+		t.Errorf("Expected force, all and unmerged to be ignored in %s, got: %+v", config.FileName, cfg.Defaults)
+	}
+	if cfg.Defaults.DryRun == nil || !*cfg.Defaults.DryRun {
+		t.Errorf("Expected dry_run to be read from %s", config.FileName)
+	}
+	if len(cfg.Warnings) != 3 {
+		t.Errorf("Expected a warning per ignored default, got: %v", cfg.Warnings)
+	}
+
+	// git config may still set them
+	runGitCmd(t, "config", "gitgone.force", "true")
+	cfg, err = config.Load(repo)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Defaults.Force == nil || !*cfg.Defaults.Force {
+		t.Error("Expected force default from git config")
+	}
+}
@@ -0,0 +1,134 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"git-gone/internal/events"
+)
+
+// decodeEvents parses an ndjson stream, failing on any line that is not a
+// JSON object with a type
+func decodeEvents(t *testing.T, output []byte) []map[string]any {
+	t.Helper()
+	var decoded []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		var event map[string]any
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("Expected only JSON lines, got %q in:\n%s", line, output)
+		}
+		if event["type"] == nil || event["time"] == nil {
+			t.Errorf("Expected type and time in %q", line)
+		}
+		decoded = append(decoded, event)
+	}
+	return decoded
+}
+
+// eventTypes returns the type of every event
+func eventTypes(decoded []map[string]any) string {
+	var types []string
+	for _, event := range decoded {
+		types = append(types, event["type"].(string))
+	}
+	return strings.Join(types, ",")
+}
+
+func TestEmit_WritesOneLinePerEvent(t *testing.T) {
+	var buf bytes.Buffer
+	events.Emit(&events.Summary{Command: "branches"})
+	if events.Enabled() {
+		t.Fatal("Expected the event stream to be disabled by default")
+	}
+
+	events.Enable(&buf)
+	defer events.Enable(nil)
+	events.Emit(&events.Candidate{Kind: events.KindBranch, Name: "feature", Reason: "merged", Risk: "safe"})
+	events.Emit(&events.Summary{Command: "branches", Candidates: 1})
+
+	decoded := decodeEvents(t, buf.Bytes())
+	if types := eventTypes(decoded); types != "candidate,summary" {
+		t.Fatalf("Expected candidate and summary events, got: %s", types)
+	}
+	if decoded[0]["name"] != "feature" || decoded[0]["kind"] != "branch" {
+		t.Errorf("Unexpected candidate event: %v", decoded[0])
+	}
+	if _, ok := decoded[0]["sha"]; ok {
+		t.Errorf("Expected empty fields to be omitted: %v", decoded[0])
+	}
+	if decoded[1]["candidates"] != float64(1) {
+		t.Errorf("Unexpected summary event: %v", decoded[1])
+	}
+}
+
+func TestNDJSONOutput_StreamsBranchCleanup(t *testing.T) {
+	binaryPath := filepath.Join(t.TempDir(), "git-gone-test")
+	buildCmd := exec.Command("go", "build", "-o", binaryPath, ".")
+	buildCmd.Dir = getProjectRoot(t)
+	if output, err := buildCmd.CombinedOutput(); err != nil {
+		t.Fatalf("Failed to build: %v\nOutput: %s", err, string(output))
+	}
+
+	repo := t.TempDir()
+	initScanRepo(t, repo)
+
+	// Confirmations are never read from stdin in ndjson mode
+	cmd := exec.Command(binaryPath, "--output", "ndjson", "--all")
+	cmd.Dir = repo
+	cmd.Env = append(os.Environ(), "LC_ALL=C")
+	cmd.Stdin = strings.NewReader("y\n")
+	output, err := cmd.CombinedOutput()
+	if err == nil {
+		t.Fatalf("Expected branches without --force to fail:\n%s", output)
+	}
+	decoded := decodeEvents(t, output)
+	if last := decoded[len(decoded)-1]; last["type"] != "error" || !strings.Contains(last["message"].(string), "--force") {
+		t.Errorf("Expected an error event asking for --force, got: %v", last)
+	}
+	if branches := runGitCmd(t, "-C", repo, "branch", "--list", "done"); branches == "" {
+		t.Fatal("Expected done to be kept without --force")
+	}
+
+	cmd = exec.Command(binaryPath, "--output", "ndjson", "--all", "--force")
+	cmd.Dir = repo
+	cmd.Env = append(os.Environ(), "LC_ALL=C")
+	output, err = cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("branches failed: %v\nOutput: %s", err, output)
+	}
+
+	decoded = decodeEvents(t, output)
+	expected := "fetch_started,fetch_finished,candidate,selection,deletion,summary"
+	if types := eventTypes(decoded); types != expected {
+		t.Fatalf("Expected events %s, got: %s", expected, types)
+	}
+	if decoded[2]["name"] != "done" || decoded[2]["reason"] != "merged" || decoded[2]["sha"] == nil {
+		t.Errorf("Unexpected candidate event: %v", decoded[2])
+	}
+	if decoded[4]["name"] != "done" || decoded[4]["error"] != nil {
+		t.Errorf("Unexpected deletion event: %v", decoded[4])
+	}
+	summary := decoded[5]
+	if summary["command"] != "branches" || summary["succeeded"] != float64(1) || summary["failed"] != float64(0) {
+		t.Errorf("Unexpected summary event: %v", summary)
+	}
+
+	// The interactive selector cannot be used with the event stream
+	cmd = exec.Command(binaryPath, "--output", "ndjson", "--unmerged")
+	cmd.Dir = repo
+	cmd.Env = append(os.Environ(), "LC_ALL=C")
+	output, err = cmd.CombinedOutput()
+	if err == nil {
+		t.Fatalf("Expected branches without --all to fail:\n%s", output)
+	}
+	decoded = decodeEvents(t, output)
+	last := decoded[len(decoded)-1]
+	if last["type"] != "error" || !strings.Contains(last["message"].(string), "--all") {
+		t.Errorf("Expected an error event asking for --all, got: %v", last)
+	}
+}
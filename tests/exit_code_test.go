@@ -0,0 +1,84 @@
+package tests
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// rejectDeletionHook makes git refuse to update the given branch
+const rejectDeletionHook = `#!/bin/sh
+[ "$1" = prepared ] || exit 0
+while read old new ref; do
+  [ "$ref" = refs/heads/%s ] && exit 1
+done
+exit 0
+`
+
+func TestExitCodes(t *testing.T) {
+	binaryPath := filepath.Join(t.TempDir(), "git-gone-test")
+	buildCmd := exec.Command("go", "build", "-o", binaryPath, ".")
+	buildCmd.Dir = getProjectRoot(t)
+	if output, err := buildCmd.CombinedOutput(); err != nil {
+		t.Fatalf("Failed to build: %v\nOutput: %s", err, string(output))
+	}
+
+	// run returns the exit code of git-gone run in dir with stdin
+	run := func(dir, stdin string, args ...string) int {
+		t.Helper()
+		cmd := exec.Command(binaryPath, args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "LC_ALL=C")
+		cmd.Stdin = strings.NewReader(stdin)
+		output, err := cmd.CombinedOutput()
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return exitErr.ExitCode()
+		}
+		if err != nil {
+			t.Fatalf("Failed to run git-gone %v: %v\nOutput: %s", args, err, output)
+		}
+		return 0
+	}
+	expect := func(name string, expected, got int) {
+		t.Helper()
+		if got != expected {
+			t.Errorf("%s: expected exit code %d, got %d", name, expected, got)
+		}
+	}
+	hook := func(repo, branch string) {
+		t.Helper()
+		path := filepath.Join(repo, ".git", "hooks", "reference-transaction")
+		if err := os.WriteFile(path, []byte(strings.ReplaceAll(rejectDeletionHook, "%s", branch)), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expect("not a repository", 7, run(t.TempDir(), "", "--all"))
+
+	repo := t.TempDir()
+	runGitCmd(t, "init", "-b", "main", repo)
+	runGitCmd(t, "-C", repo, "config", "user.email", "test@example.com")
+	runGitCmd(t, "-C", repo, "config", "user.name", "Test User")
+	runGitCmd(t, "-C", repo, "commit", "--allow-empty", "-m", "Initial commit")
+
+	expect("nothing to do", 2, run(repo, "", "--all"))
+	expect("report --check without candidates", 0, run(repo, "", "report", "--check"))
+
+	runGitCmd(t, "-C", repo, "branch", "one")
+	runGitCmd(t, "-C", repo, "branch", "two")
+	expect("report --check with candidates", 3, run(repo, "", "report", "--check"))
+	expect("declined confirmation", 6, run(repo, "n\n", "--all"))
+
+	hook(repo, "one")
+	expect("partial failure", 4, run(repo, "y\n", "--all"))
+	expect("total failure", 5, run(repo, "y\n", "--all"))
+
+	if err := os.Remove(filepath.Join(repo, ".git", "hooks", "reference-transaction")); err != nil {
+		t.Fatal(err)
+	}
+	expect("success", 0, run(repo, "y\n", "--all"))
+}
@@ -21,7 +21,7 @@ func TestIntegration_NotInGitRepository(t *testing.T) {
 	defer func() { _ = os.Chdir(origDir) }()
 	_ = os.Chdir(tempDir)
 
-	err = git.CheckGitRepository()
+	err = repo.CheckGitRepository()
 	if err == nil {
 		t.Error("Expected error when not in git repository")
 	}
@@ -33,7 +33,7 @@ func TestIntegration_NoBranchesToDelete(t *testing.T) {
 	defer h.Cleanup()
 
 	// In a fresh repo with only main, there should be no branches to delete
-	merged, err := git.GetMergedBranches("main")
+	merged, err := repo.GetMergedBranches("main")
 	if err != nil {
 		t.Fatalf("GetMergedBranches failed: %v", err)
 	}
@@ -57,13 +57,13 @@ func TestIntegration_PartialDeletionFailure(t *testing.T) {
 	h.MergeBranch("test-branch")
 
 	// Delete should succeed (branch is merged, safe delete works)
-	err := git.DeleteBranch("test-branch", false)
+	err := repo.DeleteBranch("test-branch", false)
 	if err != nil {
 		t.Errorf("DeleteBranch should succeed: %v", err)
 	}
 
 	// Deleting again should fail (branch doesn't exist)
-	err = git.DeleteBranch("test-branch", false)
+	err = repo.DeleteBranch("test-branch", false)
 	if err == nil {
 		t.Error("DeleteBranch should fail for non-existent branch")
 	}
@@ -86,7 +86,7 @@ func TestIntegration_NonEnglishLocale(t *testing.T) {
 	_ = os.Setenv("LC_ALL", "es_ES.UTF-8")
 
 	// Git operations should still work because internal/git uses LC_ALL=C
-	defaultBranch, err := git.GetDefaultBranch()
+	defaultBranch, err := repo.GetDefaultBranch("origin")
 	if err != nil {
 		t.Fatalf("GetDefaultBranch failed with non-English locale: %v", err)
 	}
@@ -165,7 +165,7 @@ func TestIntegration_DifferentDefaultBranches(t *testing.T) {
 			runGitCmd(t, "branch", "-M", tt.setupBranch)
 
 			// Test default branch detection
-			defaultBranch, err := git.GetDefaultBranch()
+			defaultBranch, err := repo.GetDefaultBranch("origin")
 			if err != nil {
 				t.Fatalf("GetDefaultBranch failed: %v", err)
 			}
@@ -188,13 +188,13 @@ func TestIntegration_DeleteBranchWithRemote_LocalOnly(t *testing.T) {
 	h.CheckoutMain()
 
 	// Delete with remote should succeed for local part
-	err := git.DeleteBranchWithRemote("local-only-branch")
+	err := repo.DeleteBranchWithRemote("local-only-branch", "origin")
 	if err != nil {
 		t.Errorf("DeleteBranchWithRemote should succeed for local branch: %v", err)
 	}
 
 	// Verify branch is deleted
-	branches, _ := git.GetAllLocalBranches()
+	branches, _ := repo.GetAllLocalBranches()
 	for _, b := range branches {
 		if b == "local-only-branch" {
 			t.Error("Branch should have been deleted")
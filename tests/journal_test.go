@@ -0,0 +1,178 @@
+package tests
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDeleteBranch_RecordsDeletionInJournal(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	h.CreateBranch("feature-journaled")
+	sha := strings.TrimSpace(runGitCmd(t, "rev-parse", "feature-journaled"))
+	h.MergeBranch("feature-journaled")
+
+	if err := repo.DeleteBranch("feature-journaled", false); err != nil {
+		t.Fatalf("DeleteBranch failed: %v", err)
+	}
+
+	records, err := repo.ReadJournal()
+	if err != nil {
+		t.Fatalf("ReadJournal failed: %v", err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 journal record, got %d", len(records))
+	}
+	if records[0].Branch != "feature-journaled" || records[0].SHA != sha {
+		t.Errorf("Unexpected journal record: %+v", records[0])
+	}
+	if records[0].DeletedAt.IsZero() {
+		t.Error("Expected deletion timestamp to be recorded")
+	}
+}
+
+func TestReadJournal_ReturnsMostRecentFirst(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	h.CreateBranch("first")
+	h.CheckoutMain()
+	h.CreateBranch("second")
+	h.CheckoutMain()
+
+	_ = repo.DeleteBranch("first", true)
+	_ = repo.DeleteBranch("second", true)
+
+	records, err := repo.ReadJournal()
+	if err != nil {
+		t.Fatalf("ReadJournal failed: %v", err)
+	}
+	if len(records) != 2 || records[0].Branch != "second" || records[1].Branch != "first" {
+		t.Errorf("Expected [second first], got: %+v", records)
+	}
+}
+
+func TestRestoreBranch_RecreatesBranchAndUpstream(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	h.CreateBranch("feature-restore")
+	h.CheckoutMain()
+	runGitCmd(t, "config", "branch.feature-restore.remote", "origin")
+	runGitCmd(t, "config", "branch.feature-restore.merge", "refs/heads/feature-restore")
+	sha := strings.TrimSpace(runGitCmd(t, "rev-parse", "feature-restore"))
+
+	if err := repo.DeleteBranch("feature-restore", true); err != nil {
+		t.Fatalf("DeleteBranch failed: %v", err)
+	}
+
+	records, err := repo.ReadJournal()
+	if err != nil || len(records) == 0 {
+		t.Fatalf("Expected a journal record, got %v (err: %v)", records, err)
+	}
+
+	if err := repo.RestoreBranch(records[0]); err != nil {
+		t.Fatalf("RestoreBranch failed: %v", err)
+	}
+
+	restored := strings.TrimSpace(runGitCmd(t, "rev-parse", "feature-restore"))
+	if restored != sha {
+		t.Errorf("Expected restored branch at %s, got %s", sha, restored)
+	}
+	remote := strings.TrimSpace(runGitCmd(t, "config", "branch.feature-restore.remote"))
+	if remote != "origin" {
+		t.Errorf("Expected upstream remote 'origin', got '%s'", remote)
+	}
+
+	// Restoring over an existing branch must fail
+	if err := repo.RestoreBranch(records[0]); err == nil {
+		t.Error("Expected RestoreBranch to fail when the branch already exists")
+	}
+}
+
+func TestReadJournal_OmitsRestoredDeletions(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	h.CreateBranch("feature-twice")
+	h.CheckoutMain()
+	h.CreateBranch("feature-kept")
+	h.CheckoutMain()
+
+	_ = repo.DeleteBranch("feature-kept", true)
+	if err := repo.DeleteBranch("feature-twice", true); err != nil {
+		t.Fatalf("DeleteBranch failed: %v", err)
+	}
+
+	records, err := repo.ReadJournal()
+	if err != nil || len(records) != 2 {
+		t.Fatalf("Expected 2 journal records, got %v (err: %v)", records, err)
+	}
+	if err := repo.RestoreBranch(records[0]); err != nil {
+		t.Fatalf("RestoreBranch failed: %v", err)
+	}
+
+	records, err = repo.ReadJournal()
+	if err != nil {
+		t.Fatalf("ReadJournal failed: %v", err)
+	}
+	if len(records) != 1 || records[0].Branch != "feature-kept" {
+		t.Fatalf("Expected only feature-kept after the restore, got: %+v", records)
+	}
+
+	// Deleting the restored branch again journals a new deletion to restore
+	if err := repo.DeleteBranch("feature-twice", true); err != nil {
+		t.Fatalf("DeleteBranch failed: %v", err)
+	}
+	records, err = repo.ReadJournal()
+	if err != nil || len(records) != 2 || records[0].Branch != "feature-twice" {
+		t.Fatalf("Expected feature-twice listed again, got %v (err: %v)", records, err)
+	}
+	if err := repo.RestoreBranch(records[0]); err != nil {
+		t.Errorf("Second RestoreBranch failed: %v", err)
+	}
+}
+
+func TestRestoreCommands_DryRunRestoresNothing(t *testing.T) {
+	binaryPath := filepath.Join(t.TempDir(), "git-gone-test")
+	buildCmd := exec.Command("go", "build", "-o", binaryPath, ".")
+	buildCmd.Dir = getProjectRoot(t)
+	if output, err := buildCmd.CombinedOutput(); err != nil {
+		t.Fatalf("Failed to build: %v\nOutput: %s", err, string(output))
+	}
+
+	dir := t.TempDir()
+	runGitCmd(t, "init", "-b", "main", dir)
+	runGitCmd(t, "-C", dir, "config", "user.email", "test@example.com")
+	runGitCmd(t, "-C", dir, "config", "user.name", "Test User")
+	runGitCmd(t, "-C", dir, "commit", "--allow-empty", "-m", "Initial commit")
+	runGitCmd(t, "-C", dir, "branch", "feature-done")
+
+	run := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command(binaryPath, args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "LC_ALL=C")
+		cmd.Stdin = strings.NewReader("y\n")
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git-gone %v failed: %v\nOutput: %s", args, err, output)
+		}
+		return string(output)
+	}
+	run("--all")
+
+	for _, args := range [][]string{{"restore", "--all", "--dry-run"}, {"trash", "restore", "--all", "--dry-run"}} {
+		output := run(args...)
+		if !strings.Contains(output, "Dry run: no changes were made") {
+			t.Errorf("Expected git-gone %v to print a dry run:\n%s", args, output)
+		}
+		if branches := runGitCmd(t, "-C", dir, "branch", "--list", "feature-done"); branches != "" {
+			t.Fatalf("Expected git-gone %v to leave feature-done deleted, got: %q", args, branches)
+		}
+	}
+}
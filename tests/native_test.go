@@ -0,0 +1,200 @@
+package tests
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"git-gone/internal/git"
+)
+
+// readBranchState collects everything the backends answer, for comparison.
+func readBranchState(t *testing.T) map[string]interface{} {
+	t.Helper()
+	state := make(map[string]interface{})
+
+	branches, err := repo.GetAllLocalBranches()
+	if err != nil {
+		t.Fatalf("GetAllLocalBranches failed: %v", err)
+	}
+	state["branches"] = branches
+	state["gone"], _ = repo.GetGoneBranches()
+	state["merged"], _ = repo.GetMergedBranches("main")
+	state["dates"], _ = repo.GetBranchCommitDates()
+	for _, branch := range branches {
+		divergence, err := repo.GetDivergence("refs/heads/"+branch, "main")
+		upstream, upstreamDivergence, ok := repo.GetUpstreamDivergence(branch)
+		state["branch:"+branch] = fmt.Sprint(repo.GetRemoteStatus(branch), divergence, err == nil, upstream, upstreamDivergence, ok)
+	}
+
+	snapshot, err := repo.LoadBranchSnapshot(git.BranchContext{DefaultBranch: "main", CurrentBranch: "main", Targets: []string{"main"}})
+	if err != nil {
+		t.Fatalf("LoadBranchSnapshot failed: %v", err)
+	}
+	state["snapshot"] = snapshot.Classify()
+	for _, branch := range snapshot.Branches() {
+		ref, _ := snapshot.Ref(branch)
+		divergence, ok := snapshot.Divergence(branch, "main")
+		state["snapshot:"+branch] = fmt.Sprint(ref, divergence, ok)
+	}
+	return state
+}
+
+func TestNativeBackend_MatchesExecBackend(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+	defer func() { _ = repo.SetBackend(git.BackendExec) }()
+
+	h.AddBareRemote("origin")
+	runGitCmd(t, "push", "-u", "origin", "main")
+
+	h.CreateBranch("feature-merged")
+	h.MergeBranch("feature-merged")
+	h.CreateBranch("feature-gone")
+	runGitCmd(t, "push", "-u", "origin", "feature-gone")
+	runGitCmd(t, "push", "origin", "--delete", "feature-gone")
+	h.CheckoutMain()
+	h.CreateBranch("feature/ahead")
+	runGitCmd(t, "push", "-u", "origin", "feature/ahead")
+	createFile(t, "ahead.txt", "ahead")
+	runGitCmd(t, "add", ".")
+	runGitCmd(t, "commit", "-m", "Ahead of upstream")
+	h.CheckoutMain()
+	createFile(t, "main.txt", "main moved on")
+	runGitCmd(t, "add", ".")
+	runGitCmd(t, "commit", "-m", "Main moved on")
+	h.CreateBranch("local-only")
+	runGitCmd(t, "branch", "--set-upstream-to=main")
+	h.CheckoutMain()
+
+	if err := repo.SetBackend(git.BackendExec); err != nil {
+		t.Fatalf("SetBackend(exec) failed: %v", err)
+	}
+	expected := readBranchState(t)
+
+	if err := repo.SetBackend(git.BackendNative); err != nil {
+		t.Fatalf("SetBackend(native) failed: %v", err)
+	}
+	got := readBranchState(t)
+
+	for key, want := range expected {
+		if !reflect.DeepEqual(got[key], want) {
+			t.Errorf("%s: native backend returned %v, exec backend %v", key, got[key], want)
+		}
+	}
+}
+
+func TestSetBackend_RejectsUnknownBackend(t *testing.T) {
+	if err := repo.SetBackend("libgit2"); err == nil {
+		t.Error("Expected an error for an unknown backend")
+	}
+}
+
+// generateRepo creates a repository with a linear main history and the given
+// number of branches, each one commit ahead of a main commit. Every other
+// branch tracks a remote branch, half of which are gone.
+func generateRepo(b testing.TB, branches int) string {
+	b.Helper()
+	dir := b.TempDir()
+	run := func(stdin string, args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "LC_ALL=C")
+		cmd.Stdin = strings.NewReader(stdin)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			b.Fatalf("git %s failed: %v\n%s", strings.Join(args, " "), err, output)
+		}
+	}
+	run("", "init", "-q", "-b", "main")
+
+	// Build the whole history in a single fast-import stream
+	const history = 200
+	var stream, config strings.Builder
+	commit := func(ref string, mark int, from string, when int) {
+		fmt.Fprintf(&stream, "commit %s\nmark :%d\ncommitter Bench <bench@example.com> %d +0000\ndata 8\ncommit%d\n", ref, mark, when, mark%10)
+		if from != "" {
+			fmt.Fprintf(&stream, "from %s\n", from)
+		}
+		fmt.Fprintf(&stream, "M 644 inline file%d.txt\ndata 2\n%d\n\n", mark, mark%10)
+	}
+	for i := 1; i <= history; i++ {
+		from := ""
+		if i > 1 {
+			from = fmt.Sprintf(":%d", i-1)
+		}
+		commit("refs/heads/main", i, from, 1600000000+i*60)
+	}
+	for i := 0; i < branches; i++ {
+		name := fmt.Sprintf("feature/branch-%05d", i)
+		mark := history + 1 + i
+		commit("refs/heads/"+name, mark, fmt.Sprintf(":%d", 1+i%history), 1600000000+mark*60)
+		if i%2 == 0 {
+			fmt.Fprintf(&config, "[branch %q]\n\tremote = origin\n\tmerge = refs/heads/%s\n", name, name)
+			if i%4 == 0 {
+				fmt.Fprintf(&stream, "reset refs/remotes/origin/%s\nfrom :%d\n\n", name, mark)
+			}
+		}
+	}
+	run(stream.String(), "fast-import", "--quiet")
+	run("", "remote", "add", "origin", "https://example.com/repo.git")
+	run("", "checkout", "-q", "main")
+
+	f, err := os.OpenFile(filepath.Join(dir, ".git", "config"), os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(config.String()); err != nil {
+		b.Fatal(err)
+	}
+	return dir
+}
+
+// benchBranches is the number of branches of the benchmark repository
+const benchBranches = 5000
+
+// BenchmarkBackends reads what "git-gone report" needs for every branch of a
+// repository with 5,000 branches, with each backend.
+func BenchmarkBackends(b *testing.B) {
+	dir := generateRepo(b, benchBranches)
+	origDir, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		b.Fatal(err)
+	}
+	defer func() { _ = os.Chdir(origDir) }()
+	defer func() { _ = repo.SetBackend(git.BackendExec) }()
+
+	for _, backend := range []string{git.BackendExec, git.BackendNative} {
+		b.Run(backend, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				// Open the backend per iteration, as every git-gone run does
+				if err := repo.SetBackend(backend); err != nil {
+					b.Fatal(err)
+				}
+				branches, err := repo.GetAllLocalBranches()
+				if err != nil || len(branches) != benchBranches+1 {
+					b.Fatalf("GetAllLocalBranches returned %d branches, %v", len(branches), err)
+				}
+				if _, err := repo.GetGoneBranches(); err != nil {
+					b.Fatal(err)
+				}
+				if _, err := repo.GetMergedBranches("main"); err != nil {
+					b.Fatal(err)
+				}
+				if _, err := repo.GetBranchCommitDates(); err != nil {
+					b.Fatal(err)
+				}
+				for _, branch := range branches {
+					repo.GetRemoteStatus(branch)
+					if _, err := repo.GetDivergence("refs/heads/"+branch, "main"); err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package tests
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"git-gone/internal/git"
+)
+
+func TestRemoteBranches_FindsMergedAndDeletesInOnePush(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	h.AddBareRemote("origin")
+	h.CreateBranch("feature-merged")
+	h.MergeBranch("feature-merged")
+	h.CreateBranch("feature-open")
+	h.CheckoutMain()
+	runGitCmd(t, "push", "origin", "main", "feature-merged", "feature-open")
+
+	branches, err := repo.ListRemoteBranches("origin")
+	if err != nil {
+		t.Fatalf("ListRemoteBranches failed: %v", err)
+	}
+	if len(branches) != 3 {
+		t.Fatalf("Expected 3 remote branches, got: %+v", branches)
+	}
+	if branches[0].AuthorEmail != "test@example.com" || branches[0].CommitDate.IsZero() {
+		t.Errorf("Expected author and commit date to be parsed, got: %+v", branches[0])
+	}
+
+	targetRef := repo.RemoteTargetRef("origin", "main")
+	if targetRef != "refs/remotes/origin/main" {
+		t.Errorf("Expected the remote copy of main as target, got %q", targetRef)
+	}
+	merged, err := repo.GetMergedRemoteBranches("origin", targetRef)
+	if err != nil {
+		t.Fatalf("GetMergedRemoteBranches failed: %v", err)
+	}
+	if strings.Join(merged, ",") != "feature-merged,main" {
+		t.Errorf("Expected feature-merged and main to be merged, got: %v", merged)
+	}
+
+	var toDelete []git.RemoteBranch
+	for _, b := range branches {
+		if b.Name != "main" {
+			toDelete = append(toDelete, b)
+		}
+	}
+	if err := repo.DeleteRemoteBranches("origin", toDelete); err != nil {
+		t.Fatalf("DeleteRemoteBranches failed: %v", err)
+	}
+
+	remaining := runGitCmd(t, "ls-remote", "--heads", "origin")
+	if strings.Contains(remaining, "feature-") || !strings.Contains(remaining, "refs/heads/main") {
+		t.Errorf("Expected only main on the remote, got:\n%s", remaining)
+	}
+
+	entries, err := repo.ListTrash()
+	if err != nil {
+		t.Fatalf("ListTrash failed: %v", err)
+	}
+	if len(entries) != 2 || !entries[0].IsRemoteBranch() {
+		t.Errorf("Expected both remote-tracking refs backed up, got: %+v", entries)
+	}
+}
+
+func TestDeleteRemoteBranches_KeepsBranchPushedToSinceListing(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	h.AddBareRemote("origin")
+	h.CreateBranch("feature-done")
+	h.CreateBranch("feature-busy")
+	h.CheckoutMain()
+	runGitCmd(t, "push", "origin", "main", "feature-done", "feature-busy")
+
+	branches, err := repo.ListRemoteBranches("origin")
+	if err != nil {
+		t.Fatalf("ListRemoteBranches failed: %v", err)
+	}
+	var toDelete []git.RemoteBranch
+	for _, b := range branches {
+		if b.Name != "main" {
+			toDelete = append(toDelete, b)
+		}
+	}
+
+	// Someone pushes to feature-busy after it was listed
+	runGitCmd(t, "checkout", "feature-busy")
+	createFile(t, "late.txt", "late work")
+	runGitCmd(t, "add", ".")
+	runGitCmd(t, "commit", "-m", "Late work")
+	runGitCmd(t, "push", "origin", "feature-busy")
+	h.CheckoutMain()
+
+	err = repo.DeleteRemoteBranches("origin", toDelete)
+	var kept *git.RemoteDeletionError
+	if !errors.As(err, &kept) {
+		t.Fatalf("Expected a RemoteDeletionError, got: %v", err)
+	}
+	if kept.Kept("feature-busy") == nil || kept.Kept("feature-done") != nil {
+		t.Errorf("Expected only feature-busy to be kept, got: %v", err)
+	}
+	if !strings.Contains(err.Error(), "stale info") {
+		t.Errorf("Expected the lease rejection as reason, got: %v", err)
+	}
+
+	remaining := runGitCmd(t, "ls-remote", "--heads", "origin")
+	if strings.Contains(remaining, "feature-done") || !strings.Contains(remaining, "refs/heads/feature-busy") {
+		t.Errorf("Expected feature-busy kept and feature-done deleted, got:\n%s", remaining)
+	}
+
+	entries, err := repo.ListTrash()
+	if err != nil {
+		t.Fatalf("ListTrash failed: %v", err)
+	}
+	if len(entries) != 1 || !strings.HasSuffix(entries[0].Original, "/feature-done") {
+		t.Errorf("Expected only the deleted branch backed up, got: %+v", entries)
+	}
+}
+
+func TestMatchAuthor_MatchesNameOrEmailGlobs(t *testing.T) {
+	b := git.RemoteBranch{AuthorName: "Jane Doe", AuthorEmail: "jane@example.com"}
+
+	tests := []struct {
+		patterns []string
+		matched  bool
+	}{
+		{nil, true},
+		{[]string{"*@example.com"}, true},
+		{[]string{"jane doe"}, true},
+		{[]string{"JANE@EXAMPLE.COM"}, true},
+		{[]string{"bob@example.com", "*@other.org"}, false},
+	}
+
+	for _, tt := range tests {
+		if matched := git.MatchAuthor(b, tt.patterns); matched != tt.matched {
+			t.Errorf("MatchAuthor(%v) = %v, want %v", tt.patterns, matched, tt.matched)
+		}
+	}
+}
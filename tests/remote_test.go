@@ -0,0 +1,167 @@
+package tests
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"git-gone/internal/git"
+)
+
+func TestResolveRemote_PrefersOriginThenFirstRemote(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	if remote, err := repo.ResolveRemote(""); err != nil || remote != "" {
+		t.Errorf("Expected no remote, got %q (err: %v)", remote, err)
+	}
+
+	h.AddBareRemote("upstream")
+	if remote, _ := repo.ResolveRemote(""); remote != "upstream" {
+		t.Errorf("Expected first remote 'upstream', got %q", remote)
+	}
+
+	h.AddBareRemote("origin")
+	if remote, _ := repo.ResolveRemote(""); remote != "origin" {
+		t.Errorf("Expected 'origin', got %q", remote)
+	}
+	if remote, _ := repo.ResolveRemote("upstream"); remote != "upstream" {
+		t.Errorf("Expected explicit 'upstream', got %q", remote)
+	}
+	if _, err := repo.ResolveRemote("missing"); err == nil {
+		t.Error("Expected an error for a missing remote")
+	}
+}
+
+func TestGetStaleTags_ComparesAgainstChosenRemotes(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	h.AddBareRemote("origin")
+	h.AddBareRemote("upstream")
+	runGitCmd(t, "tag", "v1.0.0")
+	runGitCmd(t, "tag", "v1.1.0")
+	runGitCmd(t, "tag", "local-only")
+	runGitCmd(t, "push", "origin", "v1.0.0")
+	runGitCmd(t, "push", "upstream", "v1.1.0")
+
+	stale, err := repo.GetStaleTags("origin")
+	if err != nil {
+		t.Fatalf("GetStaleTags failed: %v", err)
+	}
+	if strings.Join(stale, ",") != "local-only,v1.1.0" {
+		t.Errorf("Expected local-only and v1.1.0 stale against origin, got: %v", stale)
+	}
+
+	stale, err = repo.GetStaleTags("origin", "upstream")
+	if err != nil {
+		t.Fatalf("GetStaleTags failed: %v", err)
+	}
+	if strings.Join(stale, ",") != "local-only" {
+		t.Errorf("Expected only local-only stale against all remotes, got: %v", stale)
+	}
+}
+
+func TestDeleteBranchWithRemote_UsesBranchRemote(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	h.AddBareRemote("origin")
+	h.AddBareRemote("fork")
+	h.CreateBranch("feature-fork")
+	runGitCmd(t, "push", "-u", "fork", "feature-fork")
+	h.CheckoutMain()
+
+	if err := repo.DeleteBranchWithRemote("feature-fork", "origin"); err != nil {
+		t.Fatalf("DeleteBranchWithRemote failed: %v", err)
+	}
+
+	if out := runGitCmd(t, "ls-remote", "--heads", "fork", "feature-fork"); strings.TrimSpace(out) != "" {
+		t.Errorf("Expected feature-fork to be deleted from fork, got: %s", out)
+	}
+
+	records, err := repo.ReadJournal()
+	if err != nil || len(records) == 0 {
+		t.Fatalf("Expected a journal record, got %v (err: %v)", records, err)
+	}
+	if records[0].DeletedFromRemote != "fork" {
+		t.Errorf("Expected deletion from 'fork' to be journaled, got %q", records[0].DeletedFromRemote)
+	}
+}
+
+func TestDeleteBranchWithRemote_KeepsAdvancedRemoteBranch(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	h.AddBareRemote("origin")
+	h.CreateBranch("feature-lease")
+	runGitCmd(t, "push", "-u", "origin", "feature-lease")
+	fetched := strings.TrimSpace(runGitCmd(t, "rev-parse", "feature-lease"))
+
+	// Someone pushes to the branch after the last fetch
+	createFile(t, "late.txt", "late commit")
+	runGitCmd(t, "add", ".")
+	runGitCmd(t, "commit", "-m", "Late commit")
+	runGitCmd(t, "push", "origin", "HEAD:refs/heads/feature-lease")
+	runGitCmd(t, "update-ref", "refs/remotes/origin/feature-lease", fetched)
+	runGitCmd(t, "reset", "--hard", "HEAD~1")
+	h.CheckoutMain()
+
+	err := repo.DeleteBranchWithRemote("feature-lease", "origin")
+	var incomplete *git.IncompleteDeletionError
+	if !errors.As(err, &incomplete) || !strings.Contains(err.Error(), "kept on origin: [rejected] (stale info)") {
+		t.Fatalf("Expected the kept remote branch to be reported, got: %v", err)
+	}
+	if branches := runGitCmd(t, "branch", "--list", "feature-lease"); strings.TrimSpace(branches) != "" {
+		t.Errorf("Expected the local branch to be deleted, got: %s", branches)
+	}
+	if out := runGitCmd(t, "ls-remote", "--heads", "origin", "feature-lease"); strings.TrimSpace(out) == "" {
+		t.Error("Expected the advanced remote branch to be kept")
+	}
+	records, err := repo.ReadJournal()
+	if err != nil || len(records) == 0 {
+		t.Fatalf("Expected a journal record, got %v (err: %v)", records, err)
+	}
+	if records[0].DeletedFromRemote != "" {
+		t.Errorf("Expected no remote deletion to be journaled, got %q", records[0].DeletedFromRemote)
+	}
+}
+
+func TestGetDivergence_CountsAheadAndBehind(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	h.AddBareRemote("origin")
+	h.CreateBranch("feature-diverged")
+	runGitCmd(t, "push", "-u", "origin", "feature-diverged")
+	createFile(t, "local.txt", "not pushed")
+	runGitCmd(t, "add", ".")
+	runGitCmd(t, "commit", "-m", "Local commit")
+	h.CheckoutMain()
+	createFile(t, "main1.txt", "main 1")
+	runGitCmd(t, "add", ".")
+	runGitCmd(t, "commit", "-m", "Main 1")
+	createFile(t, "main2.txt", "main 2")
+	runGitCmd(t, "add", ".")
+	runGitCmd(t, "commit", "-m", "Main 2")
+
+	divergence, err := repo.GetDivergence("refs/heads/feature-diverged", "main")
+	if err != nil {
+		t.Fatalf("GetDivergence failed: %v", err)
+	}
+	if divergence.Ahead != 2 || divergence.Behind != 2 {
+		t.Errorf("Expected 2 ahead / 2 behind main, got %d/%d", divergence.Ahead, divergence.Behind)
+	}
+
+	upstream, divergence, ok := repo.GetUpstreamDivergence("feature-diverged")
+	if !ok {
+		t.Fatal("Expected feature-diverged to have an upstream")
+	}
+	if upstream != "origin/feature-diverged" || divergence.Ahead != 1 || divergence.Behind != 0 {
+		t.Errorf("Expected origin/feature-diverged 1/0, got %s %d/%d", upstream, divergence.Ahead, divergence.Behind)
+	}
+
+	if _, _, ok := repo.GetUpstreamDivergence("main"); ok {
+		t.Error("Expected main to have no upstream")
+	}
+}
@@ -0,0 +1,103 @@
+package tests
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"git-gone/internal/git"
+)
+
+func TestParseVersion_AcceptsSemverTags(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want string
+		ok   bool
+	}{
+		{"v1.2.3", "1.2.3", true},
+		{"1.2.3-rc.1", "1.2.3-rc.1", true},
+		{"v2.0.0-beta+build.5", "2.0.0-beta", true},
+		{"v1.2", "", false},
+		{"v01.2.3", "", false},
+		{"release-1.2.3", "", false},
+		{"v1.2.3-", "", false},
+	}
+
+	for _, tt := range tests {
+		v, ok := git.ParseVersion(tt.tag)
+		if ok != tt.ok || (ok && v.String() != tt.want) {
+			t.Errorf("ParseVersion(%q) = (%s, %v), want (%s, %v)", tt.tag, v, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestCompareVersions_FollowsSemverPrecedence(t *testing.T) {
+	ordered := []string{"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta.2", "1.0.0-beta.11", "1.0.0-rc.1", "1.0.0", "1.0.1", "1.10.0"}
+	for i := 0; i+1 < len(ordered); i++ {
+		a, _ := git.ParseVersion(ordered[i])
+		b, _ := git.ParseVersion(ordered[i+1])
+		if git.CompareVersions(a, b) != -1 || git.CompareVersions(b, a) != 1 {
+			t.Errorf("Expected %s < %s", ordered[i], ordered[i+1])
+		}
+	}
+}
+
+func TestApplyRetention_KeepsPatchesDropsPrereleasesAndOldNightlies(t *testing.T) {
+	tags := []string{
+		"v1.0.0", "v1.0.1", "v1.0.2", "v1.0.3",
+		"v1.1.0-rc.1", "v1.1.0", "v1.2.0-rc.1",
+		"nightly-1", "nightly-2", "nightly-3",
+		"legacy", "v0.9.0",
+	}
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	dates := map[string]time.Time{
+		"nightly-1": base,
+		"nightly-2": base.Add(24 * time.Hour),
+		"nightly-3": base.Add(48 * time.Hour),
+	}
+	policy := git.RetentionPolicy{
+		KeepPatches:     2,
+		DropPrereleases: true,
+		KeepNightlies:   1,
+		Protected:       []string{"v0.*"},
+	}
+
+	var dropped []string
+	decisions := git.ApplyRetention(tags, dates, policy)
+	for _, d := range decisions {
+		if !d.Keep {
+			dropped = append(dropped, d.Tag)
+		}
+	}
+	if len(decisions) != len(tags) {
+		t.Errorf("Expected a decision for every tag, got: %+v", decisions)
+	}
+
+	sort.Strings(dropped)
+	want := []string{"nightly-1", "nightly-2", "v1.0.0", "v1.0.1", "v1.1.0-rc.1"}
+	if len(dropped) != len(want) {
+		t.Fatalf("Expected %v to be dropped, got: %v", want, dropped)
+	}
+	for i := range want {
+		if dropped[i] != want[i] {
+			t.Errorf("Expected %v to be dropped, got: %v", want, dropped)
+			break
+		}
+	}
+
+	if first := decisions[0]; first.Tag != "v0.9.0" || !first.Keep {
+		t.Errorf("Expected decisions in version order starting with the protected v0.9.0, got: %+v", first)
+	}
+}
+
+func TestApplyRetention_NoRulesKeepsEverything(t *testing.T) {
+	policy := git.RetentionPolicy{}
+	if !policy.IsEmpty() {
+		t.Fatal("Expected a policy without rules to be empty")
+	}
+	for _, d := range git.ApplyRetention([]string{"v1.0.0", "v1.0.1-rc.1", "nightly-1"}, nil, policy) {
+		if !d.Keep {
+			t.Errorf("Expected %s to be kept without rules", d.Tag)
+		}
+	}
+}
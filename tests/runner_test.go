@@ -0,0 +1,131 @@
+package tests
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"git-gone/internal/git"
+)
+
+func TestClassifyBranches_WithFakeRunner(t *testing.T) {
+	fake := git.NewFakeRunner()
+	repo := git.OpenRepository("", fake)
+
+	main := "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+	gone := "2222222222222222222222222222222222222222"
+	wip := "1111111111111111111111111111111111111111"
+	fake.Stub("feature-gone\t"+gone+"\torigin\torigin/feature-gone\t[gone]\t1700000000\n"+
+		"feature-merged\t"+main+"\t\t\t\t1700000000\n"+
+		"feature-wip\t"+wip+"\t\t\t\t1700000000\n"+
+		"main\t"+main+"\t\t\t\t1700000000\n",
+		"for-each-ref",
+		"--format=%(refname:lstrip=2)%09%(objectname)%09%(upstream:remotename)%09%(upstream:short)%09%(upstream:track)%09%(committerdate:unix)",
+		"refs/heads/")
+	fake.Stub(main+"\n", "rev-parse", "--verify", "--quiet", "main^{commit}")
+	fake.Stub("feature-merged\nmain\n", "for-each-ref", "--merged="+main, "--format=%(refname:lstrip=2)", "refs/heads/")
+	// The commits the unmerged branches add on top of main, then the main
+	// commits since their merge base
+	fake.StubInput(gone+" "+main+"\n"+wip+" "+main+"\n", "^"+main+"\n"+gone+"\n"+wip+"\n", "rev-list", "--parents", "--stdin")
+	fake.StubInput("", main+"\n^"+main+"\n", "rev-list", "--parents", "--stdin")
+	// Neither branch has a patch main already contains
+	fake.Stub("", "diff-tree", "-r", "-p", "--stdin")
+
+	classifications, err := repo.ClassifyBranches(
+		[]string{"main", "feature-gone", "feature-merged", "feature-wip"},
+		git.BranchContext{DefaultBranch: "main", CurrentBranch: "main", Targets: []string{"main"}},
+	)
+	if err != nil {
+		t.Fatalf("ClassifyBranches failed: %v", err)
+	}
+
+	got := make(map[string]string)
+	for _, c := range classifications {
+		if c.Name == "feature-wip" && c.Candidate.SHA != wip {
+			t.Errorf("Expected feature-wip to capture its SHA, got %q", c.Candidate.SHA)
+		}
+		if c.IsProtected() {
+			got[c.Name] = c.ProtectedBy
+		} else {
+			got[c.Name] = c.Candidate.Reason.String()
+		}
+	}
+	expected := map[string]string{
+		"main":           "default_branch",
+		"feature-gone":   "gone_remote",
+		"feature-merged": "merged",
+		"feature-wip":    "unmerged",
+	}
+	for name, want := range expected {
+		if got[name] != want {
+			t.Errorf("Expected %s to be classified as %s, got %s", name, want, got[name])
+		}
+	}
+}
+
+func TestFakeRunner_FailsUnscriptedCommands(t *testing.T) {
+	fake := git.NewFakeRunner()
+	repo := git.OpenRepository("", fake)
+
+	fake.StubError("fatal: not a git repository", "rev-parse", "--git-dir")
+	if err := repo.CheckGitRepository(); err == nil {
+		t.Error("Expected CheckGitRepository to fail")
+	}
+	if _, err := repo.GetAllLocalBranches(); err == nil {
+		t.Error("Expected unscripted command to fail")
+	}
+	if calls := fake.Calls(); len(calls) != 2 || calls[1][0] != "branch" {
+		t.Errorf("Unexpected calls: %v", calls)
+	}
+}
+
+func TestRecordingRunner_LogsCommands(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	var log bytes.Buffer
+	recorder := git.NewRecordingRunner(git.ExecRunner{}, &log, true)
+	repo := git.OpenRepository("", recorder)
+
+	branch, err := repo.GetCurrentBranch()
+	if err != nil || branch != "main" {
+		t.Fatalf("GetCurrentBranch returned %q, %v", branch, err)
+	}
+
+	commands := recorder.Commands()
+	if len(commands) != 1 || strings.Join(commands[0], " ") != "rev-parse --abbrev-ref HEAD" {
+		t.Errorf("Unexpected recorded commands: %v", commands)
+	}
+	output := log.String()
+	if !strings.Contains(output, "+ git rev-parse --abbrev-ref HEAD") || !strings.Contains(output, "| main") {
+		t.Errorf("Expected traced command and output, got:\n%s", output)
+	}
+}
+
+func TestRepository_RunsInItsOwnDirectory(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	other := t.TempDir()
+	runGitCmd(t, "init", "-b", "trunk", other)
+	runGitCmd(t, "-C", other, "config", "user.email", "test@example.com")
+	runGitCmd(t, "-C", other, "config", "user.name", "Test User")
+	runGitCmd(t, "-C", other, "commit", "--allow-empty", "-m", "Initial commit")
+	runGitCmd(t, "-C", other, "branch", "feature")
+
+	recorder := git.NewRecordingRunner(git.ExecRunner{}, nil, false)
+	otherRepo := git.OpenRepository(other, recorder)
+	branches, err := otherRepo.GetAllLocalBranches()
+	if err != nil || strings.Join(branches, ",") != "feature,trunk" {
+		t.Fatalf("Expected the branches of %s, got %v, %v", other, branches, err)
+	}
+	if commands := recorder.Commands(); len(commands) != 1 || commands[0][0] != "-C" || commands[0][1] != other {
+		t.Errorf("Expected git to run in %s, got: %v", other, commands)
+	}
+	if branches, _ := repo.GetAllLocalBranches(); strings.Join(branches, ",") != "main" {
+		t.Errorf("Expected the current repository to be unaffected, got %v", branches)
+	}
+	if stateDir, err := otherRepo.GetStateDir(); err != nil || !strings.HasPrefix(stateDir, other) {
+		t.Errorf("Expected the state directory inside %s, got %s, %v", other, stateDir, err)
+	}
+}
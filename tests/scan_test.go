@@ -0,0 +1,138 @@
+package tests
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"git-gone/internal/git"
+)
+
+// initScanRepo creates a repository at dir with a merged branch "done" and an
+// unmerged branch "wip".
+func initScanRepo(t *testing.T, dir string) {
+	t.Helper()
+	runGitCmd(t, "init", "-b", "main", dir)
+	runGitCmd(t, "-C", dir, "config", "user.email", "test@example.com")
+	runGitCmd(t, "-C", dir, "config", "user.name", "Test User")
+	runGitCmd(t, "-C", dir, "commit", "--allow-empty", "-m", "Initial commit")
+	runGitCmd(t, "-C", dir, "checkout", "-b", "done")
+	runGitCmd(t, "-C", dir, "commit", "--allow-empty", "-m", "Commit on done")
+	runGitCmd(t, "-C", dir, "checkout", "main")
+	runGitCmd(t, "-C", dir, "merge", "--no-ff", "done", "-m", "Merge done")
+	runGitCmd(t, "-C", dir, "checkout", "-b", "wip")
+	runGitCmd(t, "-C", dir, "commit", "--allow-empty", "-m", "Commit on wip")
+	runGitCmd(t, "-C", dir, "checkout", "main")
+}
+
+func TestFindRepositories_SkipsNestedRepositories(t *testing.T) {
+	root := t.TempDir()
+	initScanRepo(t, filepath.Join(root, "api"))
+	initScanRepo(t, filepath.Join(root, "team", "web"))
+	initScanRepo(t, filepath.Join(root, "api", "vendor", "lib"))
+	if err := os.MkdirAll(filepath.Join(root, "docs", "drafts"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	repos, err := git.FindRepositories(root)
+	if err != nil {
+		t.Fatalf("FindRepositories failed: %v", err)
+	}
+	expected := []string{filepath.Join(root, "api"), filepath.Join(root, "team", "web")}
+	if strings.Join(repos, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected %v, got: %v", expected, repos)
+	}
+}
+
+func TestScanCommand_SummarizesAndCleansRepositories(t *testing.T) {
+	binaryPath := filepath.Join(t.TempDir(), "git-gone-test")
+	buildCmd := exec.Command("go", "build", "-o", binaryPath, ".")
+	buildCmd.Dir = getProjectRoot(t)
+	if output, err := buildCmd.CombinedOutput(); err != nil {
+		t.Fatalf("Failed to build: %v\nOutput: %s", err, string(output))
+	}
+
+	root := t.TempDir()
+	initScanRepo(t, filepath.Join(root, "api"))
+	initScanRepo(t, filepath.Join(root, "team", "web"))
+
+	cmd := exec.Command(binaryPath, "scan", root, "-u", "--jobs", "2")
+	cmd.Env = append(os.Environ(), "LC_ALL=C")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("scan failed: %v\nOutput: %s", err, output)
+	}
+	for _, line := range []string{
+		"api       0 safe | 1 local-only | 1 unmerged | 0 stale tags",
+		"team/web  0 safe | 1 local-only | 1 unmerged | 0 stale tags",
+		"SUMMARY: 2 repositories | 0 safe | 2 local-only | 2 unmerged | 0 stale tags",
+	} {
+		if !strings.Contains(string(output), line) {
+			t.Errorf("Expected %q in output:\n%s", line, output)
+		}
+	}
+
+	// Without -u only the merged branches are offered, in a single selector
+	cmd = exec.Command(binaryPath, "scan", root, "--clean", "--grouped", "--all")
+	cmd.Env = append(os.Environ(), "LC_ALL=C")
+	cmd.Stdin = strings.NewReader("y\n")
+	output, err = cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("scan --clean failed: %v\nOutput: %s", err, output)
+	}
+	if !strings.Contains(string(output), "Successfully deleted 2 branches in 2 repositories") {
+		t.Errorf("Expected both merged branches to be deleted:\n%s", output)
+	}
+	for _, repo := range []string{"api", "team/web"} {
+		branches := runGitCmd(t, "-C", filepath.Join(root, repo), "branch", "--format=%(refname:short)")
+		if branches != "main\nwip\n" {
+			t.Errorf("Expected only main and wip to remain in %s, got: %q", repo, branches)
+		}
+	}
+}
+
+func TestScanCommand_AtomicCleanupPerRepository(t *testing.T) {
+	binaryPath := filepath.Join(t.TempDir(), "git-gone-test")
+	buildCmd := exec.Command("go", "build", "-o", binaryPath, ".")
+	buildCmd.Dir = getProjectRoot(t)
+	if output, err := buildCmd.CombinedOutput(); err != nil {
+		t.Fatalf("Failed to build: %v\nOutput: %s", err, string(output))
+	}
+
+	root := t.TempDir()
+	initScanRepo(t, filepath.Join(root, "api"))
+	initScanRepo(t, filepath.Join(root, "web"))
+
+	// The dry run plans one transaction per repository
+	cmd := exec.Command(binaryPath, "scan", root, "--clean", "--grouped", "--all", "--atomic", "--dry-run")
+	cmd.Dir = t.TempDir()
+	cmd.Env = append(os.Environ(), "LC_ALL=C")
+	cmd.Stdin = strings.NewReader("y\n")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("scan --dry-run failed: %v\nOutput: %s", err, output)
+	}
+	if count := strings.Count(string(output), "git update-ref --stdin"); count != 2 {
+		t.Errorf("Expected one transaction per repository, got %d:\n%s", count, output)
+	}
+
+	cmd = exec.Command(binaryPath, "scan", root, "--clean", "--grouped", "--all", "--atomic")
+	cmd.Dir = t.TempDir()
+	cmd.Env = append(os.Environ(), "LC_ALL=C")
+	cmd.Stdin = strings.NewReader("y\n")
+	output, err = cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("scan --clean --atomic failed: %v\nOutput: %s", err, output)
+	}
+	if !strings.Contains(string(output), "Successfully deleted 2 branches in 2 repositories") {
+		t.Errorf("Expected both merged branches to be deleted:\n%s", output)
+	}
+	for _, repo := range []string{"api", "web"} {
+		branches := runGitCmd(t, "-C", filepath.Join(root, repo), "branch", "--format=%(refname:short)")
+		if branches != "main\nwip\n" {
+			t.Errorf("Expected only main and wip to remain in %s, got: %q", repo, branches)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package tests
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"git-gone/internal/git"
+)
+
+func TestBranchSnapshot_MatchesPerBranchQueries(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	h.AddBareRemote("origin")
+	runGitCmd(t, "push", "-u", "origin", "main")
+
+	h.CreateBranch("feature-merged")
+	h.MergeBranch("feature-merged")
+	h.CreateBranch("feature-squashed")
+	createFile(t, "squashed-2.txt", "second commit")
+	runGitCmd(t, "add", ".")
+	runGitCmd(t, "commit", "-m", "Second commit on feature-squashed")
+	h.SquashMergeBranch("feature-squashed")
+	h.CreateBranch("feature-gone")
+	runGitCmd(t, "push", "-u", "origin", "feature-gone")
+	runGitCmd(t, "push", "origin", "--delete", "feature-gone")
+	h.CheckoutMain()
+	h.CreateBranch("feature-partial")
+	createFile(t, "partial-2.txt", "second commit")
+	runGitCmd(t, "add", ".")
+	runGitCmd(t, "commit", "-m", "Second commit on feature-partial")
+	runGitCmd(t, "push", "-u", "origin", "feature-partial")
+	createFile(t, "partial-3.txt", "unpushed commit")
+	runGitCmd(t, "add", ".")
+	runGitCmd(t, "commit", "-m", "Unpushed commit on feature-partial")
+	h.CheckoutMain()
+	h.CreateBranch("feature-rebased")
+	h.CreateBranch("release/1.0")
+	h.CheckoutMain()
+	createFile(t, "main.txt", "main moved on")
+	runGitCmd(t, "add", ".")
+	runGitCmd(t, "commit", "-m", "Main moved on")
+	runGitCmd(t, "cherry-pick", "feature-rebased")
+	runGitCmd(t, "cherry-pick", "feature-partial~2")
+	h.CreateBranch("feature-unmerged")
+	runGitCmd(t, "branch", "--set-upstream-to=main")
+	h.CheckoutMain()
+	// A branch forked long ago moves the merge base of every branch back
+	runGitCmd(t, "checkout", "-b", "feature-old", "main~4")
+	createFile(t, "old.txt", "forked long ago")
+	runGitCmd(t, "add", ".")
+	runGitCmd(t, "commit", "-m", "Commit on feature-old")
+	h.CheckoutMain()
+
+	ctx := git.BranchContext{
+		DefaultBranch: "main",
+		CurrentBranch: "main",
+		Targets:       []string{"main"},
+		Protected:     []string{"release/*"},
+	}
+	branches, err := repo.GetAllLocalBranches()
+	if err != nil {
+		t.Fatalf("GetAllLocalBranches failed: %v", err)
+	}
+	snapshot, err := repo.LoadBranchSnapshot(ctx)
+	if err != nil {
+		t.Fatalf("LoadBranchSnapshot failed: %v", err)
+	}
+	got := make(map[string]string)
+	for _, c := range snapshot.Classify() {
+		if c.IsProtected() {
+			got[c.Name] = c.ProtectedBy
+		} else {
+			got[c.Name] = c.Candidate.Reason.String()
+		}
+	}
+	expected := map[string]string{
+		"main":             "default_branch",
+		"release/1.0":      "pattern:release/*",
+		"feature-merged":   "merged",
+		"feature-squashed": "squash_merged",
+		"feature-gone":     "gone_remote",
+		"feature-partial":  "unmerged",
+		"feature-rebased":  "rebase_merged",
+		"feature-unmerged": "unmerged",
+		"feature-old":      "unmerged",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Snapshot classified branches as %v, expected %v", got, expected)
+	}
+
+	dates, _ := repo.GetBranchCommitDates()
+	for _, branch := range branches {
+		ref, ok := snapshot.Ref(branch)
+		if !ok {
+			t.Errorf("Branch %s missing from snapshot", branch)
+			continue
+		}
+		if status := repo.GetRemoteStatus(branch); ref.RemoteStatus() != status {
+			t.Errorf("%s: snapshot remote status %v, expected %v", branch, ref.RemoteStatus(), status)
+		}
+		if !ref.CommitDate.Equal(dates[branch]) {
+			t.Errorf("%s: snapshot commit date %v, expected %v", branch, ref.CommitDate, dates[branch])
+		}
+
+		divergence, err := repo.GetDivergence("refs/heads/"+branch, "main")
+		if got, ok := snapshot.Divergence(branch, "main"); ok != (err == nil) || got != divergence {
+			t.Errorf("%s: snapshot divergence %v, expected %v", branch, got, divergence)
+		}
+
+		upstream, upstreamDivergence, hasUpstream := repo.GetUpstreamDivergence(branch)
+		if ref.HasUpstream() != hasUpstream || (hasUpstream && (ref.Upstream != upstream || ref.UpstreamDivergence != upstreamDivergence)) {
+			t.Errorf("%s: snapshot upstream %s %v, expected %s %v", branch, ref.Upstream, ref.UpstreamDivergence, upstream, upstreamDivergence)
+		}
+
+		if cherry, ok := snapshot.Cherry(branch, "main"); ok {
+			if expected, err := repo.GetCherryStatus(branch, "main"); err != nil || cherry != expected {
+				t.Errorf("%s: snapshot cherry %+v, expected %+v", branch, cherry, expected)
+			}
+		}
+	}
+	if cherry, ok := snapshot.Cherry("feature-partial", "main"); !ok || !cherry.PartiallyAbsorbed() {
+		t.Errorf("Expected feature-partial to be partially absorbed, got %+v", cherry)
+	}
+}
+
+// countSnapshotProcesses analyses every branch of the repository in dir, which
+// has the given number of branches besides main, and returns the number of
+// git commands run.
+func countSnapshotProcesses(tb testing.TB, dir string, branches int) int {
+	tb.Helper()
+	origDir, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		tb.Fatal(err)
+	}
+	defer func() { _ = os.Chdir(origDir) }()
+
+	recorder := git.NewRecordingRunner(git.ExecRunner{}, nil, false)
+	repo := git.OpenRepository("", recorder)
+
+	snapshot, err := repo.LoadBranchSnapshot(git.BranchContext{DefaultBranch: "main", Targets: []string{"main"}})
+	if err != nil {
+		tb.Fatalf("LoadBranchSnapshot failed: %v", err)
+	}
+	if classifications := snapshot.Classify(); len(classifications) != branches+1 {
+		tb.Fatalf("Classified %d branches, expected %d", len(classifications), branches+1)
+	}
+	for _, branch := range snapshot.Branches() {
+		snapshot.Divergence(branch, "main")
+		snapshot.Cherry(branch, "main")
+	}
+	return len(recorder.Commands())
+}
+
+func TestBranchSnapshot_RunsFixedNumberOfCommands(t *testing.T) {
+	small := countSnapshotProcesses(t, generateRepo(t, 20), 20)
+	large := countSnapshotProcesses(t, generateRepo(t, 200), 200)
+	if small != large {
+		t.Errorf("Expected the same number of git commands, got %d for 20 branches and %d for 200", small, large)
+	}
+}
+
+func TestBranchesCommand_RunsFixedNumberOfCommands(t *testing.T) {
+	binaryPath := filepath.Join(t.TempDir(), "git-gone-test")
+	buildCmd := exec.Command("go", "build", "-o", binaryPath, ".")
+	buildCmd.Dir = getProjectRoot(t)
+	if output, err := buildCmd.CombinedOutput(); err != nil {
+		t.Fatalf("Failed to build: %v\nOutput: %s", err, string(output))
+	}
+
+	// count runs the cleanup on every branch of dir and returns the number of
+	// git commands it logged
+	count := func(dir string) int {
+		t.Helper()
+		cmd := exec.Command(binaryPath, "branches", "--all", "--dry-run", "--verbose")
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "LC_ALL=C")
+		cmd.Stdin = strings.NewReader("y\n")
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git-gone branches failed: %v\nOutput: %s", err, output)
+		}
+		if !strings.Contains(string(output), "Dry run") {
+			t.Fatalf("Expected branches to be offered for deletion, got:\n%s", output)
+		}
+		return strings.Count(string(output), "+ git ")
+	}
+
+	small := count(generateRepo(t, 20))
+	large := count(generateRepo(t, 200))
+	if small != large {
+		t.Errorf("Expected the same number of git commands, got %d for 20 branches and %d for 200", small, large)
+	}
+}
+
+// BenchmarkBranchSnapshot analyses repositories of growing size and reports
+// the number of git processes spawned, which must not grow with them.
+func BenchmarkBranchSnapshot(b *testing.B) {
+	processes := -1
+	for _, branches := range []int{500, 1000, 2000} {
+		b.Run(fmt.Sprintf("branches=%d", branches), func(b *testing.B) {
+			dir := generateRepo(b, branches)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				count := countSnapshotProcesses(b, dir, branches)
+				if processes >= 0 && count != processes {
+					b.Fatalf("%d branches spawned %d git processes, expected %d", branches, count, processes)
+				}
+				processes = count
+				b.ReportMetric(float64(count), "processes/op")
+			}
+		})
+	}
+}
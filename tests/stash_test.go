@@ -0,0 +1,116 @@
+package tests
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"git-gone/internal/git"
+)
+
+func TestListStashes_RecordsBranchAndMessage(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	h.CreateBranch("feature-stash")
+	createFile(t, "feature-stash.txt", "work in progress")
+	runGitCmd(t, "stash", "push", "-m", "half done")
+	h.CheckoutMain()
+	createFile(t, "README.md", "changed on main")
+	runGitCmd(t, "stash")
+
+	stashes, err := repo.ListStashes()
+	if err != nil {
+		t.Fatalf("ListStashes failed: %v", err)
+	}
+	if len(stashes) != 2 {
+		t.Fatalf("Expected 2 stashes, got: %+v", stashes)
+	}
+
+	latest, older := stashes[0], stashes[1]
+	if latest.Index != 0 || latest.Branch != "main" {
+		t.Errorf("Unexpected latest stash: %+v", latest)
+	}
+	if older.Ref() != "stash@{1}" || older.Branch != "feature-stash" || older.Message != "half done" {
+		t.Errorf("Unexpected older stash: %+v", older)
+	}
+	if older.CreatedAt.IsZero() {
+		t.Error("Expected the stash creation date to be parsed")
+	}
+}
+
+func TestDropStashes_DropsFromHighestIndex(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	for _, content := range []string{"one", "two", "three"} {
+		createFile(t, "README.md", content)
+		runGitCmd(t, "stash", "push", "-m", content)
+	}
+
+	stashes, err := repo.ListStashes()
+	if err != nil {
+		t.Fatalf("ListStashes failed: %v", err)
+	}
+	keep := stashes[1]
+
+	dropped, err := repo.DropStashes([]git.Stash{stashes[0], stashes[2]})
+	if err != nil {
+		t.Fatalf("DropStashes failed: %v", err)
+	}
+	if len(dropped) != 2 {
+		t.Errorf("Expected 2 dropped stashes, got: %+v", dropped)
+	}
+
+	remaining, _ := repo.ListStashes()
+	if len(remaining) != 1 || remaining[0].SHA != keep.SHA {
+		t.Errorf("Expected only %s to remain, got: %+v", keep.Message, remaining)
+	}
+
+	// The recorded SHA no longer matches stash@{0}'s original entry
+	if _, err := repo.DropStashes([]git.Stash{stashes[0]}); err == nil {
+		t.Error("Expected DropStashes to refuse a stash that moved")
+	}
+}
+
+func TestStashesClean_MarksStashesOfExistingBranchesUnmerged(t *testing.T) {
+	binaryPath := filepath.Join(t.TempDir(), "git-gone-test")
+	buildCmd := exec.Command("go", "build", "-o", binaryPath, ".")
+	buildCmd.Dir = getProjectRoot(t)
+	if output, err := buildCmd.CombinedOutput(); err != nil {
+		t.Fatalf("Failed to build: %v\nOutput: %s", err, string(output))
+	}
+
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	h.CreateBranch("feature-kept")
+	createFile(t, "kept.txt", "work in progress")
+	runGitCmd(t, "add", "kept.txt")
+	runGitCmd(t, "stash")
+	h.CheckoutMain()
+	h.CreateBranch("feature-gone")
+	createFile(t, "gone.txt", "abandoned work")
+	runGitCmd(t, "add", "gone.txt")
+	runGitCmd(t, "stash")
+	h.CheckoutMain()
+	runGitCmd(t, "branch", "-D", "feature-gone")
+
+	cmd := exec.Command(binaryPath, "stashes", "clean", "--unmerged", "--all", "--force", "--dry-run", "--output", "ndjson")
+	cmd.Env = append(os.Environ(), "LC_ALL=C")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("stashes clean failed: %v\nOutput: %s", err, output)
+	}
+
+	risks := make(map[string]string)
+	for _, event := range decodeEvents(t, output) {
+		if event["type"] == "candidate" {
+			risks[event["reason"].(string)] = event["risk"].(string)
+		}
+	}
+	if risks["branch_exists"] != "unmerged" || risks["branch_deleted"] != "safe" {
+		t.Errorf("Expected branch_exists unmerged and branch_deleted safe, got: %v", risks)
+	}
+}
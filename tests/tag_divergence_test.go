@@ -0,0 +1,100 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"git-gone/internal/git"
+)
+
+func TestGetDivergentTags_DetectsRetaggedTags(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	h.AddBareRemote("origin")
+	runGitCmd(t, "tag", "v1.0.0")
+	runGitCmd(t, "tag", "-a", "v1.1.0", "-m", "Release 1.1.0")
+	runGitCmd(t, "push", "origin", "v1.0.0", "v1.1.0")
+	remoteSHA := strings.TrimSpace(runGitCmd(t, "rev-parse", "v1.0.0"))
+
+	// Retag v1.0.0 locally on a new commit; v1.1.0 stays in sync
+	h.CreateBranch("hotfix")
+	runGitCmd(t, "tag", "-f", "v1.0.0")
+
+	divergent, err := repo.GetDivergentTags("origin")
+	if err != nil {
+		t.Fatalf("GetDivergentTags failed: %v", err)
+	}
+	if len(divergent) != 1 || divergent[0].Name != "v1.0.0" || divergent[0].RemoteSHA != remoteSHA {
+		t.Fatalf("Expected only v1.0.0 to diverge from %s, got: %+v", remoteSHA, divergent)
+	}
+
+	c := git.NewDivergentTagCandidate("v1.0.0")
+	if c.Reason != git.ReasonDivergentTag || c.RiskLevel != git.RiskDangerous {
+		t.Errorf("Expected a dangerous divergent tag candidate, got: %+v", c)
+	}
+
+	if err := repo.ResetTagToRemote("v1.0.0", "origin"); err != nil {
+		t.Fatalf("ResetTagToRemote failed: %v", err)
+	}
+	if sha := strings.TrimSpace(runGitCmd(t, "rev-parse", "v1.0.0")); sha != remoteSHA {
+		t.Errorf("Expected v1.0.0 reset to %s, got %s", remoteSHA, sha)
+	}
+	if divergent, _ := repo.GetDivergentTags("origin"); len(divergent) != 0 {
+		t.Errorf("Expected no divergent tags after reset, got: %+v", divergent)
+	}
+	if entries, _ := repo.ListTrash(); len(entries) != 1 || !entries[0].IsTag() {
+		t.Errorf("Expected the retagged v1.0.0 to be backed up, got: %+v", entries)
+	}
+}
+
+func TestDeleteRemoteTags_DeletesOnlyOnRemote(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	h.AddBareRemote("origin")
+	runGitCmd(t, "tag", "v1.0.0")
+	runGitCmd(t, "tag", "v2.0.0")
+	runGitCmd(t, "push", "origin", "--tags")
+
+	sha := strings.TrimSpace(runGitCmd(t, "rev-parse", "v1.0.0"))
+	if deleted, err := repo.DeleteRemoteTags("origin", map[string]string{"v1.0.0": sha}); err != nil || len(deleted) != 1 {
+		t.Fatalf("DeleteRemoteTags failed: %v (deleted %v)", err, deleted)
+	}
+
+	remoteTags, err := repo.GetRemoteTags("origin")
+	if err != nil {
+		t.Fatalf("GetRemoteTags failed: %v", err)
+	}
+	if strings.Join(remoteTags, ",") != "v2.0.0" {
+		t.Errorf("Expected only v2.0.0 on the remote, got: %v", remoteTags)
+	}
+	if local, _ := repo.GetLocalTags(); len(local) != 2 {
+		t.Errorf("Expected local tags to be untouched, got: %v", local)
+	}
+}
+
+func TestDeleteRemoteTags_KeepsTagMovedOnRemote(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	h.AddBareRemote("origin")
+	runGitCmd(t, "tag", "v1.0.0")
+	runGitCmd(t, "push", "origin", "v1.0.0")
+	reviewed := strings.TrimSpace(runGitCmd(t, "rev-parse", "v1.0.0"))
+
+	// Someone moves the tag on the remote after it was reviewed
+	h.CreateBranch("hotfix")
+	runGitCmd(t, "push", "--force", "origin", "HEAD:refs/tags/v1.0.0")
+
+	deleted, err := repo.DeleteRemoteTags("origin", map[string]string{"v1.0.0": reviewed})
+	if err == nil || len(deleted) != 0 {
+		t.Fatalf("Expected the moved tag to be kept, got deleted %v, err %v", deleted, err)
+	}
+	if !strings.Contains(err.Error(), "kept v1.0.0 (") {
+		t.Errorf("Expected the kept tag to be named, got: %v", err)
+	}
+	if remoteTags, _ := repo.GetRemoteTags("origin"); strings.Join(remoteTags, ",") != "v1.0.0" {
+		t.Errorf("Expected v1.0.0 to stay on the remote, got: %v", remoteTags)
+	}
+}
@@ -1,9 +1,8 @@
 package tests
 
 import (
+	"strings"
 	"testing"
-
-	"git-gone/internal/git"
 )
 
 func TestGetLocalTags_ReturnsAllTags(t *testing.T) {
@@ -15,7 +14,7 @@ func TestGetLocalTags_ReturnsAllTags(t *testing.T) {
 	runGitCmd(t, "tag", "v1.1.0")
 	runGitCmd(t, "tag", "-a", "v2.0.0", "-m", "Release 2.0.0")
 
-	tags, err := git.GetLocalTags()
+	tags, err := repo.GetLocalTags()
 	if err != nil {
 		t.Fatalf("GetLocalTags failed: %v", err)
 	}
@@ -40,7 +39,7 @@ func TestGetLocalTags_ReturnsEmptyForNoTags(t *testing.T) {
 	h := NewTestHelper(t)
 	defer h.Cleanup()
 
-	tags, err := git.GetLocalTags()
+	tags, err := repo.GetLocalTags()
 	if err != nil {
 		t.Fatalf("GetLocalTags failed: %v", err)
 	}
@@ -58,7 +57,7 @@ func TestDeleteTag_DeletesLocalTag(t *testing.T) {
 	runGitCmd(t, "tag", "v-to-delete")
 
 	// Verify tag exists
-	tagsBefore, _ := git.GetLocalTags()
+	tagsBefore, _ := repo.GetLocalTags()
 	found := false
 	for _, tag := range tagsBefore {
 		if tag == "v-to-delete" {
@@ -71,16 +70,44 @@ func TestDeleteTag_DeletesLocalTag(t *testing.T) {
 	}
 
 	// Delete the tag
-	err := git.DeleteTag("v-to-delete")
+	err := repo.DeleteTag("v-to-delete")
 	if err != nil {
 		t.Fatalf("DeleteTag failed: %v", err)
 	}
 
 	// Verify tag is gone
-	tagsAfter, _ := git.GetLocalTags()
+	tagsAfter, _ := repo.GetLocalTags()
 	for _, tag := range tagsAfter {
 		if tag == "v-to-delete" {
 			t.Error("Tag should not exist after deletion")
 		}
 	}
 }
+
+func TestListTags_PopulatesAnnotationAndTarget(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	runGitCmd(t, "tag", "v1.0.0")
+	runGitCmd(t, "tag", "-a", "v2.0.0", "-m", "Release 2.0.0")
+	head := strings.TrimSpace(runGitCmd(t, "rev-parse", "HEAD"))
+
+	tags, err := repo.ListTags()
+	if err != nil {
+		t.Fatalf("ListTags failed: %v", err)
+	}
+	if len(tags) != 2 {
+		t.Fatalf("Expected 2 tags, got: %+v", tags)
+	}
+
+	lightweight, annotated := tags[0], tags[1]
+	if lightweight.IsAnnotated || lightweight.Tagger != "" || lightweight.SHA != head || lightweight.Target != head {
+		t.Errorf("Unexpected lightweight tag: %+v", lightweight)
+	}
+	if !annotated.IsAnnotated || annotated.Tagger != "Test User <test@example.com>" {
+		t.Errorf("Expected annotated tag with tagger, got: %+v", annotated)
+	}
+	if annotated.Target != head || annotated.SHA == head || annotated.CreatedAt.IsZero() {
+		t.Errorf("Expected annotated tag object pointing at %s, got: %+v", head, annotated)
+	}
+}
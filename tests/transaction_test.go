@@ -0,0 +1,142 @@
+package tests
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"git-gone/internal/git"
+)
+
+func TestDeleteBranchesAtomic_DeletesEveryBranch(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	h.AddBareRemote("origin")
+	h.CreateBranch("feature-merged")
+	h.MergeBranch("feature-merged")
+	h.CreateBranch("feature-unmerged")
+	runGitCmd(t, "push", "-u", "origin", "feature-unmerged")
+	h.CheckoutMain()
+
+	_, err := repo.DeleteBranchesAtomic([]git.BranchDeletion{
+		{Name: "feature-merged"},
+		{Name: "feature-unmerged", Force: true, DeleteRemote: true},
+	}, "origin")
+	if err != nil {
+		t.Fatalf("DeleteBranchesAtomic failed: %v", err)
+	}
+
+	if branches := runGitCmd(t, "branch", "--list", "feature-*"); strings.TrimSpace(branches) != "" {
+		t.Errorf("Expected both branches to be deleted, got: %s", branches)
+	}
+	if remote := runGitCmd(t, "ls-remote", "--heads", "origin", "feature-unmerged"); strings.TrimSpace(remote) != "" {
+		t.Errorf("Expected feature-unmerged to be deleted from the remote, got: %s", remote)
+	}
+	if config := runGitCmd(t, "config", "--list"); strings.Contains(config, "feature-unmerged") {
+		t.Errorf("Expected the branch configuration to be removed, got: %s", config)
+	}
+
+	trash, err := repo.ListTrash()
+	if err != nil || len(trash) != 2 {
+		t.Fatalf("Expected 2 trash entries, got %v, %v", trash, err)
+	}
+	records, err := repo.ReadJournal()
+	if err != nil || len(records) != 2 {
+		t.Fatalf("Expected 2 journal records, got %v, %v", records, err)
+	}
+	for _, record := range records {
+		if record.Branch == "feature-unmerged" && record.DeletedFromRemote != "origin" {
+			t.Errorf("Expected feature-unmerged to be recorded as deleted from origin, got: %+v", record)
+		}
+		if record.TrashRef == "" {
+			t.Errorf("Expected a trash ref for %s", record.Branch)
+		}
+	}
+}
+
+func TestDeleteBranchesAtomic_ReportsBranchKeptOnRemote(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	h.AddBareRemote("origin")
+	h.CreateBranch("feature-busy")
+	runGitCmd(t, "push", "-u", "origin", "feature-busy")
+	fetched := strings.TrimSpace(runGitCmd(t, "rev-parse", "feature-busy"))
+
+	// Someone pushes to the branch after the last fetch
+	createFile(t, "late.txt", "late commit")
+	runGitCmd(t, "add", ".")
+	runGitCmd(t, "commit", "-m", "Late commit")
+	runGitCmd(t, "push", "origin", "HEAD:refs/heads/feature-busy")
+	runGitCmd(t, "update-ref", "refs/remotes/origin/feature-busy", fetched)
+	runGitCmd(t, "reset", "--hard", "HEAD~1")
+	h.CreateBranch("feature-local")
+	h.CheckoutMain()
+
+	records, err := repo.DeleteBranchesAtomic([]git.BranchDeletion{
+		{Name: "feature-busy", Force: true, DeleteRemote: true},
+		{Name: "feature-local", Force: true, DeleteRemote: true},
+	}, "origin")
+	var incomplete *git.IncompleteDeletionError
+	if !errors.As(err, &incomplete) {
+		t.Fatalf("Expected an IncompleteDeletionError, got: %v", err)
+	}
+	if incomplete.For("feature-busy") == nil || incomplete.For("feature-local") != nil {
+		t.Errorf("Expected only feature-busy to be reported, got: %v", err)
+	}
+	if len(records) != 2 || records[0].DeletedFromRemote != "" || records[1].DeletedFromRemote != "" {
+		t.Errorf("Expected neither branch to be recorded as deleted from the remote, got: %+v", records)
+	}
+	if branches := runGitCmd(t, "branch", "--list", "feature-*"); strings.TrimSpace(branches) != "" {
+		t.Errorf("Expected both local branches to be deleted, got: %s", branches)
+	}
+}
+
+func TestDeleteBranchesAtomic_RejectsWholeTransaction(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	h.CreateBranch("feature-merged")
+	h.MergeBranch("feature-merged")
+	h.CreateBranch("feature-unmerged")
+	h.CreateBranch("feature-moved")
+	h.CheckoutMain()
+	staleSHA := strings.TrimSpace(runGitCmd(t, "rev-parse", "feature-moved~1"))
+
+	_, err := repo.DeleteBranchesAtomic([]git.BranchDeletion{
+		{Name: "feature-merged"},
+		{Name: "feature-unmerged"},
+		{Name: "feature-moved", SHA: staleSHA, Force: true},
+		{Name: "feature-missing", Force: true},
+	}, "")
+
+	var rejected *git.TransactionError
+	if !errors.As(err, &rejected) {
+		t.Fatalf("Expected a TransactionError, got: %v", err)
+	}
+	reasons := make(map[string]string)
+	for _, ref := range rejected.Refs {
+		reasons[ref.Branch] = ref.Reason
+	}
+	if len(reasons) != 3 || reasons["feature-merged"] != "" {
+		t.Errorf("Expected the unmerged, moved and missing branches to be reported, got: %v", reasons)
+	}
+	if !strings.Contains(reasons["feature-unmerged"], "not fully merged") {
+		t.Errorf("Unexpected reason for feature-unmerged: %q", reasons["feature-unmerged"])
+	}
+	if !strings.Contains(reasons["feature-moved"], "moved") {
+		t.Errorf("Unexpected reason for feature-moved: %q", reasons["feature-moved"])
+	}
+
+	// Nothing was deleted or backed up
+	branches := runGitCmd(t, "branch", "--list", "feature-*")
+	for _, branch := range []string{"feature-merged", "feature-unmerged", "feature-moved"} {
+		if !strings.Contains(branches, branch) {
+			t.Errorf("Expected %s to be kept, got: %s", branch, branches)
+		}
+	}
+	if trash, _ := repo.ListTrash(); len(trash) != 0 {
+		t.Errorf("Expected an empty trash, got: %v", trash)
+	}
+}
@@ -0,0 +1,127 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"git-gone/internal/git"
+)
+
+func TestDeleteBranch_BacksUpBranchInTrash(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	h.CreateBranch("feature-trashed")
+	h.CheckoutMain()
+	sha := strings.TrimSpace(runGitCmd(t, "rev-parse", "feature-trashed"))
+
+	if err := repo.DeleteBranch("feature-trashed", true); err != nil {
+		t.Fatalf("DeleteBranch failed: %v", err)
+	}
+
+	entries, err := repo.ListTrash()
+	if err != nil {
+		t.Fatalf("ListTrash failed: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 trash entry, got: %+v", entries)
+	}
+	entry := entries[0]
+	if entry.Original != "refs/heads/feature-trashed" || entry.SHA != sha || entry.IsTag() {
+		t.Errorf("Unexpected trash entry: %+v", entry)
+	}
+
+	if err := repo.RestoreFromTrash(entry); err != nil {
+		t.Fatalf("RestoreFromTrash failed: %v", err)
+	}
+	restored := strings.TrimSpace(runGitCmd(t, "rev-parse", "feature-trashed"))
+	if restored != sha {
+		t.Errorf("Expected restored branch at %s, got %s", sha, restored)
+	}
+
+	entries, _ = repo.ListTrash()
+	if len(entries) != 0 {
+		t.Errorf("Expected restored entry to leave the trash, got: %+v", entries)
+	}
+}
+
+func TestDeleteBranch_FailedDeleteLeavesNoBackup(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	h.CreateBranch("feature-unmerged")
+	h.CheckoutMain()
+
+	// Safe delete refuses unmerged branches
+	if err := repo.DeleteBranch("feature-unmerged", false); err == nil {
+		t.Fatal("Expected safe delete of an unmerged branch to fail")
+	}
+
+	entries, _ := repo.ListTrash()
+	if len(entries) != 0 {
+		t.Errorf("Expected no trash entries after a failed delete, got: %+v", entries)
+	}
+}
+
+func TestDeleteTag_BacksUpTagInTrash(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	runGitCmd(t, "tag", "-a", "v1.0.0", "-m", "Release 1.0.0")
+
+	if err := repo.DeleteTag("v1.0.0"); err != nil {
+		t.Fatalf("DeleteTag failed: %v", err)
+	}
+
+	entries, err := repo.ListTrash()
+	if err != nil {
+		t.Fatalf("ListTrash failed: %v", err)
+	}
+	if len(entries) != 1 || !entries[0].IsTag() || entries[0].Name() != "v1.0.0" {
+		t.Fatalf("Expected a trash entry for tag v1.0.0, got: %+v", entries)
+	}
+}
+
+func TestDeleteTag_KeptWhenBackupFails(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	runGitCmd(t, "tag", "v1.0.0")
+	// A ref at the trash namespace itself leaves no room for backups below it
+	runGitCmd(t, "update-ref", strings.TrimSuffix(git.TrashNamespace, "/"), "HEAD")
+
+	if err := repo.DeleteTag("v1.0.0"); err == nil {
+		t.Fatal("Expected DeleteTag to fail when the tag cannot be backed up")
+	}
+	if tags := runGitCmd(t, "tag", "--list"); tags != "v1.0.0\n" {
+		t.Errorf("Expected tag v1.0.0 to be kept, got: %q", tags)
+	}
+}
+
+func TestEmptyTrash_RespectsOlderThan(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	runGitCmd(t, "tag", "v-trash")
+	if err := repo.DeleteTag("v-trash"); err != nil {
+		t.Fatalf("DeleteTag failed: %v", err)
+	}
+
+	// A fresh backup is not older than an hour
+	removed, err := repo.EmptyTrash(time.Hour)
+	if err != nil {
+		t.Fatalf("EmptyTrash failed: %v", err)
+	}
+	if len(removed) != 0 {
+		t.Errorf("Expected no backups removed, got: %+v", removed)
+	}
+
+	removed, err = repo.EmptyTrash(0)
+	if err != nil {
+		t.Fatalf("EmptyTrash failed: %v", err)
+	}
+	if len(removed) != 1 {
+		t.Errorf("Expected 1 backup removed, got: %+v", removed)
+	}
+}
@@ -0,0 +1,60 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"git-gone/internal/git"
+)
+
+func TestGetWorktreeBranches_ProtectsBranchesInOtherWorktrees(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	path := filepath.Join(t.TempDir(), "feature-wt")
+	runGitCmd(t, "worktree", "add", "-b", "feature-wt", path)
+
+	branches := repo.GetWorktreeBranches()
+	if branches["feature-wt"] == "" {
+		t.Fatalf("Expected feature-wt to be checked out in a worktree, got: %v", branches)
+	}
+	if _, ok := branches["main"]; ok {
+		t.Error("The current worktree's branch should not be listed")
+	}
+
+	b := git.Branch{Name: "feature-wt", WorktreePath: branches["feature-wt"]}
+	if !b.IsProtected() {
+		t.Error("Expected a branch checked out in another worktree to be protected")
+	}
+}
+
+func TestListWorktrees_DetectsMissingWorktree(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	path := filepath.Join(t.TempDir(), "gone-wt")
+	runGitCmd(t, "worktree", "add", "-b", "gone-wt", path)
+	if err := os.RemoveAll(path); err != nil {
+		t.Fatalf("Failed to remove worktree directory: %v", err)
+	}
+
+	worktrees, err := repo.ListWorktrees()
+	if err != nil {
+		t.Fatalf("ListWorktrees failed: %v", err)
+	}
+	if len(worktrees) != 2 || !worktrees[0].IsMain {
+		t.Fatalf("Expected the main worktree and one linked worktree, got: %+v", worktrees)
+	}
+	missing := worktrees[1]
+	if !missing.Missing || missing.Branch != "gone-wt" {
+		t.Errorf("Expected gone-wt to be reported missing, got: %+v", missing)
+	}
+
+	if err := repo.RemoveWorktree(missing.Path); err != nil {
+		t.Fatalf("RemoveWorktree failed: %v", err)
+	}
+	if branches := repo.GetWorktreeBranches(); branches["gone-wt"] != "" {
+		t.Error("Expected the missing worktree to be pruned")
+	}
+}